@@ -28,8 +28,10 @@ type Secret struct {
 }
 
 type NamespacedName struct {
-	// resource namespace
-	Namespace string `json:"namespace"`
+	// +optional
+	// resource namespace, required for cluster scoped resources.
+	// For NamespacedUser defaults to its namespace.
+	Namespace string `json:"namespace,omitempty"`
 
 	// resource name
 	Name string `json:"name"`
@@ -50,6 +52,11 @@ func (n Name) ToNamespacedName() types.NamespacedName {
 	}
 }
 
+// ConditionReady is the type of condition, that indicates whether the resource is ready:
+// for User - it is created with all privileges in every database from the spec,
+// for Database - operator can connect to it.
+const ConditionReady = "Ready"
+
 type StatusSummary struct {
 	Ready   bool   `json:"ready"`
 	Message string `json:"message"`
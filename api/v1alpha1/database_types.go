@@ -17,7 +17,12 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"errors"
+	"fmt"
+	"slices"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 // Database types that are currently supported.
@@ -26,12 +31,24 @@ type DatabaseType string
 const (
 	PostgreSQL DatabaseType = "PostgreSQL"
 	MySQL      DatabaseType = "MySQL"
+	MariaDB    DatabaseType = "MariaDB"
+	MSSQL      DatabaseType = "MSSQL"
+	ClickHouse DatabaseType = "ClickHouse"
+	MongoDB    DatabaseType = "MongoDB"
+	Redis      DatabaseType = "Redis"
 )
 
-// +kubebuilder:validation:XValidation:rule="(self.databaseType == \"PostgreSQL\" && has(self.postgreSQL) && !has(self.mySQL)) || (self.databaseType == \"MySQL\" && has(self.mySQL) && !has(self.postgreSQL))",message="When .spec.databaseType is PostgreSQL use .spec.postgreSQL, When .spec.databaseType is MySQL use .spec.mySQL"
+// +kubebuilder:validation:XValidation:rule="(self.databaseType == \"PostgreSQL\") == has(self.postgreSQL)",message="When .spec.databaseType is PostgreSQL use .spec.postgreSQL"
+// +kubebuilder:validation:XValidation:rule="(self.databaseType == \"MySQL\") == has(self.mySQL)",message="When .spec.databaseType is MySQL use .spec.mySQL"
+// +kubebuilder:validation:XValidation:rule="(self.databaseType == \"MariaDB\") == has(self.mariaDB)",message="When .spec.databaseType is MariaDB use .spec.mariaDB"
+// +kubebuilder:validation:XValidation:rule="(self.databaseType == \"MSSQL\") == has(self.msSQL)",message="When .spec.databaseType is MSSQL use .spec.msSQL"
+// +kubebuilder:validation:XValidation:rule="(self.databaseType == \"ClickHouse\") == has(self.clickHouse)",message="When .spec.databaseType is ClickHouse use .spec.clickHouse"
+// +kubebuilder:validation:XValidation:rule="(self.databaseType == \"MongoDB\") == has(self.mongoDB)",message="When .spec.databaseType is MongoDB use .spec.mongoDB"
+// +kubebuilder:validation:XValidation:rule="(self.databaseType == \"Redis\") == has(self.redis)",message="When .spec.databaseType is Redis use .spec.redis"
 // DatabaseSpec defines the desired state of Database.
 type DatabaseSpec struct {
-	// Type of database to connect (Currently it is PostgreSQL and MySQL), required
+	// Type of database to connect (Currently it is PostgreSQL, MySQL, MariaDB, MSSQL, ClickHouse, MongoDB and Redis), required
+	// +kubebuilder:validation:Enum=PostgreSQL;MySQL;MariaDB;MSSQL;ClickHouse;MongoDB;Redis
 	Type DatabaseType `json:"databaseType"`
 
 	// Config for connecting for PostgreSQL compatible databases, not required.
@@ -41,8 +58,64 @@ type DatabaseSpec struct {
 	// Config for connecting for MySQL compatible databases, not required.
 	// required if DatabaseType equals to "MySQL".
 	MySQL *MySQLConfig `json:"mySQL,omitempty"`
+
+	// Config for connecting for MariaDB databases, not required.
+	// required if DatabaseType equals to "MariaDB".
+	MariaDB *MariaDBConfig `json:"mariaDB,omitempty"`
+
+	// Config for connecting for Microsoft SQL Server databases, not required.
+	// required if DatabaseType equals to "MSSQL".
+	MSSQL *MSSQLConfig `json:"msSQL,omitempty"`
+
+	// Config for connecting for ClickHouse databases, not required.
+	// required if DatabaseType equals to "ClickHouse".
+	ClickHouse *ClickHouseConfig `json:"clickHouse,omitempty"`
+
+	// Config for connecting for MongoDB databases, not required.
+	// required if DatabaseType equals to "MongoDB".
+	MongoDB *MongoDBConfig `json:"mongoDB,omitempty"`
+
+	// Config for connecting for Redis databases, not required.
+	// required if DatabaseType equals to "Redis".
+	Redis *RedisConfig `json:"redis,omitempty"`
+
+	// Policy restricting which users can use the database, not required.
+	// If not set - any User and NamespacedUser can use the database with any Privileges.
+	AccessPolicy *AccessPolicy `json:"accessPolicy,omitempty"`
+}
+
+// AccessPolicy defines which users can use the database and which privileges they can get.
+type AccessPolicy struct {
+	// Selector for namespaces, NamespacedUsers from which can use the database, not required.
+	// If not set - NamespacedUsers from any namespace can use the database.
+	// Cluster scoped Users are not restricted by this selector.
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// List of Privileges CRs, that can be referenced by users of the database, not required.
+	// If not set - any Privileges can be referenced.
+	AllowedPrivileges []Name `json:"allowedPrivileges,omitempty"`
+
+	// List of database roles, that can be granted to users of the database, not required.
+	// If not set - any roles can be granted only if AllowedPrivileges is not set too,
+	// otherwise roles can't be granted.
+	AllowedRoles []string `json:"allowedRoles,omitempty"`
+
+	// List of privileged PostgreSQL role attributes, that can be set in options of users of the database, not required.
+	// If not set - any attributes can be set only if AllowedPrivileges is not set too,
+	// otherwise privileged attributes can't be set.
+	AllowedPostgreSQLAttributes []PostgreSQLRoleAttribute `json:"allowedPostgreSQLAttributes,omitempty"`
 }
 
+// PostgreSQLRoleAttribute is privileged attribute of PostgreSQL role, that is restricted by AccessPolicy.
+// +kubebuilder:validation:Enum=CREATEROLE;REPLICATION;BYPASSRLS
+type PostgreSQLRoleAttribute string
+
+const (
+	PostgreSQLCreateRole  PostgreSQLRoleAttribute = "CREATEROLE"
+	PostgreSQLReplication PostgreSQLRoleAttribute = "REPLICATION"
+	PostgreSQLBypassRLS   PostgreSQLRoleAttribute = "BYPASSRLS"
+)
+
 type PostgresSSLMode string
 
 const (
@@ -132,12 +205,143 @@ type MySQLConfig struct {
 	// refer to --password flag in https://dev.mysql.com/doc/refman/8.0/en/connection-options.html
 	PasswordSecret Secret `json:"passwordSecret,omitempty"`
 
-	// The hostname from which created users will connect
-	// By default "*" will be used (So users would be "<user>@*")
-	UsersHostname string `json:"usersHostname"`
+	// Deprecated: use UsersHostnames.
+	// The hostname from which created users will connect, not required.
+	// +optional
+	UsersHostname string `json:"usersHostname,omitempty"`
+
+	// List of host patterns from which created users will connect, not required.
+	// User is created for every host ("<user>@<host>"), for example "%" or "10.0.%".
+	// By default "%" will be used (So users would be "<user>@%" and could connect from any host).
+	// Can be overridden in the User's DatabaseRef.
+	// +optional
+	UsersHostnames []string `json:"usersHostnames,omitempty"`
+}
+
+// MySQLDefaultUsersHostname is the host pattern of MySQL users, that allows connections from any host.
+const MySQLDefaultUsersHostname = "%"
+
+// Hostnames returns host patterns of created users, deprecated UsersHostname is used if UsersHostnames is empty.
+func (c *MySQLConfig) Hostnames() []string {
+	switch {
+	case len(c.UsersHostnames) > 0:
+		return c.UsersHostnames
+	case c.UsersHostname != "":
+		return []string{c.UsersHostname}
+	}
+	return []string{MySQLDefaultUsersHostname}
+}
+
+// MySQLLegacyUsersHostname is the host pattern of MySQL users,
+// that were created without UsersHostname before UsersHostnames were introduced.
+const MySQLLegacyUsersHostname = "*"
+
+// LegacyHostname returns host pattern of the users created before UsersHostnames were introduced.
+func (c *MySQLConfig) LegacyHostname() string {
+	if c.UsersHostname != "" {
+		return c.UsersHostname
+	}
+	return MySQLLegacyUsersHostname
+}
+
+type MariaDBConfig struct {
+	// Full DNS name/ip for database to use, required.
+	// If K8S service is used to connect - provide host
+	// as <db-service-name>.<db-service-namespace>.svc.cluster.local
+	// refer to --host flag in https://mariadb.com/kb/en/mariadb-command-line-client/
+	Host string `json:"host"`
+
+	// k8s-service/database port to connect to execute queries, defaults to 3306.
+	// refer to --port flag in https://mariadb.com/kb/en/mariadb-command-line-client/
+	Port int `json:"port"`
+
+	// Database name that will be used to connect to database, not required.
+	DatabaseName string `json:"databaseName,omitempty"`
+
+	// The MariaDB user account to provide for the authentication process, required.
+	// It must have at least CREATE USER privilege and GRANT OPTION on privileges, that are granted to users.
+	// refer to https://mariadb.com/kb/en/grant/#the-grant-option-privilege
+	User string `json:"user"`
+
+	// Secret with password for User to connect to database
+	// refer to --password flag in https://mariadb.com/kb/en/mariadb-command-line-client/
+	PasswordSecret Secret `json:"passwordSecret,omitempty"`
+
+	// List of host patterns from which created users will connect, not required.
+	// User is created for every host ("<user>@<host>"), for example "%" or "10.0.%".
+	// By default "%" will be used (So users would be "<user>@%" and could connect from any host).
+	// Can be overridden in the User's DatabaseRef.
+	// +optional
+	UsersHostnames []string `json:"usersHostnames,omitempty"`
+}
+
+// Hostnames returns host patterns of created users.
+func (c *MariaDBConfig) Hostnames() []string {
+	if len(c.UsersHostnames) > 0 {
+		return c.UsersHostnames
+	}
+	return []string{MySQLDefaultUsersHostname}
+}
+
+type MSSQLConfig struct {
+	// Full DNS name/ip for database to use, required.
+	// If K8S service is used to connect - provide host
+	// as <db-service-name>.<db-service-namespace>.svc.cluster.local
+	// refer to -S flag in https://learn.microsoft.com/en-us/sql/tools/sqlcmd/sqlcmd-utility
+	Host string `json:"host"`
+
+	// k8s-service/database port to connect to execute queries, defaults to 1433.
+	Port int `json:"port"`
+
+	// Database name that will be used to connect to database, not required.
+	// If not set - default database of the User login is used.
+	DatabaseName string `json:"databaseName,omitempty"`
+
+	// The SQL Server login to provide for the authentication process, required.
+	// It must have at least ALTER ANY LOGIN permission and ALTER ANY USER, ALTER ANY ROLE permissions
+	// in the databases, where users are created, or be a member of sysadmin server role.
+	// refer to https://learn.microsoft.com/en-us/sql/t-sql/statements/create-login-transact-sql#permissions
+	User string `json:"user"`
+
+	// Secret with password for User to connect to database
+	PasswordSecret Secret `json:"passwordSecret,omitempty"`
+
+	// List of databases, where database user is created for the login of every User
+	// and where roles are created and granted, not required.
+	// If not set - DatabaseName is used.
+	// Database users are also created in the databases of privileges, that are applied to them.
+	// +optional
+	UsersDatabases []string `json:"usersDatabases,omitempty"`
+}
+
+// Databases returns databases, where database users and roles are created.
+func (c *MSSQLConfig) Databases() []string {
+	if len(c.UsersDatabases) > 0 || c.DatabaseName == "" {
+		return c.UsersDatabases
+	}
+	return []string{c.DatabaseName}
+}
+
+// DatabaseServerStatus defines the observed state of Database.
+type DatabaseServerStatus struct {
+	// Standard conditions of the Database, see ConditionReady.
+	// Ready condition indicates whether operator can connect to the database.
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Version of the database server, reported during the last successful check.
+	ServerVersion string `json:"serverVersion,omitempty"`
+
+	// Whether connection of the operator to the database is encrypted with TLS.
+	TLSEnabled bool `json:"tlsEnabled,omitempty"`
+
+	// Time of the last connectivity check.
+	LastCheckTime *metav1.Time `json:"lastCheckTime,omitempty"`
 }
 
 //+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
 //+kubebuilder:resource:scope=Cluster
 
 // Database is the Schema for the databases API.
@@ -145,7 +349,8 @@ type Database struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec DatabaseSpec `json:"spec,omitempty"`
+	Spec   DatabaseSpec         `json:"spec,omitempty"`
+	Status DatabaseServerStatus `json:"status,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -157,6 +362,189 @@ type DatabaseList struct {
 	Items           []Database `json:"items"`
 }
 
+var ErrAccessDenied = errors.New("access to the database is denied")
+
+// Allows checks, whether user from the namespace with provided labels can use the database
+// with Privileges CRs, roles and privileged options from the DatabaseRef. For cluster scoped User namespace is empty.
+func (p *AccessPolicy) Allows(namespace string, namespaceLabels map[string]string, dbRef DatabaseRef) error {
+	if p == nil {
+		return nil
+	}
+
+	if namespace != "" && p.NamespaceSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(p.NamespaceSelector)
+		if err != nil {
+			return err
+		}
+		if !selector.Matches(labels.Set(namespaceLabels)) {
+			return fmt.Errorf("%w: namespace %s doesn't match namespace selector", ErrAccessDenied, namespace)
+		}
+	}
+
+	if p.AllowedPrivileges != nil {
+		for _, privilege := range dbRef.Privileges {
+			if !containsName(p.AllowedPrivileges, privilege.Name) {
+				return fmt.Errorf("%w: privileges %s are not allowed", ErrAccessDenied, privilege.Name)
+			}
+		}
+	}
+
+	if p.AllowedPrivileges != nil || p.AllowedRoles != nil {
+		for _, role := range dbRef.Roles {
+			if !slices.Contains(p.AllowedRoles, role.Name) {
+				return fmt.Errorf("%w: role %s is not allowed", ErrAccessDenied, role.Name)
+			}
+		}
+	}
+
+	if p.AllowedPrivileges != nil || p.AllowedPostgreSQLAttributes != nil {
+		for _, attribute := range postgresPrivilegedAttributes(dbRef.Options) {
+			if !slices.Contains(p.AllowedPostgreSQLAttributes, attribute) {
+				return fmt.Errorf("%w: PostgreSQL role attribute %s is not allowed", ErrAccessDenied, attribute)
+			}
+		}
+	}
+	return nil
+}
+
+// postgresPrivilegedAttributes returns privileged PostgreSQL role attributes, that are set in the options.
+func postgresPrivilegedAttributes(options *UserOptions) []PostgreSQLRoleAttribute {
+	if options == nil || options.PostgreSQL == nil {
+		return nil
+	}
+
+	var attributes []PostgreSQLRoleAttribute
+	if options.PostgreSQL.CreateRole {
+		attributes = append(attributes, PostgreSQLCreateRole)
+	}
+	if options.PostgreSQL.Replication {
+		attributes = append(attributes, PostgreSQLReplication)
+	}
+	if options.PostgreSQL.BypassRLS {
+		attributes = append(attributes, PostgreSQLBypassRLS)
+	}
+	return attributes
+}
+
+func containsName(names []Name, name string) bool {
+	for _, n := range names {
+		if n.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
 func init() {
 	SchemeBuilder.Register(&Database{}, &DatabaseList{})
 }
+
+// ClickHouseProtocol is a protocol to connect to ClickHouse with.
+type ClickHouseProtocol string
+
+const (
+	ClickHouseNative ClickHouseProtocol = "Native"
+	ClickHouseHTTP   ClickHouseProtocol = "HTTP"
+)
+
+type ClickHouseConfig struct {
+	// Full DNS name/ip for database to use, required.
+	// If K8S service is used to connect - provide host
+	// as <db-service-name>.<db-service-namespace>.svc.cluster.local
+	// refer to --host flag in https://clickhouse.com/docs/en/interfaces/cli
+	Host string `json:"host"`
+
+	// k8s-service/database port to connect to execute queries, required.
+	// Usually it is 9000 (9440 with TLS) for Native protocol and 8123 (8443 with TLS) for HTTP.
+	Port int `json:"port"`
+
+	// Protocol to connect to ClickHouse with, defaults to Native.
+	// +kubebuilder:validation:Enum=Native;HTTP
+	// +kubebuilder:default=Native
+	// +optional
+	Protocol ClickHouseProtocol `json:"protocol,omitempty"`
+
+	// Connect to ClickHouse with TLS, defaults to false.
+	// +optional
+	Secure bool `json:"secure,omitempty"`
+
+	// Database name that will be used to connect to database, not required.
+	DatabaseName string `json:"databaseName,omitempty"`
+
+	// The ClickHouse user account to provide for the authentication process, required.
+	// It must have at least ACCESS MANAGEMENT privilege with GRANT OPTION (SQL-driven access control)
+	// and privileges, that are granted to users, with GRANT OPTION.
+	// refer to https://clickhouse.com/docs/en/operations/access-rights#enabling-access-control
+	User string `json:"user"`
+
+	// Secret with password for User to connect to database
+	PasswordSecret Secret `json:"passwordSecret,omitempty"`
+}
+
+// MongoDBDefaultUsersDatabase is the database, where MongoDB users and roles are created by default.
+const MongoDBDefaultUsersDatabase = "admin"
+
+type MongoDBConfig struct {
+	// Full DNS name/ip for database to use, required.
+	// If K8S service is used to connect - provide host
+	// as <db-service-name>.<db-service-namespace>.svc.cluster.local
+	// refer to --host flag in https://www.mongodb.com/docs/mongodb-shell/reference/options/
+	Host string `json:"host"`
+
+	// k8s-service/database port to connect to execute queries, defaults to 27017.
+	Port int `json:"port"`
+
+	// Connect to MongoDB with TLS, defaults to false.
+	// +optional
+	Secure bool `json:"secure,omitempty"`
+
+	// Authentication database of User, defaults to "admin".
+	// +optional
+	AuthDatabase string `json:"authDatabase,omitempty"`
+
+	// The MongoDB user to provide for the authentication process, required.
+	// It must have at least userAdmin role in the UsersDatabase (userAdminAnyDatabase for "admin")
+	// refer to https://www.mongodb.com/docs/manual/reference/built-in-roles/#mongodb-authrole-userAdmin
+	User string `json:"user"`
+
+	// Secret with password for User to connect to database
+	PasswordSecret Secret `json:"passwordSecret,omitempty"`
+
+	// Database, where users and roles are created, defaults to "admin".
+	// Users authenticate against this database.
+	// +optional
+	UsersDatabase string `json:"usersDatabase,omitempty"`
+}
+
+// UsersDB returns the database, where users and roles are created.
+func (c *MongoDBConfig) UsersDB() string {
+	if c.UsersDatabase != "" {
+		return c.UsersDatabase
+	}
+	return MongoDBDefaultUsersDatabase
+}
+
+type RedisConfig struct {
+	// Full DNS name/ip for database to use, required.
+	// If K8S service is used to connect - provide host
+	// as <db-service-name>.<db-service-namespace>.svc.cluster.local
+	// refer to -h flag in https://redis.io/docs/connect/cli/
+	Host string `json:"host"`
+
+	// k8s-service/database port to connect to execute queries, defaults to 6379.
+	Port int `json:"port"`
+
+	// Connect to Redis with TLS, defaults to false.
+	// +optional
+	Secure bool `json:"secure,omitempty"`
+
+	// The Redis ACL user to provide for the authentication process, not required.
+	// If not set - password is used to authenticate as "default" user.
+	// It must be allowed to run ACL command (+acl)
+	// refer to https://redis.io/docs/management/security/acl/
+	// +optional
+	User string `json:"user,omitempty"`
+
+	// Secret with password for User to connect to database
+	PasswordSecret Secret `json:"passwordSecret,omitempty"`
+}
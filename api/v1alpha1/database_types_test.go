@@ -0,0 +1,140 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1_test
+
+import (
+	"errors"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/alex123012/database-users-operator/api/v1alpha1"
+)
+
+func TestAccessPolicy_Allows(t *testing.T) {
+	policy := &v1alpha1.AccessPolicy{
+		NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "backend"}},
+		AllowedPrivileges: []v1alpha1.Name{{Name: "readonly"}},
+	}
+
+	tests := []struct {
+		name       string
+		policy     *v1alpha1.AccessPolicy
+		namespace  string
+		labels     map[string]string
+		privileges []v1alpha1.Name
+		roles      []v1alpha1.RoleGrant
+		options    *v1alpha1.UserOptions
+		wantErr    bool
+	}{
+		{
+			name:       "No policy",
+			privileges: []v1alpha1.Name{{Name: "superuser"}},
+		},
+		{
+			name:       "Cluster user with allowed privileges",
+			policy:     policy,
+			privileges: []v1alpha1.Name{{Name: "readonly"}},
+		},
+		{
+			name:       "Cluster user with not allowed privileges",
+			policy:     policy,
+			privileges: []v1alpha1.Name{{Name: "readonly"}, {Name: "superuser"}},
+			wantErr:    true,
+		},
+		{
+			name:       "Namespace matches selector",
+			policy:     policy,
+			namespace:  "backend",
+			labels:     map[string]string{"team": "backend"},
+			privileges: []v1alpha1.Name{{Name: "readonly"}},
+		},
+		{
+			name:       "Namespace doesn't match selector",
+			policy:     policy,
+			namespace:  "frontend",
+			labels:     map[string]string{"team": "frontend"},
+			privileges: []v1alpha1.Name{{Name: "readonly"}},
+			wantErr:    true,
+		},
+		{
+			name:       "Roles with allowed privileges only",
+			policy:     policy,
+			privileges: []v1alpha1.Name{{Name: "readonly"}},
+			roles:      []v1alpha1.RoleGrant{{Name: "postgres"}},
+			wantErr:    true,
+		},
+		{
+			name:       "Allowed roles",
+			policy:     &v1alpha1.AccessPolicy{AllowedPrivileges: policy.AllowedPrivileges, AllowedRoles: []string{"readers"}},
+			privileges: []v1alpha1.Name{{Name: "readonly"}},
+			roles:      []v1alpha1.RoleGrant{{Name: "readers"}},
+		},
+		{
+			name:       "Not allowed roles",
+			policy:     &v1alpha1.AccessPolicy{AllowedRoles: []string{"readers"}},
+			privileges: []v1alpha1.Name{{Name: "superuser"}},
+			roles:      []v1alpha1.RoleGrant{{Name: "readers"}, {Name: "rds_superuser"}},
+			wantErr:    true,
+		},
+		{
+			name:       "Privileged attributes with allowed privileges only",
+			policy:     policy,
+			privileges: []v1alpha1.Name{{Name: "readonly"}},
+			options:    &v1alpha1.UserOptions{PostgreSQL: &v1alpha1.PostgreSQLUserOptions{Replication: true}},
+			wantErr:    true,
+		},
+		{
+			name:       "Not privileged attributes with allowed privileges only",
+			policy:     policy,
+			privileges: []v1alpha1.Name{{Name: "readonly"}},
+			options:    &v1alpha1.UserOptions{PostgreSQL: &v1alpha1.PostgreSQLUserOptions{CreateDB: true}},
+		},
+		{
+			name:       "Allowed privileged attributes",
+			policy:     &v1alpha1.AccessPolicy{AllowedPostgreSQLAttributes: []v1alpha1.PostgreSQLRoleAttribute{v1alpha1.PostgreSQLBypassRLS}},
+			privileges: []v1alpha1.Name{{Name: "superuser"}},
+			options:    &v1alpha1.UserOptions{PostgreSQL: &v1alpha1.PostgreSQLUserOptions{BypassRLS: true}},
+		},
+		{
+			name:       "Not allowed privileged attributes",
+			policy:     &v1alpha1.AccessPolicy{AllowedPostgreSQLAttributes: []v1alpha1.PostgreSQLRoleAttribute{v1alpha1.PostgreSQLBypassRLS}},
+			privileges: []v1alpha1.Name{{Name: "superuser"}},
+			options:    &v1alpha1.UserOptions{PostgreSQL: &v1alpha1.PostgreSQLUserOptions{BypassRLS: true, CreateRole: true}},
+			wantErr:    true,
+		},
+		{
+			name:       "Any privileges allowed",
+			policy:     &v1alpha1.AccessPolicy{NamespaceSelector: policy.NamespaceSelector},
+			namespace:  "backend",
+			labels:     map[string]string{"team": "backend"},
+			privileges: []v1alpha1.Name{{Name: "superuser"}},
+			roles:      []v1alpha1.RoleGrant{{Name: "postgres"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Allows(tt.namespace, tt.labels, v1alpha1.DatabaseRef{Privileges: tt.privileges, Roles: tt.roles, Options: tt.options})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("AccessPolicy.Allows() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, v1alpha1.ErrAccessDenied) {
+				t.Errorf("AccessPolicy.Allows() error = %v, want ErrAccessDenied", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// +kubebuilder:validation:Required
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// NamespacedUser is the Schema for the namespacedusers API.
+// It has the same spec as User, but can be created by owners of the namespace.
+// User is created in the databases with "<namespace>_<name>" name,
+// referenced and created secrets are always located in the NamespacedUser namespace,
+// so namespace for them can be omitted.
+type NamespacedUser struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   UserSpec   `json:"spec,omitempty"`
+	Status UserStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// NamespacedUserList contains a list of NamespacedUser.
+type NamespacedUserList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NamespacedUser `json:"items"`
+}
+
+// GetSpec returns pointer to the NamespacedUser spec.
+func (u *NamespacedUser) GetSpec() *UserSpec {
+	return &u.Spec
+}
+
+// GetStatus returns pointer to the NamespacedUser status.
+func (u *NamespacedUser) GetStatus() *UserStatus {
+	return &u.Status
+}
+
+func init() {
+	SchemeBuilder.Register(&NamespacedUser{}, &NamespacedUserList{})
+}
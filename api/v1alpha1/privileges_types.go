@@ -17,21 +17,107 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 type PrivilegeType string
 
+// ObjectType is a type of the database object, privileges are granted on.
+// +kubebuilder:validation:Enum=Table;Sequence;Schema;Function;Database;Global
+type ObjectType string
+
+const (
+	ObjectTable    ObjectType = "Table"
+	ObjectSequence ObjectType = "Sequence"
+	ObjectSchema   ObjectType = "Schema"
+	ObjectFunction ObjectType = "Function"
+	ObjectDatabase ObjectType = "Database"
+	ObjectGlobal   ObjectType = "Global"
+)
+
+// AllObjects is used in PrivilegeSpec.Objects to grant privileges on all objects of the type.
+const AllObjects = "*"
+
 // PrivilegesSpec defines the desired state of Privileges.
+// Either Privilege (role name or privilege in free form) or ObjectType with Privileges must be set.
+// +kubebuilder:validation:XValidation:rule="has(self.objectType) ? has(self.privileges) && !has(self.privilege) && !has(self.on) : has(self.privilege)",message="either privilege or objectType with privileges must be set"
+// +kubebuilder:validation:XValidation:rule="!has(self.objectType) || self.objectType == 'Global' || has(self.database)",message="database is required for objectType"
+// +kubebuilder:validation:XValidation:rule="!has(self.defaultPrivileges) || (has(self.objectType) && self.objectType in ['Table', 'Sequence', 'Function'])",message="defaultPrivileges can be used only with Table, Sequence or Function objectType"
 type PrivilegeSpec struct {
-	// Privilege is role name or PrivilegeType, required.
-	Privilege PrivilegeType `json:"privilege"`
+	// Privilege is role name or PrivilegeType, not required.
+	// For object privileges prefer ObjectType with Privileges.
+	// +optional
+	Privilege PrivilegeType `json:"privilege,omitempty"`
 
 	// In database object to give privileges to, not required.
+	// +optional
 	On string `json:"on,omitempty"`
 
 	// If Privilege is database specific - this field will be used to determine which db to use, not required.
+	// +optional
 	Database string `json:"database,omitempty"`
+
+	// Type of the objects to give Privileges on, not required.
+	// +optional
+	ObjectType ObjectType `json:"objectType,omitempty"`
+
+	// Schema of the objects, not required. For PostgreSQL defaults to "public", for MSSQL to "dbo".
+	// +optional
+	Schema string `json:"schema,omitempty"`
+
+	// Names of the objects, not required.
+	// Empty list or "*" means all objects of ObjectType in Schema (in Database for MySQL).
+	// +optional
+	Objects []string `json:"objects,omitempty"`
+
+	// List of privileges to give on the objects, required if ObjectType is set.
+	// +optional
+	Privileges []PrivilegeType `json:"privileges,omitempty"`
+
+	// Allow user to give the privileges to other users, not required.
+	// +optional
+	WithGrantOption bool `json:"withGrantOption,omitempty"`
+
+	// Give privileges on objects, that will be created in Schema in the future, instead of existing ones.
+	// Supported only by PostgreSQL, not required.
+	// +optional
+	DefaultPrivileges *DefaultPrivileges `json:"defaultPrivileges,omitempty"`
+}
+
+// DefaultPrivileges defines whose future objects privileges are given on.
+type DefaultPrivileges struct {
+	// Role, that creates the objects, not required. Defaults to the user operator connects to the database with.
+	// +optional
+	ForRole string `json:"forRole,omitempty"`
+}
+
+// AllObjects reports, whether privileges are given on all objects of ObjectType.
+func (p PrivilegeSpec) AllObjects() bool {
+	return len(p.Objects) < 1 || slices.Contains(p.Objects, AllObjects)
+}
+
+// PrivilegesList returns normalized comma separated list of Privileges,
+// privileges with characters other than letters and spaces are rejected, so the list is safe to render as is.
+func (p PrivilegeSpec) PrivilegesList() (string, error) {
+	if len(p.Privileges) < 1 {
+		return "", fmt.Errorf("%w: privileges are required for objectType", ErrInvalidPrivilege)
+	}
+
+	privileges := make([]string, 0, len(p.Privileges))
+	for _, privilege := range p.Privileges {
+		normalized := normalizePrivilege(privilege)
+		if !privilegeNameRegexp.MatchString(string(normalized)) {
+			return "", fmt.Errorf("%w: %q is not a privilege name", ErrInvalidPrivilege, privilege)
+		}
+		privileges = append(privileges, string(normalized))
+	}
+	return strings.Join(privileges, ", "), nil
 }
 
 //+kubebuilder:object:root=true
@@ -59,69 +145,310 @@ func init() {
 	SchemeBuilder.Register(&Privileges{}, &PrivilegesList{})
 }
 
-// func (p PrivilegeType) isEmpty() bool {
-// 	return p == ""
-// }
-
-// func (p PrivilegeType) isTablePrivilegeType() bool {
-// 	return privilegeTypeTableMap[p]
-// }
-
-// func (p PrivilegeType) isDatabasePrivilegeType() bool {
-// 	return privilegeTypeDatabaseMap[p]
-// }
-
-// func (p PrivilegeType) isAllTableSchemaPrivilegeType() bool {
-// 	return allTablesSchemaRegex.MatchString(string(p))
-// }
-
-// func (p PrivilegeType) isAllPrivilegeType() bool {
-// 	return privilegeTypeAllMap[p]
-// }
-
-// const (
-// 	SELECT          PrivilegeType = "SELECT"
-// 	INSERT          PrivilegeType = "INSERT"
-// 	UPDATE          PrivilegeType = "UPDATE"
-// 	DELETE          PrivilegeType = "DELETE"
-// 	TRUNCATE        PrivilegeType = "TRUNCATE"
-// 	REFERENCES      PrivilegeType = "REFERENCES"
-// 	TRIGGER         PrivilegeType = "TRIGGER"
-// 	CREATE          PrivilegeType = "CREATE"
-// 	CONNECT         PrivilegeType = "CONNECT"
-// 	TEMPORARY       PrivilegeType = "TEMPORARY"
-// 	TEMP            PrivilegeType = "TEMP"
-// 	EXECUTE         PrivilegeType = "EXECUTE"
-// 	USAGE           PrivilegeType = "USAGE"
-// 	SET             PrivilegeType = "SET"
-// 	ALTERSYSTEM     PrivilegeType = "ALTER SYSTEM"
-// 	ALLPRIVILEGES   PrivilegeType = "ALL PRIVILEGES"
-// 	ALL             PrivilegeType = "ALL"
-// 	ALLTABLESSCHEMA PrivilegeType = "ALL TABLES IN SCHEMA.*"
-// )
-
-// var (
-// allTablesSchemaRegex = regexp.MustCompile(string(ALLTABLESSCHEMA))
-
-// privilegeTypeDatabaseMap = map[PrivilegeType]bool{
-// 	CREATE:    true,
-// 	CONNECT:   true,
-// 	TEMPORARY: true,
-// 	TEMP:      true,
-// }
-
-// privilegeTypeTableMap = map[PrivilegeType]bool{
-// 	SELECT:     true,
-// 	INSERT:     true,
-// 	UPDATE:     true,
-// 	DELETE:     true,
-// 	TRUNCATE:   true,
-// 	REFERENCES: true,
-// 	TRIGGER:    true,
-// }
-
-//	privilegeTypeAllMap = map[PrivilegeType]bool{
-//		ALL:           true,
-//		ALLPRIVILEGES: true,
-//	}
-// )
+// Privileges, that are known by the supported databases.
+const (
+	SELECT               PrivilegeType = "SELECT"
+	INSERT               PrivilegeType = "INSERT"
+	UPDATE               PrivilegeType = "UPDATE"
+	DELETE               PrivilegeType = "DELETE"
+	TRUNCATE             PrivilegeType = "TRUNCATE"
+	REFERENCES           PrivilegeType = "REFERENCES"
+	TRIGGER              PrivilegeType = "TRIGGER"
+	CREATE               PrivilegeType = "CREATE"
+	CONNECT              PrivilegeType = "CONNECT"
+	TEMPORARY            PrivilegeType = "TEMPORARY"
+	TEMP                 PrivilegeType = "TEMP"
+	EXECUTE              PrivilegeType = "EXECUTE"
+	USAGE                PrivilegeType = "USAGE"
+	SET                  PrivilegeType = "SET"
+	ALTERSYSTEM          PrivilegeType = "ALTER SYSTEM"
+	ALLPRIVILEGES        PrivilegeType = "ALL PRIVILEGES"
+	ALL                  PrivilegeType = "ALL"
+	DROP                 PrivilegeType = "DROP"
+	ALTER                PrivilegeType = "ALTER"
+	INDEX                PrivilegeType = "INDEX"
+	CREATEVIEW           PrivilegeType = "CREATE VIEW"
+	SHOWVIEW             PrivilegeType = "SHOW VIEW"
+	CREATEROUTINE        PrivilegeType = "CREATE ROUTINE"
+	ALTERROUTINE         PrivilegeType = "ALTER ROUTINE"
+	EVENT                PrivilegeType = "EVENT"
+	LOCKTABLES           PrivilegeType = "LOCK TABLES"
+	CREATETEMPORARYTABLE PrivilegeType = "CREATE TEMPORARY TABLES"
+	GRANTOPTION          PrivilegeType = "GRANT OPTION"
+	CREATEUSER           PrivilegeType = "CREATE USER"
+	CREATEROLE           PrivilegeType = "CREATE ROLE"
+	DROPROLE             PrivilegeType = "DROP ROLE"
+	CREATETABLESPACE     PrivilegeType = "CREATE TABLESPACE"
+	FILE                 PrivilegeType = "FILE"
+	PROCESS              PrivilegeType = "PROCESS"
+	RELOAD               PrivilegeType = "RELOAD"
+	REPLICATIONCLIENT    PrivilegeType = "REPLICATION CLIENT"
+	REPLICATIONSLAVE     PrivilegeType = "REPLICATION SLAVE"
+	SHOWDATABASES        PrivilegeType = "SHOW DATABASES"
+	SHUTDOWN             PrivilegeType = "SHUTDOWN"
+	SUPER                PrivilegeType = "SUPER"
+)
+
+// MySQLGlobalDatabase is used in PrivilegeSpec.Database to grant MySQL global privileges ("ON *.*").
+const MySQLGlobalDatabase = "*"
+
+// MSSQLServerDatabase is used in PrivilegeSpec.Database to grant MSSQL server permissions instead of database ones.
+const MSSQLServerDatabase = "*"
+
+var ErrInvalidPrivilege = errors.New("invalid privilege")
+
+var (
+	// PostgresAllInSchemaRegexp matches PostgreSQL "ALL TABLES IN SCHEMA <schema>" forms of PrivilegeSpec.On.
+	PostgresAllInSchemaRegexp = regexp.MustCompile(`(?i)^ALL (TABLES|SEQUENCES|FUNCTIONS|PROCEDURES|ROUTINES) IN SCHEMA (.+)$`)
+
+	// RedisCommandRuleRegexp matches Redis ACL rules allowed in PrivilegeSpec.Privilege:
+	// commands and command categories, e.g. "+@read", "-flushall", "+config|get".
+	RedisCommandRuleRegexp = regexp.MustCompile(`^[+-]@?[a-zA-Z0-9_|-]+$`)
+	// RedisPatternRegexp matches Redis ACL rules allowed in PrivilegeSpec.On:
+	// key patterns ("~app:*", "%R~app:*") and pub/sub channel patterns ("&app:*").
+	RedisPatternRegexp = regexp.MustCompile(`^(~|%R~|%W~|%RW~|&)[^\s()]+$`)
+
+	// cataloguedDatabaseTypes are types of the databases, which privileges are checked against known privileges.
+	cataloguedDatabaseTypes = []DatabaseType{PostgreSQL, MySQL, MariaDB, MongoDB, Redis}
+
+	privilegeNameRegexp = regexp.MustCompile(`^[A-Z]+( [A-Z]+)*$`)
+
+	allPrivileges = privilegeSet(ALL, ALLPRIVILEGES)
+
+	postgresDatabasePrivileges = privilegeSet(CREATE, CONNECT, TEMPORARY, TEMP)
+	postgresTablePrivileges    = privilegeSet(SELECT, INSERT, UPDATE, DELETE, TRUNCATE, REFERENCES, TRIGGER)
+	postgresSequencePrivileges = privilegeSet(USAGE, SELECT, UPDATE)
+	postgresFunctionPrivileges = privilegeSet(EXECUTE)
+	postgresSchemaPrivileges   = map[string]map[PrivilegeType]bool{
+		"TABLES":     postgresTablePrivileges,
+		"SEQUENCES":  postgresSequencePrivileges,
+		"FUNCTIONS":  postgresFunctionPrivileges,
+		"PROCEDURES": postgresFunctionPrivileges,
+		"ROUTINES":   postgresFunctionPrivileges,
+	}
+	postgresObjectPrivileges = map[ObjectType]map[PrivilegeType]bool{
+		ObjectTable:    postgresTablePrivileges,
+		ObjectSequence: postgresSequencePrivileges,
+		ObjectFunction: postgresFunctionPrivileges,
+		ObjectSchema:   privilegeSet(CREATE, USAGE),
+		ObjectDatabase: postgresDatabasePrivileges,
+	}
+	postgresPrivileges = mergeSets(postgresDatabasePrivileges, postgresTablePrivileges, privilegeSet(EXECUTE, USAGE, SET, ALTERSYSTEM))
+
+	mysqlTablePrivileges = privilegeSet(SELECT, INSERT, UPDATE, DELETE, CREATE, DROP, ALTER, INDEX,
+		REFERENCES, CREATEVIEW, SHOWVIEW, TRIGGER, GRANTOPTION)
+	mysqlDatabasePrivileges = mergeSets(mysqlTablePrivileges, privilegeSet(CREATEROUTINE, ALTERROUTINE,
+		EXECUTE, EVENT, LOCKTABLES, CREATETEMPORARYTABLE))
+	mysqlGlobalPrivileges = mergeSets(mysqlDatabasePrivileges, privilegeSet(CREATEUSER, CREATEROLE, DROPROLE,
+		CREATETABLESPACE, FILE, PROCESS, RELOAD, REPLICATIONCLIENT, REPLICATIONSLAVE, SHOWDATABASES, SHUTDOWN, SUPER, USAGE))
+	mysqlObjectPrivileges = map[ObjectType]map[PrivilegeType]bool{
+		ObjectTable:    mysqlTablePrivileges,
+		ObjectFunction: privilegeSet(EXECUTE, ALTERROUTINE, GRANTOPTION),
+		ObjectDatabase: mysqlDatabasePrivileges,
+		ObjectGlobal:   mysqlGlobalPrivileges,
+	}
+)
+
+// Validate checks, that privilege can be applied in the database of provided type.
+// Privilege without Database and On is treated as role name, so only known privileges are rejected there.
+func (p PrivilegeSpec) Validate(dbType DatabaseType) error {
+	if p.ObjectType != "" {
+		return p.validateObject(dbType)
+	}
+	if p.Privilege == "" {
+		return fmt.Errorf("%w: privilege is required", ErrInvalidPrivilege)
+	}
+	if dbType == Redis {
+		return p.validateRedis()
+	}
+	if p.On != "" && p.Database == "" {
+		return fmt.Errorf("%w: database is required, when on is set", ErrInvalidPrivilege)
+	}
+
+	switch dbType {
+	case PostgreSQL:
+		return p.validatePostgres()
+	case MySQL, MariaDB:
+		return p.validateMysql()
+	case MongoDB:
+		if p.On != "" {
+			return fmt.Errorf("%w: on is not supported by %s, privilege is a role in the database", ErrInvalidPrivilege, dbType)
+		}
+	}
+	return nil
+}
+
+// ValidateAny checks, that privilege can be applied in the database of at least one type with known privileges.
+// It is used, when type of the database, where privilege is applied, isn't known yet.
+func (p PrivilegeSpec) ValidateAny() error {
+	var reasons []string
+	for _, dbType := range cataloguedDatabaseTypes {
+		err := p.Validate(dbType)
+		if err == nil {
+			return nil
+		}
+		reasons = append(reasons, fmt.Sprintf("%s: %v", dbType, err))
+	}
+	return fmt.Errorf("%w: privilege is not valid for any database type (%s)", ErrInvalidPrivilege, strings.Join(reasons, "; "))
+}
+
+func (p PrivilegeSpec) validateObject(dbType DatabaseType) error {
+	if p.Privilege != "" || p.On != "" {
+		return fmt.Errorf("%w: privilege and on can't be used with objectType", ErrInvalidPrivilege)
+	}
+	if p.ObjectType != ObjectGlobal && p.Database == "" {
+		return fmt.Errorf("%w: database is required for objectType %s", ErrInvalidPrivilege, p.ObjectType)
+	}
+	if _, err := p.PrivilegesList(); err != nil {
+		return err
+	}
+	if err := p.validateDefaultPrivileges(dbType); err != nil {
+		return err
+	}
+
+	var objectPrivileges map[ObjectType]map[PrivilegeType]bool
+	switch dbType {
+	case PostgreSQL:
+		objectPrivileges = postgresObjectPrivileges
+	case MySQL, MariaDB:
+		objectPrivileges = mysqlObjectPrivileges
+		if p.ObjectType == ObjectFunction && p.AllObjects() {
+			return fmt.Errorf("%w: function names are required", ErrInvalidPrivilege)
+		}
+	case ClickHouse:
+		if p.ObjectType != ObjectTable && p.ObjectType != ObjectDatabase && p.ObjectType != ObjectGlobal {
+			return fmt.Errorf("%w: objectType %s is not supported by %s", ErrInvalidPrivilege, p.ObjectType, dbType)
+		}
+		return nil
+	case MongoDB:
+		return fmt.Errorf("%w: objectType is not supported by %s, use privilege with role name", ErrInvalidPrivilege, dbType)
+	case Redis:
+		return fmt.Errorf("%w: objectType is not supported by %s, use privilege with ACL rules", ErrInvalidPrivilege, dbType)
+	default:
+		return nil
+	}
+
+	allowed, ok := objectPrivileges[p.ObjectType]
+	if !ok {
+		return fmt.Errorf("%w: objectType %s is not supported by %s", ErrInvalidPrivilege, p.ObjectType, dbType)
+	}
+	for _, privilege := range p.Privileges {
+		if err := checkPrivileges(privilege, allowed, strings.ToLower(string(p.ObjectType))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p PrivilegeSpec) validateDefaultPrivileges(dbType DatabaseType) error {
+	switch {
+	case p.DefaultPrivileges == nil:
+		return nil
+	case p.ObjectType != ObjectTable && p.ObjectType != ObjectSequence && p.ObjectType != ObjectFunction:
+		return fmt.Errorf("%w: default privileges can't be given on objectType %s", ErrInvalidPrivilege, p.ObjectType)
+	case !p.AllObjects():
+		return fmt.Errorf("%w: objects can't be set for default privileges", ErrInvalidPrivilege)
+	case dbType != "" && dbType != PostgreSQL:
+		return fmt.Errorf("%w: default privileges are not supported by %s", ErrInvalidPrivilege, dbType)
+	}
+	return nil
+}
+
+func (p PrivilegeSpec) validatePostgres() error {
+	switch {
+	case p.Database == "":
+		return checkRole(p.Privilege, postgresPrivileges)
+	case p.On == "":
+		return checkPrivileges(p.Privilege, postgresDatabasePrivileges, "database")
+	}
+
+	if match := PostgresAllInSchemaRegexp.FindStringSubmatch(p.On); match != nil {
+		objects := strings.ToUpper(match[1])
+		return checkPrivileges(p.Privilege, postgresSchemaPrivileges[objects], "all "+strings.ToLower(objects)+" in schema")
+	}
+	return checkPrivileges(p.Privilege, postgresTablePrivileges, "table")
+}
+
+func (p PrivilegeSpec) validateMysql() error {
+	switch {
+	case p.Database == "":
+		return checkRole(p.Privilege, mysqlGlobalPrivileges)
+	case p.Database == MySQLGlobalDatabase:
+		if p.On != "" && p.On != "*" {
+			return fmt.Errorf("%w: on must be empty for global privileges", ErrInvalidPrivilege)
+		}
+		return checkPrivileges(p.Privilege, mysqlGlobalPrivileges, "global")
+	case p.On == "" || p.On == "*":
+		return checkPrivileges(p.Privilege, mysqlDatabasePrivileges, "database")
+	}
+	return checkPrivileges(p.Privilege, mysqlTablePrivileges, "table")
+}
+
+// validateRedis checks, that privilege contains only command rules and on contains only key and channel patterns,
+// so password and user state rules (">pass", "nopass", "off", "reset") can't be set with privileges.
+func (p PrivilegeSpec) validateRedis() error {
+	if p.Database != "" {
+		return fmt.Errorf("%w: database is not supported by Redis, ACL rules are global", ErrInvalidPrivilege)
+	}
+	for _, rule := range strings.Fields(string(p.Privilege)) {
+		if !RedisCommandRuleRegexp.MatchString(rule) {
+			return fmt.Errorf("%w: %q is not a command rule, privilege must contain rules like +@read or -flushall", ErrInvalidPrivilege, rule)
+		}
+	}
+	for _, pattern := range strings.Fields(p.On) {
+		if !RedisPatternRegexp.MatchString(pattern) {
+			return fmt.Errorf("%w: %q is not a key or channel pattern, on must contain patterns like ~app:* or &app:*", ErrInvalidPrivilege, pattern)
+		}
+	}
+	return nil
+}
+
+// checkPrivileges checks, that every privilege from comma separated list is allowed on the level.
+func checkPrivileges(privilege PrivilegeType, allowed map[PrivilegeType]bool, level string) error {
+	for _, item := range splitPrivileges(privilege) {
+		if !allowed[item] && !allPrivileges[item] {
+			return fmt.Errorf("%w: %s can't be granted on %s level", ErrInvalidPrivilege, item, level)
+		}
+	}
+	return nil
+}
+
+// checkRole checks, that privilege without target is not a known privilege, which requires database.
+func checkRole(privilege PrivilegeType, known map[PrivilegeType]bool) error {
+	for _, item := range splitPrivileges(privilege) {
+		if known[item] || allPrivileges[item] {
+			return fmt.Errorf("%w: %s requires database", ErrInvalidPrivilege, item)
+		}
+	}
+	return nil
+}
+
+func splitPrivileges(privilege PrivilegeType) []PrivilegeType {
+	var privileges []PrivilegeType
+	for _, item := range strings.Split(string(privilege), ",") {
+		privileges = append(privileges, normalizePrivilege(PrivilegeType(item)))
+	}
+	return privileges
+}
+
+func normalizePrivilege(privilege PrivilegeType) PrivilegeType {
+	return PrivilegeType(strings.Join(strings.Fields(strings.ToUpper(string(privilege))), " "))
+}
+
+func privilegeSet(privileges ...PrivilegeType) map[PrivilegeType]bool {
+	set := make(map[PrivilegeType]bool, len(privileges))
+	for _, privilege := range privileges {
+		set[privilege] = true
+	}
+	return set
+}
+
+func mergeSets(sets ...map[PrivilegeType]bool) map[PrivilegeType]bool {
+	merged := make(map[PrivilegeType]bool)
+	for _, set := range sets {
+		for privilege := range set {
+			merged[privilege] = true
+		}
+	}
+	return merged
+}
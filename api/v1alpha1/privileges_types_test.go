@@ -0,0 +1,343 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/alex123012/database-users-operator/api/v1alpha1"
+)
+
+func TestPrivilegeSpec_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		dbType    v1alpha1.DatabaseType
+		privilege v1alpha1.PrivilegeSpec
+		wantErr   bool
+	}{
+		{
+			name:      "Empty privilege",
+			dbType:    v1alpha1.PostgreSQL,
+			privilege: v1alpha1.PrivilegeSpec{Database: "db"},
+			wantErr:   true,
+		},
+		{
+			name:      "On without database",
+			dbType:    v1alpha1.PostgreSQL,
+			privilege: v1alpha1.PrivilegeSpec{Privilege: v1alpha1.SELECT, On: "table"},
+			wantErr:   true,
+		},
+		{
+			name:      "Unknown database type",
+			dbType:    "",
+			privilege: v1alpha1.PrivilegeSpec{Privilege: "SELCT", On: "table", Database: "db"},
+		},
+		{
+			name:      "Postgres role",
+			dbType:    v1alpha1.PostgreSQL,
+			privilege: v1alpha1.PrivilegeSpec{Privilege: "readonly"},
+		},
+		{
+			name:      "Postgres privilege without database",
+			dbType:    v1alpha1.PostgreSQL,
+			privilege: v1alpha1.PrivilegeSpec{Privilege: v1alpha1.SELECT},
+			wantErr:   true,
+		},
+		{
+			name:      "Postgres database privilege",
+			dbType:    v1alpha1.PostgreSQL,
+			privilege: v1alpha1.PrivilegeSpec{Privilege: "connect, temp", Database: "db"},
+		},
+		{
+			name:      "Postgres table privilege on database",
+			dbType:    v1alpha1.PostgreSQL,
+			privilege: v1alpha1.PrivilegeSpec{Privilege: v1alpha1.SELECT, Database: "db"},
+			wantErr:   true,
+		},
+		{
+			name:      "Postgres table privileges",
+			dbType:    v1alpha1.PostgreSQL,
+			privilege: v1alpha1.PrivilegeSpec{Privilege: "SELECT, INSERT", On: "table", Database: "db"},
+		},
+		{
+			name:      "Postgres typo in table privilege",
+			dbType:    v1alpha1.PostgreSQL,
+			privilege: v1alpha1.PrivilegeSpec{Privilege: "SELCT", On: "table", Database: "db"},
+			wantErr:   true,
+		},
+		{
+			name:      "Postgres all tables in schema",
+			dbType:    v1alpha1.PostgreSQL,
+			privilege: v1alpha1.PrivilegeSpec{Privilege: v1alpha1.SELECT, On: "ALL TABLES IN SCHEMA public", Database: "db"},
+		},
+		{
+			name:      "Postgres all functions in schema",
+			dbType:    v1alpha1.PostgreSQL,
+			privilege: v1alpha1.PrivilegeSpec{Privilege: v1alpha1.EXECUTE, On: "all functions in schema public", Database: "db"},
+		},
+		{
+			name:      "Postgres select on all functions in schema",
+			dbType:    v1alpha1.PostgreSQL,
+			privilege: v1alpha1.PrivilegeSpec{Privilege: v1alpha1.SELECT, On: "ALL FUNCTIONS IN SCHEMA public", Database: "db"},
+			wantErr:   true,
+		},
+		{
+			name:      "Postgres all privileges",
+			dbType:    v1alpha1.PostgreSQL,
+			privilege: v1alpha1.PrivilegeSpec{Privilege: v1alpha1.ALLPRIVILEGES, On: "table", Database: "db"},
+		},
+		{
+			name:      "Mysql global privilege",
+			dbType:    v1alpha1.MySQL,
+			privilege: v1alpha1.PrivilegeSpec{Privilege: v1alpha1.PROCESS, Database: v1alpha1.MySQLGlobalDatabase},
+		},
+		{
+			name:      "Mysql global privilege with table",
+			dbType:    v1alpha1.MySQL,
+			privilege: v1alpha1.PrivilegeSpec{Privilege: v1alpha1.PROCESS, On: "table", Database: v1alpha1.MySQLGlobalDatabase},
+			wantErr:   true,
+		},
+		{
+			name:      "Mysql global privilege on database",
+			dbType:    v1alpha1.MySQL,
+			privilege: v1alpha1.PrivilegeSpec{Privilege: v1alpha1.PROCESS, Database: "db"},
+			wantErr:   true,
+		},
+		{
+			name:      "Mysql database privilege",
+			dbType:    v1alpha1.MySQL,
+			privilege: v1alpha1.PrivilegeSpec{Privilege: "EXECUTE, LOCK TABLES", On: "*", Database: "db"},
+		},
+		{
+			name:      "Mysql database privilege on table",
+			dbType:    v1alpha1.MySQL,
+			privilege: v1alpha1.PrivilegeSpec{Privilege: v1alpha1.EXECUTE, On: "table", Database: "db"},
+			wantErr:   true,
+		},
+		{
+			name:      "Mysql table privilege",
+			dbType:    v1alpha1.MySQL,
+			privilege: v1alpha1.PrivilegeSpec{Privilege: "SELECT, SHOW VIEW", On: "table", Database: "db"},
+		},
+		{
+			name:      "MariaDB table privilege",
+			dbType:    v1alpha1.MariaDB,
+			privilege: v1alpha1.PrivilegeSpec{Privilege: "SELECT, SHOW VIEW", On: "table", Database: "db"},
+		},
+		{
+			name:      "MariaDB default privileges",
+			dbType:    v1alpha1.MariaDB,
+			privilege: v1alpha1.PrivilegeSpec{ObjectType: v1alpha1.ObjectTable, Database: "db", Privileges: []v1alpha1.PrivilegeType{v1alpha1.SELECT}, DefaultPrivileges: &v1alpha1.DefaultPrivileges{}},
+			wantErr:   true,
+		},
+		{
+			name:      "MSSQL schema privileges",
+			dbType:    v1alpha1.MSSQL,
+			privilege: v1alpha1.PrivilegeSpec{ObjectType: v1alpha1.ObjectSchema, Database: "db", Schema: "sales", Privileges: []v1alpha1.PrivilegeType{v1alpha1.SELECT, "VIEW DEFINITION"}},
+		},
+		{
+			name:      "ClickHouse table privileges",
+			dbType:    v1alpha1.ClickHouse,
+			privilege: v1alpha1.PrivilegeSpec{ObjectType: v1alpha1.ObjectTable, Database: "db", Objects: []string{"events"}, Privileges: []v1alpha1.PrivilegeType{v1alpha1.SELECT}},
+		},
+		{
+			name:      "ClickHouse schema privileges",
+			dbType:    v1alpha1.ClickHouse,
+			privilege: v1alpha1.PrivilegeSpec{ObjectType: v1alpha1.ObjectSchema, Database: "db", Privileges: []v1alpha1.PrivilegeType{v1alpha1.SELECT}},
+			wantErr:   true,
+		},
+		{
+			name:      "MongoDB role",
+			dbType:    v1alpha1.MongoDB,
+			privilege: v1alpha1.PrivilegeSpec{Privilege: "readWrite", Database: "app"},
+		},
+		{
+			name:      "MongoDB privilege on collection",
+			dbType:    v1alpha1.MongoDB,
+			privilege: v1alpha1.PrivilegeSpec{Privilege: "readWrite", Database: "app", On: "orders"},
+			wantErr:   true,
+		},
+		{
+			name:      "MongoDB structured privileges",
+			dbType:    v1alpha1.MongoDB,
+			privilege: v1alpha1.PrivilegeSpec{ObjectType: v1alpha1.ObjectDatabase, Database: "app", Privileges: []v1alpha1.PrivilegeType{v1alpha1.SELECT}},
+			wantErr:   true,
+		},
+		{
+			name:      "Redis command rules with key patterns",
+			dbType:    v1alpha1.Redis,
+			privilege: v1alpha1.PrivilegeSpec{Privilege: "+@read +config|get -flushall", On: "~app:* %R~cache:* &events:*"},
+		},
+		{
+			name:      "Redis password rule",
+			dbType:    v1alpha1.Redis,
+			privilege: v1alpha1.PrivilegeSpec{Privilege: "+@all >password"},
+			wantErr:   true,
+		},
+		{
+			name:      "Redis command rule in on",
+			dbType:    v1alpha1.Redis,
+			privilege: v1alpha1.PrivilegeSpec{Privilege: "+@read", On: "~app:* nopass"},
+			wantErr:   true,
+		},
+		{
+			name:      "Redis privilege with database",
+			dbType:    v1alpha1.Redis,
+			privilege: v1alpha1.PrivilegeSpec{Privilege: "+@read", Database: "0"},
+			wantErr:   true,
+		},
+		{
+			name:      "MSSQL default privileges",
+			dbType:    v1alpha1.MSSQL,
+			privilege: v1alpha1.PrivilegeSpec{ObjectType: v1alpha1.ObjectTable, Database: "db", Privileges: []v1alpha1.PrivilegeType{v1alpha1.SELECT}, DefaultPrivileges: &v1alpha1.DefaultPrivileges{}},
+			wantErr:   true,
+		},
+		{
+			name:      "Postgres structured table privileges",
+			dbType:    v1alpha1.PostgreSQL,
+			privilege: v1alpha1.PrivilegeSpec{ObjectType: v1alpha1.ObjectTable, Database: "db", Objects: []string{"users"}, Privileges: []v1alpha1.PrivilegeType{"select", v1alpha1.UPDATE}},
+		},
+		{
+			name:      "Postgres structured schema privileges",
+			dbType:    v1alpha1.PostgreSQL,
+			privilege: v1alpha1.PrivilegeSpec{ObjectType: v1alpha1.ObjectSchema, Database: "db", Privileges: []v1alpha1.PrivilegeType{v1alpha1.SELECT}},
+			wantErr:   true,
+		},
+		{
+			name:      "Postgres structured global privileges",
+			dbType:    v1alpha1.PostgreSQL,
+			privilege: v1alpha1.PrivilegeSpec{ObjectType: v1alpha1.ObjectGlobal, Privileges: []v1alpha1.PrivilegeType{v1alpha1.SUPER}},
+			wantErr:   true,
+		},
+		{
+			name:      "Structured privileges without database",
+			dbType:    "",
+			privilege: v1alpha1.PrivilegeSpec{ObjectType: v1alpha1.ObjectTable, Privileges: []v1alpha1.PrivilegeType{v1alpha1.SELECT}},
+			wantErr:   true,
+		},
+		{
+			name:      "Structured privileges without privileges list",
+			dbType:    "",
+			privilege: v1alpha1.PrivilegeSpec{ObjectType: v1alpha1.ObjectTable, Database: "db"},
+			wantErr:   true,
+		},
+		{
+			name:      "Structured privileges with unsafe privilege",
+			dbType:    "",
+			privilege: v1alpha1.PrivilegeSpec{ObjectType: v1alpha1.ObjectTable, Database: "db", Privileges: []v1alpha1.PrivilegeType{"SELECT; --"}},
+			wantErr:   true,
+		},
+		{
+			name:      "Structured privileges with free-form privilege",
+			dbType:    "",
+			privilege: v1alpha1.PrivilegeSpec{ObjectType: v1alpha1.ObjectTable, Database: "db", Privilege: v1alpha1.SELECT, Privileges: []v1alpha1.PrivilegeType{v1alpha1.SELECT}},
+			wantErr:   true,
+		},
+		{
+			name:      "Mysql structured global privileges",
+			dbType:    v1alpha1.MySQL,
+			privilege: v1alpha1.PrivilegeSpec{ObjectType: v1alpha1.ObjectGlobal, Privileges: []v1alpha1.PrivilegeType{v1alpha1.PROCESS, v1alpha1.SELECT}},
+		},
+		{
+			name:      "Mysql structured function without names",
+			dbType:    v1alpha1.MySQL,
+			privilege: v1alpha1.PrivilegeSpec{ObjectType: v1alpha1.ObjectFunction, Database: "db", Privileges: []v1alpha1.PrivilegeType{v1alpha1.EXECUTE}},
+			wantErr:   true,
+		},
+		{
+			name:      "Mysql structured sequence privileges",
+			dbType:    v1alpha1.MySQL,
+			privilege: v1alpha1.PrivilegeSpec{ObjectType: v1alpha1.ObjectSequence, Database: "db", Privileges: []v1alpha1.PrivilegeType{v1alpha1.USAGE}},
+			wantErr:   true,
+		},
+		{
+			name:      "Postgres default privileges",
+			dbType:    v1alpha1.PostgreSQL,
+			privilege: v1alpha1.PrivilegeSpec{ObjectType: v1alpha1.ObjectTable, Database: "db", Privileges: []v1alpha1.PrivilegeType{v1alpha1.SELECT}, DefaultPrivileges: &v1alpha1.DefaultPrivileges{ForRole: "migrator"}},
+		},
+		{
+			name:      "Postgres default privileges on objects",
+			dbType:    v1alpha1.PostgreSQL,
+			privilege: v1alpha1.PrivilegeSpec{ObjectType: v1alpha1.ObjectTable, Database: "db", Objects: []string{"users"}, Privileges: []v1alpha1.PrivilegeType{v1alpha1.SELECT}, DefaultPrivileges: &v1alpha1.DefaultPrivileges{}},
+			wantErr:   true,
+		},
+		{
+			name:      "Mysql default privileges",
+			dbType:    v1alpha1.MySQL,
+			privilege: v1alpha1.PrivilegeSpec{ObjectType: v1alpha1.ObjectTable, Database: "db", Privileges: []v1alpha1.PrivilegeType{v1alpha1.SELECT}, DefaultPrivileges: &v1alpha1.DefaultPrivileges{}},
+			wantErr:   true,
+		},
+		{
+			name:      "Mysql role",
+			dbType:    v1alpha1.MySQL,
+			privilege: v1alpha1.PrivilegeSpec{Privilege: "readonly"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.privilege.Validate(tt.dbType)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, v1alpha1.ErrInvalidPrivilege) {
+				t.Fatalf("Validate() error = %v, want %v", err, v1alpha1.ErrInvalidPrivilege)
+			}
+		})
+	}
+}
+
+func TestPrivilegeSpec_ValidateAny(t *testing.T) {
+	tests := []struct {
+		name      string
+		privilege v1alpha1.PrivilegeSpec
+		wantErr   bool
+	}{
+		{
+			name:      "Typo in table privilege",
+			privilege: v1alpha1.PrivilegeSpec{Privilege: "SELCT", On: "table", Database: "db"},
+			wantErr:   true,
+		},
+		{
+			name:      "Postgres table privileges",
+			privilege: v1alpha1.PrivilegeSpec{Privilege: "SELECT, INSERT", On: "table", Database: "db"},
+		},
+		{
+			name:      "Mysql global privilege",
+			privilege: v1alpha1.PrivilegeSpec{Privilege: v1alpha1.PROCESS, Database: v1alpha1.MySQLGlobalDatabase},
+		},
+		{
+			name:      "Redis rules",
+			privilege: v1alpha1.PrivilegeSpec{Privilege: "+@read", On: "~app:*"},
+		},
+		{
+			name:      "Role",
+			privilege: v1alpha1.PrivilegeSpec{Privilege: "readonly"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.privilege.ValidateAny()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateAny() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, v1alpha1.ErrInvalidPrivilege) {
+				t.Fatalf("ValidateAny() error = %v, want %v", err, v1alpha1.ErrInvalidPrivilege)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// SetupPrivilegesWebhookWithManager registers validating webhook for Privileges.
+func SetupPrivilegesWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&Privileges{}).
+		WithValidator(&privilegesValidator{client: mgr.GetClient()}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-databaseusersoperator-com-v1alpha1-privileges,mutating=false,failurePolicy=fail,sideEffects=None,groups=databaseusersoperator.com,resources=privileges,verbs=create;update,versions=v1alpha1,name=vprivileges.databaseusersoperator.com,admissionReviewVersions=v1
+
+// privilegesValidator checks privileges against types of the databases,
+// where users and roles referencing the Privileges are created.
+type privilegesValidator struct {
+	client client.Reader
+}
+
+func (v *privilegesValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return v.validate(ctx, obj)
+}
+
+func (v *privilegesValidator) ValidateUpdate(ctx context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	return v.validate(ctx, newObj)
+}
+
+func (v *privilegesValidator) ValidateDelete(context.Context, runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *privilegesValidator) validate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	privileges, ok := obj.(*Privileges)
+	if !ok {
+		return nil, fmt.Errorf("unexpected object type %T", obj)
+	}
+
+	dbTypes, err := v.databaseTypes(ctx, privileges.GetName())
+	if err != nil {
+		return nil, err
+	}
+
+	var warnings admission.Warnings
+	if len(dbTypes) < 1 {
+		warnings = append(warnings, "privileges are not used by any user or role, so they are checked against privileges known for every database type")
+	}
+
+	var errs field.ErrorList
+	for i, privilege := range privileges.Privileges {
+		if len(dbTypes) < 1 {
+			if err := privilege.ValidateAny(); err != nil {
+				errs = append(errs, field.Invalid(field.NewPath("privileges").Index(i), privilege, err.Error()))
+			}
+			continue
+		}
+		for _, dbType := range dbTypes {
+			if err := privilege.Validate(dbType); err != nil {
+				errs = append(errs, field.Invalid(field.NewPath("privileges").Index(i), privilege, err.Error()))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return warnings, apierrors.NewInvalid(GroupVersion.WithKind("Privileges").GroupKind(), privileges.GetName(), errs)
+	}
+	return warnings, nil
+}
+
+// databaseTypes returns types of the databases, where Privileges with provided name are applied.
+func (v *privilegesValidator) databaseTypes(ctx context.Context, name string) ([]DatabaseType, error) {
+	users := &UserList{}
+	if err := v.client.List(ctx, users); err != nil {
+		return nil, err
+	}
+	namespacedUsers := &NamespacedUserList{}
+	if err := v.client.List(ctx, namespacedUsers); err != nil {
+		return nil, err
+	}
+	roles := &RoleList{}
+	if err := v.client.List(ctx, roles); err != nil {
+		return nil, err
+	}
+
+	var specs []UserSpec
+	for _, user := range users.Items {
+		specs = append(specs, user.Spec)
+	}
+	for _, user := range namespacedUsers.Items {
+		specs = append(specs, user.Spec)
+	}
+	for _, role := range roles.Items {
+		spec := UserSpec{}
+		for _, dbRef := range role.Spec.Databases {
+			spec.Databases = append(spec.Databases, DatabaseRef{Name: dbRef.Name, Privileges: dbRef.Privileges})
+		}
+		specs = append(specs, spec)
+	}
+
+	seen := make(map[string]bool)
+	var dbTypes []DatabaseType
+	for _, spec := range specs {
+		for _, dbRef := range spec.Databases {
+			if seen[dbRef.Name] || !containsName(dbRef.Privileges, name) {
+				continue
+			}
+			seen[dbRef.Name] = true
+
+			db := &Database{}
+			if err := v.client.Get(ctx, types.NamespacedName{Name: dbRef.Name}, db); err != nil {
+				if apierrors.IsNotFound(err) {
+					continue
+				}
+				return nil, err
+			}
+			dbTypes = append(dbTypes, db.Spec.Type)
+		}
+	}
+	return dbTypes, nil
+}
+
+// validatePrivileges checks referenced Privileges against the type of the database.
+func validatePrivileges(ctx context.Context, c client.Reader, dbType DatabaseType, dbRef DatabaseRef) error {
+	for _, name := range dbRef.Privileges {
+		privileges := &Privileges{}
+		if err := c.Get(ctx, types.NamespacedName{Name: name.Name}, privileges); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+
+		for _, privilege := range privileges.Privileges {
+			if err := privilege.Validate(dbType); err != nil {
+				return fmt.Errorf("privileges %s: %w", name.Name, err)
+			}
+		}
+	}
+	return nil
+}
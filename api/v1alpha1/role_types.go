@@ -0,0 +1,105 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RoleSpec defines the desired state of Role.
+type RoleSpec struct {
+	// The name of the role in the databases, not required. Defaults to the name of the Role CR.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="roleName is immutable"
+	// +optional
+	RoleName string `json:"roleName,omitempty"`
+
+	// List of databases, where role needs to be created with privileges for it.
+	Databases []RoleDatabaseRef `json:"databases"`
+}
+
+type RoleDatabaseRef struct {
+	// The name of the Database CR to create role in, required.
+	Name string `json:"name"`
+
+	// List of references to Privileges CR, that will be applied to created role in the database, required.
+	Privileges []Name `json:"privileges"`
+}
+
+// RoleStatus defines the observed state of Role.
+type RoleStatus struct {
+	Summary StatusSummary `json:"summary,omitempty"`
+
+	// Standard conditions of the Role, see ConditionReady.
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// State of the role in every database from the spec, that was applied by the operator.
+	Databases []RoleDatabaseStatus `json:"databases,omitempty"`
+}
+
+// RoleDatabaseStatus defines the observed state of Role in the Database.
+type RoleDatabaseStatus struct {
+	// The name of the Database CR.
+	Name string `json:"name"`
+
+	// Whether the role was created in the database.
+	RoleCreated bool `json:"roleCreated,omitempty"`
+
+	// Error occurred during the last reconcile of the role in the database, empty on success.
+	LastError string `json:"lastError,omitempty"`
+
+	// List of privileges, that were applied to the role in the database during the last reconcile.
+	// Privileges that are removed from the referenced Privileges CRs would be revoked from the role.
+	AppliedPrivileges []PrivilegeSpec `json:"appliedPrivileges,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:scope=Cluster
+
+// Role is the Schema for the roles API.
+// It creates group role without login (NOLOGIN role for PostgreSQL, role for MySQL 8 and MariaDB) in the databases,
+// users can be granted membership in it with DatabaseRef.Roles.
+type Role struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RoleSpec   `json:"spec,omitempty"`
+	Status RoleStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// RoleList contains a list of Role.
+type RoleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Role `json:"items"`
+}
+
+// DatabaseRoleName returns the name of the role in the databases.
+func (r *Role) DatabaseRoleName() string {
+	if r.Spec.RoleName != "" {
+		return r.Spec.RoleName
+	}
+	return r.GetName()
+}
+
+func init() {
+	SchemeBuilder.Register(&Role{}, &RoleList{})
+}
@@ -18,6 +18,9 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"errors"
+	"fmt"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -27,24 +30,297 @@ type UserSpec struct {
 	Databases []DatabaseRef `json:"databases"`
 }
 
+// +kubebuilder:validation:XValidation:rule="!has(self.rotation) || !has(self.passwordSecret) || size(self.passwordSecret.key) == 0",message="Rotation can be used only with generated passwords, unset .passwordSecret"
 type DatabaseRef struct {
 	// The name of the Database CR to create user in, required.
 	Name string `json:"name"`
 
 	// Reference to secret with password for user in the database, not required.
+	// If not set - password will be generated and stored in CreatedSecret under "password" key.
 	PasswordSecret Secret `json:"passwordSecret,omitempty"`
 
+	// Config for generating password for the user, if PasswordSecret is not set, not required.
+	PasswordGenerator *PasswordGenerator `json:"passwordGenerator,omitempty"`
+
+	// Policy for periodic rotation of generated password, not required.
+	// Can be used only if PasswordSecret is not set.
+	Rotation *RotationPolicy `json:"rotation,omitempty"`
+
 	// If operator would create data for user (for example for postgres with sslMode=="verify-full"),
 	// it is reference to non-existed Secret, that will be created during user creation in the database, not required.
 	CreatedSecret NamespacedName `json:"createdSecret,omitempty"`
 
 	// List of references to Privileges CR, that will be applied to created user in the database, required.
 	Privileges []Name `json:"privileges"`
+
+	// List of roles, that will be granted to created user in the database, not required.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Roles []RoleGrant `json:"roles,omitempty"`
+
+	// Database specific options of the user, applied on creation and updated, when changed, not required.
+	// +optional
+	Options *UserOptions `json:"options,omitempty"`
+
+	// List of host patterns from which the user will connect, MySQL and MariaDB only, not required.
+	// If not set - usersHostnames of the Database is used.
+	// +optional
+	UsersHostnames []string `json:"usersHostnames,omitempty"`
+}
+
+// UserOptions is database specific options of the user.
+// Only options for the type of the referenced Database are used.
+type UserOptions struct {
+	// Role attributes and parameters for PostgreSQL, not required.
+	// +optional
+	PostgreSQL *PostgreSQLUserOptions `json:"postgreSQL,omitempty"`
+
+	// Account options for MySQL, not required.
+	// +optional
+	MySQL *MySQLUserOptions `json:"mySQL,omitempty"`
+
+	// Account options for MariaDB, not required.
+	// +optional
+	MariaDB *MariaDBUserOptions `json:"mariaDB,omitempty"`
+
+	// Account options for ClickHouse, not required.
+	// +optional
+	ClickHouse *ClickHouseUserOptions `json:"clickHouse,omitempty"`
+}
+
+// ClickHouseUserOptions is account options of ClickHouse user.
+// Options, that are not set, are reset to ClickHouse defaults.
+type ClickHouseUserOptions struct {
+	// Settings profile of the user (SETTINGS PROFILE), not required.
+	// Settings profile with the quotas and limits should be created in ClickHouse beforehand.
+	// +optional
+	SettingsProfile string `json:"settingsProfile,omitempty"`
+
+	// Default database of the user (DEFAULT DATABASE), defaults to NONE.
+	// +optional
+	DefaultDatabase string `json:"defaultDatabase,omitempty"`
+
+	// List of networks from which the user can connect (HOST IP), defaults to ANY.
+	// +optional
+	HostIPs []string `json:"hostIPs,omitempty"`
+
+	// Quota of the user (ALTER QUOTA ... TO), not required.
+	// Quota should be created in ClickHouse beforehand, other roles and users it is applied to are kept.
+	// +optional
+	Quota string `json:"quota,omitempty"`
+}
+
+// MariaDBUserOptions is account options of MariaDB user.
+// Options, that are not set, are reset to MariaDB defaults.
+type MariaDBUserOptions struct {
+	// Authentication plugin of the user (IDENTIFIED VIA), defaults to mysql_native_password.
+	// Plugin is changed only together with the password of the user.
+	// +kubebuilder:validation:Enum=mysql_native_password;ed25519;unix_socket
+	// +optional
+	AuthPlugin string `json:"authPlugin,omitempty"`
+
+	MySQLAccountOptions `json:",inline"`
+}
+
+// MySQLUserOptions is account options of MySQL user.
+// Options, that are not set, are reset to MySQL defaults.
+type MySQLUserOptions struct {
+	// Authentication plugin of the user (IDENTIFIED WITH), defaults to server default plugin.
+	// Plugin is changed only together with the password of the user.
+	// +kubebuilder:validation:Enum=caching_sha2_password;mysql_native_password;auth_socket
+	// +optional
+	AuthPlugin string `json:"authPlugin,omitempty"`
+
+	MySQLAccountOptions `json:",inline"`
+}
+
+// MySQLAccountOptions is account options, that are the same for MySQL and MariaDB users.
+// +kubebuilder:validation:XValidation:rule="!has(self.requireTLS) || (!has(self.requireSubject) && !has(self.requireIssuer))",message="requireTLS can't be used with requireSubject or requireIssuer"
+type MySQLAccountOptions struct {
+	// Type of TLS connection required for the user (REQUIRE), defaults to NONE.
+	// +kubebuilder:validation:Enum=NONE;SSL;X509
+	// +optional
+	RequireTLS string `json:"requireTLS,omitempty"`
+
+	// Subject of the client certificate, that is required for the user (REQUIRE SUBJECT), not required.
+	// +optional
+	RequireSubject string `json:"requireSubject,omitempty"`
+
+	// Issuer of the client certificate, that is required for the user (REQUIRE ISSUER), not required.
+	// +optional
+	RequireIssuer string `json:"requireIssuer,omitempty"`
+
+	// Max number of queries per hour (MAX_QUERIES_PER_HOUR), 0 means no limit, not required.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxQueriesPerHour int32 `json:"maxQueriesPerHour,omitempty"`
+
+	// Max number of updates per hour (MAX_UPDATES_PER_HOUR), 0 means no limit, not required.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxUpdatesPerHour int32 `json:"maxUpdatesPerHour,omitempty"`
+
+	// Max number of connections per hour (MAX_CONNECTIONS_PER_HOUR), 0 means no limit, not required.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxConnectionsPerHour int32 `json:"maxConnectionsPerHour,omitempty"`
+
+	// Max number of concurrent connections (MAX_USER_CONNECTIONS), 0 means global limit is used, not required.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxUserConnections int32 `json:"maxUserConnections,omitempty"`
+
+	// Password lifetime in days (PASSWORD EXPIRE INTERVAL), 0 means password never expires, MariaDB 10.4.3+.
+	// If not set - global expiration policy is used.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	PasswordExpireDays *int32 `json:"passwordExpireDays,omitempty"`
+
+	// Lock the account (ACCOUNT LOCK), MariaDB 10.4.2+, not required.
+	// +optional
+	AccountLocked bool `json:"accountLocked,omitempty"`
+}
+
+// PostgreSQLUserOptions is role attributes and configuration parameters of PostgreSQL user.
+// Attributes, that are removed from options, are reset to PostgreSQL defaults,
+// attributes, that are not changed, are not set again.
+type PostgreSQLUserOptions struct {
+	// Allow user to create databases (CREATEDB), not required.
+	// +optional
+	CreateDB bool `json:"createDB,omitempty"`
+
+	// Allow user to create roles (CREATEROLE), not required.
+	// +optional
+	CreateRole bool `json:"createRole,omitempty"`
+
+	// Allow user to initiate streaming replication (REPLICATION), not required.
+	// +optional
+	Replication bool `json:"replication,omitempty"`
+
+	// Bypass row level security policies (BYPASSRLS), not required.
+	// +optional
+	BypassRLS bool `json:"bypassRLS,omitempty"`
+
+	// Whether user inherits privileges of roles it is a member of (INHERIT), defaults to true.
+	// +optional
+	Inherit *bool `json:"inherit,omitempty"`
+
+	// Max number of concurrent connections of the user (CONNECTION LIMIT), defaults to -1 (no limit).
+	// +kubebuilder:validation:Minimum=-1
+	// +optional
+	ConnectionLimit *int32 `json:"connectionLimit,omitempty"`
+
+	// Time after which user's password is no longer valid (VALID UNTIL), not required.
+	// +optional
+	ValidUntil *metav1.Time `json:"validUntil,omitempty"`
+
+	// Configuration parameters of the user session (ALTER ROLE ... SET), for example search_path or statement_timeout.
+	// Comma separated values are set as list, not required.
+	// +optional
+	Parameters map[string]string `json:"parameters,omitempty"`
+}
+
+// RoleGrant is config for granting role membership to the user.
+type RoleGrant struct {
+	// The name of the role in the database, required.
+	Name string `json:"name"`
+
+	// Allow user to grant the role to other users ("WITH ADMIN OPTION"), not required.
+	// +optional
+	AdminOption bool `json:"adminOption,omitempty"`
+
+	// Whether user inherits privileges of the role, PostgreSQL 16+ only, not required.
+	// If not set - server default is used.
+	// +optional
+	Inherit *bool `json:"inherit,omitempty"`
+
+	// Activate the role by default, when user connects to the database, MySQL, MariaDB and ClickHouse only, not required.
+	// MariaDB allows only one default role.
+	// ClickHouse activates all granted roles, if none of them is default.
+	// +optional
+	Default bool `json:"default,omitempty"`
+}
+
+// PasswordGenerator is config for generating users passwords.
+type PasswordGenerator struct {
+	// +kubebuilder:validation:Minimum=8
+	// +kubebuilder:default=32
+	// Length of generated password, defaults to 32.
+	Length int `json:"length,omitempty"`
+
+	// +kubebuilder:validation:MinLength=2
+	// Characters that will be used for generating password, not required.
+	// By default letters and digits are used.
+	Charset string `json:"charset,omitempty"`
+}
+
+// RotationPolicy is config for periodic rotation of generated users passwords.
+type RotationPolicy struct {
+	// Interval between password rotations, for example "720h", required.
+	Interval metav1.Duration `json:"interval"`
+
+	// If true - two users will be created in the database ("<name>" and "<name>_alt")
+	// with the same privileges and every rotation will switch CreatedSecret to the other one,
+	// so previous credentials keep working until the next rotation, not required.
+	DualCredentials bool `json:"dualCredentials,omitempty"`
 }
 
 // UserStatus defines the observed state of User.
 type UserStatus struct {
 	Summary StatusSummary `json:"summary,omitempty"`
+
+	// Standard conditions of the User, see ConditionReady.
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// State of the user in every database from the spec, that was applied by the operator.
+	Databases []DatabaseStatus `json:"databases,omitempty"`
+}
+
+// DatabaseStatus defines the observed state of User in the Database.
+type DatabaseStatus struct {
+	// The name of the Database CR.
+	Name string `json:"name"`
+
+	// Whether the user was created in the database.
+	UserCreated bool `json:"userCreated,omitempty"`
+
+	// Whether all privileges were applied to the user during the last reconcile.
+	PrivilegesApplied bool `json:"privilegesApplied,omitempty"`
+
+	// Error occurred during the last reconcile of the user in the database, empty on success.
+	LastError string `json:"lastError,omitempty"`
+
+	// The generation of the User, that was reconciled in the database last time.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// List of privileges, that were applied to the user in the database during the last reconcile.
+	// Privileges that are removed from the referenced Privileges CRs would be revoked from the user.
+	AppliedPrivileges []PrivilegeSpec `json:"appliedPrivileges,omitempty"`
+
+	// List of roles, that were granted to the user in the database during the last reconcile.
+	// Roles that are removed from the spec would be revoked from the user.
+	AppliedRoles []RoleGrant `json:"appliedRoles,omitempty"`
+
+	// Options of the user, that were applied in the database during the last reconcile.
+	AppliedOptions *UserOptions `json:"appliedOptions,omitempty"`
+
+	// List of host patterns, for which the user was created in the database during the last reconcile, MySQL and MariaDB only.
+	// Users for hosts that are removed from the spec would be dropped.
+	AppliedUsersHostnames []string `json:"appliedUsersHostnames,omitempty"`
+
+	// Hash of the password, that was set for the user in the database during the last reconcile.
+	// When password in the referenced secret changes - it will be updated in the database.
+	PasswordHash string `json:"passwordHash,omitempty"`
+
+	// Time of the last rotation of generated password, set only if rotation is configured.
+	LastRotationTime *metav1.Time `json:"lastRotationTime,omitempty"`
+
+	// Name of the user in the database, which credentials are currently stored in CreatedSecret.
+	// Set only if rotation with dual credentials is configured.
+	ActiveUsername string `json:"activeUsername,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -69,6 +345,56 @@ type UserList struct {
 	Items           []User `json:"items"`
 }
 
+// GetSpec returns pointer to the User spec.
+func (u *User) GetSpec() *UserSpec {
+	return &u.Spec
+}
+
+// GetStatus returns pointer to the User status.
+func (u *User) GetStatus() *UserStatus {
+	return &u.Status
+}
+
+// DualCredentialsSuffix is appended to the name of the second user in dual credentials mode.
+const DualCredentialsSuffix = "_alt"
+
+var ErrUsernameTooLong = errors.New("username is too long")
+
+// maxUsernameLength is the maximum length of the user name for databases, that limit it.
+var maxUsernameLength = map[DatabaseType]int{
+	PostgreSQL: 63,
+	MySQL:      32,
+	MariaDB:    80,
+	MSSQL:      128,
+}
+
+// DatabaseUsername returns name of the user in the databases.
+// NamespacedUser is prefixed with its namespace to avoid collisions between namespaces,
+// namespace is empty for cluster scoped User.
+func DatabaseUsername(namespace, name string) string {
+	if namespace != "" {
+		return namespace + "_" + name
+	}
+	return name
+}
+
+// ValidateUsernameLength checks, that the user name fits into the limit of the database type.
+// Suffix of the second user is taken into account, when dual credentials are configured in the DatabaseRef.
+func ValidateUsernameLength(dbType DatabaseType, username string, dbRef DatabaseRef) error {
+	limit, ok := maxUsernameLength[dbType]
+	if !ok {
+		return nil
+	}
+
+	if dbRef.Rotation != nil && dbRef.Rotation.DualCredentials {
+		username += DualCredentialsSuffix
+	}
+	if len(username) > limit {
+		return fmt.Errorf("%w: %s is longer than %d characters allowed by %s", ErrUsernameTooLong, username, limit, dbType)
+	}
+	return nil
+}
+
 func init() {
 	SchemeBuilder.Register(&User{}, &UserList{})
 }
@@ -0,0 +1,97 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/alex123012/database-users-operator/api/v1alpha1"
+)
+
+func TestValidateUsernameLength(t *testing.T) {
+	dualCredentials := v1alpha1.DatabaseRef{Rotation: &v1alpha1.RotationPolicy{DualCredentials: true}}
+
+	tests := []struct {
+		name     string
+		dbType   v1alpha1.DatabaseType
+		username string
+		dbRef    v1alpha1.DatabaseRef
+		wantErr  bool
+	}{
+		{
+			name:     "MySQL name at the limit",
+			dbType:   v1alpha1.MySQL,
+			username: strings.Repeat("a", 32),
+		},
+		{
+			name:     "MySQL name over the limit",
+			dbType:   v1alpha1.MySQL,
+			username: v1alpha1.DatabaseUsername("team-backend", "orders-service-reader"),
+			wantErr:  true,
+		},
+		{
+			name:     "MySQL name over the limit with dual credentials suffix",
+			dbType:   v1alpha1.MySQL,
+			username: strings.Repeat("a", 30),
+			dbRef:    dualCredentials,
+			wantErr:  true,
+		},
+		{
+			name:     "PostgreSQL name at the limit",
+			dbType:   v1alpha1.PostgreSQL,
+			username: strings.Repeat("a", 63),
+		},
+		{
+			name:     "PostgreSQL name over the limit",
+			dbType:   v1alpha1.PostgreSQL,
+			username: strings.Repeat("a", 64),
+			wantErr:  true,
+		},
+		{
+			name:     "MariaDB name is longer than MySQL limit",
+			dbType:   v1alpha1.MariaDB,
+			username: strings.Repeat("a", 40),
+		},
+		{
+			name:     "ClickHouse has no limit",
+			dbType:   v1alpha1.ClickHouse,
+			username: strings.Repeat("a", 300),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v1alpha1.ValidateUsernameLength(tt.dbType, tt.username, tt.dbRef)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateUsernameLength() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, v1alpha1.ErrUsernameTooLong) {
+				t.Errorf("ValidateUsernameLength() error = %v, want %v", err, v1alpha1.ErrUsernameTooLong)
+			}
+		})
+	}
+}
+
+func TestDatabaseUsername(t *testing.T) {
+	if got := v1alpha1.DatabaseUsername("", "john"); got != "john" {
+		t.Errorf("DatabaseUsername() = %v, want john", got)
+	}
+	if got := v1alpha1.DatabaseUsername("team", "john"); got != "team_john" {
+		t.Errorf("DatabaseUsername() = %v, want team_john", got)
+	}
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// SetupUserWebhookWithManager registers validating webhooks for User and NamespacedUser.
+func SetupUserWebhookWithManager(mgr ctrl.Manager) error {
+	validator := &userValidator{client: mgr.GetClient()}
+	if err := ctrl.NewWebhookManagedBy(mgr).For(&User{}).WithValidator(validator).Complete(); err != nil {
+		return err
+	}
+	return ctrl.NewWebhookManagedBy(mgr).For(&NamespacedUser{}).WithValidator(validator).Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-databaseusersoperator-com-v1alpha1-user,mutating=false,failurePolicy=fail,sideEffects=None,groups=databaseusersoperator.com,resources=users,verbs=create;update,versions=v1alpha1,name=vuser.databaseusersoperator.com,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-databaseusersoperator-com-v1alpha1-namespaceduser,mutating=false,failurePolicy=fail,sideEffects=None,groups=databaseusersoperator.com,resources=namespacedusers,verbs=create;update,versions=v1alpha1,name=vnamespaceduser.databaseusersoperator.com,admissionReviewVersions=v1
+
+// userValidator rejects users, that reference databases or privileges not permitted by the Database access policy.
+type userValidator struct {
+	client client.Reader
+}
+
+func (v *userValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return v.validate(ctx, obj)
+}
+
+func (v *userValidator) ValidateUpdate(ctx context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	return v.validate(ctx, newObj)
+}
+
+func (v *userValidator) ValidateDelete(context.Context, runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *userValidator) validate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	var (
+		namespace, name, resource, kind string
+		spec                            UserSpec
+	)
+	switch user := obj.(type) {
+	case *User:
+		name, resource, kind, spec = user.Name, "users", "User", user.Spec
+	case *NamespacedUser:
+		namespace, name, resource, kind, spec = user.Namespace, user.Name, "namespacedusers", "NamespacedUser", user.Spec
+	default:
+		return nil, fmt.Errorf("unexpected object type %T", obj)
+	}
+
+	warnings, err := ValidateDatabasesAccess(ctx, v.client, namespace, spec)
+	if err != nil {
+		return warnings, apierrors.NewForbidden(GroupVersion.WithResource(resource).GroupResource(), name, err)
+	}
+
+	var errs field.ErrorList
+	for i, dbRef := range spec.Databases {
+		db := &Database{}
+		if err := v.client.Get(ctx, types.NamespacedName{Name: dbRef.Name}, db); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return warnings, err
+		}
+		if err := ValidateUsernameLength(db.Spec.Type, DatabaseUsername(namespace, name), dbRef); err != nil {
+			errs = append(errs, field.Invalid(field.NewPath("metadata", "name"), name, err.Error()))
+		}
+		if err := validatePrivileges(ctx, v.client, db.Spec.Type, dbRef); err != nil {
+			errs = append(errs, field.Invalid(field.NewPath("spec", "databases").Index(i).Child("privileges"), dbRef.Privileges, err.Error()))
+		}
+	}
+	if len(errs) > 0 {
+		return warnings, apierrors.NewInvalid(GroupVersion.WithKind(kind).GroupKind(), name, errs)
+	}
+	return warnings, nil
+}
+
+// ValidateDatabasesAccess checks, that user from the namespace (empty for cluster scoped User)
+// is permitted to use referenced databases with referenced privileges and roles.
+// Databases that don't exist yet are skipped and reported as warnings.
+func ValidateDatabasesAccess(ctx context.Context, c client.Reader, namespace string, spec UserSpec) (admission.Warnings, error) {
+	var namespaceLabels map[string]string
+	if namespace != "" {
+		ns := &v1.Namespace{}
+		if err := c.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+			return nil, err
+		}
+		namespaceLabels = ns.GetLabels()
+	}
+
+	var warnings admission.Warnings
+	for _, dbRef := range spec.Databases {
+		db := &Database{}
+		if err := c.Get(ctx, types.NamespacedName{Name: dbRef.Name}, db); err != nil {
+			if apierrors.IsNotFound(err) {
+				warnings = append(warnings, fmt.Sprintf("database %s not found, access policy can't be checked", dbRef.Name))
+				continue
+			}
+			return warnings, err
+		}
+
+		if err := db.Spec.AccessPolicy.Allows(namespace, namespaceLabels, dbRef); err != nil {
+			return warnings, fmt.Errorf("database %s: %w", dbRef.Name, err)
+		}
+	}
+	return warnings, nil
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/alex123012/database-users-operator/api/v1alpha1"
+	"github.com/alex123012/database-users-operator/pkg/database"
+)
+
+const (
+	// DefaultDatabaseCheckInterval is used, if DatabaseReconciler.CheckInterval is not set.
+	DefaultDatabaseCheckInterval = time.Minute
+
+	reasonConnected        = "Connected"
+	reasonConnectionFailed = "ConnectionFailed"
+)
+
+var ErrDatabaseNotReady = errors.New("database is not ready")
+
+// DatabaseReconciler periodically checks connectivity to the databases
+// and reports it in the Database status.
+type DatabaseReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	Databases     *database.Cache
+	CheckInterval time.Duration
+}
+
+//+kubebuilder:rbac:groups=databaseusersoperator.com,resources=databases,verbs=get;list;watch
+//+kubebuilder:rbac:groups=databaseusersoperator.com,resources=databases/status,verbs=get;update;patch
+
+// Reconcile connects to the database and updates its status with the check result.
+// Check is repeated every CheckInterval.
+func (r *DatabaseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues("NAME", req.NamespacedName.Name)
+
+	db := &v1alpha1.Database{}
+	if err := r.Get(ctx, req.NamespacedName, db); err != nil {
+		if apierrors.IsNotFound(err) {
+			logger.Info("Database resource not found. Closing connection since object must be deleted")
+			r.Databases.Invalidate(ctx, req.Name)
+			return ctrl.Result{}, nil
+		}
+		logger.Error(err, "Failed to get database resource")
+		return ctrl.Result{}, err
+	}
+
+	condition := metav1.Condition{
+		Type:               v1alpha1.ConditionReady,
+		Status:             metav1.ConditionTrue,
+		Reason:             reasonConnected,
+		Message:            "Successfully connected to the database",
+		ObservedGeneration: db.GetGeneration(),
+	}
+	if err := r.check(ctx, db, logger); err != nil {
+		logger.Error(err, "Database check failed")
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = reasonConnectionFailed
+		condition.Message = err.Error()
+	}
+	meta.SetStatusCondition(&db.Status.Conditions, condition)
+
+	now := metav1.Now()
+	db.Status.LastCheckTime = &now
+	if err := r.Status().Update(ctx, db); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: r.checkInterval()}, nil
+}
+
+// check connects to the database, pings it and fills server info in the Database status.
+// Cached connection is pinged, so the check fails, when the database becomes unreachable after connect,
+// and the connection is invalidated, so the next check connects again.
+// Server info is informational only (e.g. it may require privileges, that operator doesn't have),
+// so failure to get it is logged and doesn't fail the check.
+func (r *DatabaseReconciler) check(ctx context.Context, dbConfig *v1alpha1.Database, logger logr.Logger) error {
+	db, err := r.Databases.Get(ctx, dbConfig, r.Client, logger)
+	if err != nil {
+		return errors.Join(ErrDatabaseConnect, err)
+	}
+	defer db.Close(ctx)
+
+	if err := db.Ping(ctx); err != nil {
+		r.Databases.Invalidate(ctx, dbConfig.GetName())
+		return errors.Join(ErrDatabaseConnect, err)
+	}
+
+	version, err := db.ServerVersion(ctx)
+	if err != nil {
+		logger.Error(err, "Failed to get database server version")
+	}
+
+	tls, err := db.TLSEnabled(ctx)
+	if err != nil {
+		logger.Error(err, "Failed to check whether TLS is enabled for database connection")
+	}
+
+	dbConfig.Status.ServerVersion = version
+	dbConfig.Status.TLSEnabled = tls
+	return nil
+}
+
+func (r *DatabaseReconciler) checkInterval() time.Duration {
+	if r.CheckInterval > 0 {
+		return r.CheckInterval
+	}
+	return DefaultDatabaseCheckInterval
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *DatabaseReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.Database{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(r)
+}
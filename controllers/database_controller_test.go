@@ -0,0 +1,206 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/alex123012/database-users-operator/api/v1alpha1"
+)
+
+var _ = Describe("DatabaseController", Ordered, func() {
+	Context("Reachable PostgreSQL", Ordered, func() {
+		var (
+			user       *v1alpha1.User
+			secret     *v1.Secret
+			database   *v1alpha1.Database
+			privileges *v1alpha1.Privileges
+		)
+
+		BeforeAll(func() {
+			user, secret, database, privileges = bundle(namespace, v1alpha1.PostgreSQL)
+			database.Spec.PostgreSQL = defaultPostgresConfig()
+
+			fakeDB.Conn.ResetDB()
+			fakeDB.Conn.SetResult("15.4", "SELECT current_setting('server_version')")
+			createObjects(secret, database)
+		})
+
+		AfterAll(func() {
+			deleteObjects(secret, database)
+			fakeDB.Conn.ResetDB()
+		})
+
+		It("reports server info in status", func() {
+			fetchedDatabase := &v1alpha1.Database{}
+			Eventually(func() bool {
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(database), fetchedDatabase)).To(Succeed())
+				return meta.IsStatusConditionTrue(fetchedDatabase.Status.Conditions, v1alpha1.ConditionReady)
+			}, userCreationTimeout, time.Second).Should(BeTrue())
+
+			Expect(fetchedDatabase.Status.ServerVersion).To(Equal("15.4"))
+			Expect(fetchedDatabase.Status.TLSEnabled).To(BeFalse())
+			Expect(fetchedDatabase.Status.LastCheckTime).NotTo(BeNil())
+		})
+
+		It("lets users to be created", func() {
+			createObjects(privileges, user)
+			waitForUsersReadiness(user)
+			deleteObjects(user, privileges)
+		})
+	})
+
+	Context("PostgreSQL becoming unreachable", Ordered, func() {
+		var (
+			secret   *v1.Secret
+			database *v1alpha1.Database
+		)
+
+		BeforeAll(func() {
+			_, secret, database, _ = bundle(namespace, v1alpha1.PostgreSQL)
+			database.Spec.PostgreSQL = defaultPostgresConfig()
+
+			fakeDB.Conn.ResetDB()
+			createObjects(secret, database)
+		})
+
+		AfterAll(func() {
+			deleteObjects(secret, database)
+			fakeDB.Conn.ResetDB()
+		})
+
+		It("reports failed check for cached connection", func() {
+			fetchedDatabase := &v1alpha1.Database{}
+			Eventually(func() bool {
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(database), fetchedDatabase)).To(Succeed())
+				return meta.IsStatusConditionTrue(fetchedDatabase.Status.Conditions, v1alpha1.ConditionReady)
+			}, userCreationTimeout, time.Second).Should(BeTrue())
+
+			fakeDB.Conn.SetPingError(errors.New("connection refused"))
+			Eventually(func() bool {
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(database), fetchedDatabase)).To(Succeed())
+				return meta.IsStatusConditionFalse(fetchedDatabase.Status.Conditions, v1alpha1.ConditionReady)
+			}, userCreationTimeout, time.Second).Should(BeTrue())
+			Expect(meta.FindStatusCondition(fetchedDatabase.Status.Conditions, v1alpha1.ConditionReady).Message).To(ContainSubstring("connection refused"))
+		})
+
+		It("reports ready database after it becomes reachable again", func() {
+			fakeDB.Conn.SetPingError(nil)
+			fetchedDatabase := &v1alpha1.Database{}
+			Eventually(func() bool {
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(database), fetchedDatabase)).To(Succeed())
+				return meta.IsStatusConditionTrue(fetchedDatabase.Status.Conditions, v1alpha1.ConditionReady)
+			}, userCreationTimeout, time.Second).Should(BeTrue())
+		})
+	})
+
+	Context("PostgreSQL without server info", Ordered, func() {
+		var (
+			secret   *v1.Secret
+			database *v1alpha1.Database
+		)
+
+		BeforeAll(func() {
+			_, secret, database, _ = bundle(namespace, v1alpha1.PostgreSQL)
+			database.Spec.PostgreSQL = defaultPostgresConfig()
+
+			fakeDB.Conn.ResetDB()
+			createObjects(secret, database)
+		})
+
+		AfterAll(func() {
+			deleteObjects(secret, database)
+			fakeDB.Conn.ResetDB()
+		})
+
+		It("reports ready database with empty server info", func() {
+			fetchedDatabase := &v1alpha1.Database{}
+			Eventually(func() bool {
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(database), fetchedDatabase)).To(Succeed())
+				return meta.IsStatusConditionTrue(fetchedDatabase.Status.Conditions, v1alpha1.ConditionReady)
+			}, userCreationTimeout, time.Second).Should(BeTrue())
+
+			Expect(fetchedDatabase.Status.ServerVersion).To(BeEmpty())
+			Expect(fetchedDatabase.Status.TLSEnabled).To(BeFalse())
+		})
+	})
+
+	Context("Unreachable PostgreSQL", Ordered, func() {
+		var (
+			user       *v1alpha1.User
+			secret     *v1.Secret
+			database   *v1alpha1.Database
+			privileges *v1alpha1.Privileges
+		)
+
+		BeforeAll(func() {
+			user, secret, database, privileges = bundle(namespace, v1alpha1.PostgreSQL)
+			database.Spec.PostgreSQL = defaultPostgresConfig()
+			database.Spec.PostgreSQL.PasswordSecret.Secret.Name = "missing-password"
+
+			fakeDB.Conn.ResetDB()
+			createObjects(secret, database, privileges)
+		})
+
+		AfterAll(func() {
+			// User can't be deleted from the unreachable database, so remove finalizer manually.
+			Expect(k8sClient.Delete(ctx, user)).To(Succeed())
+			Eventually(func() error {
+				fetchedUser := &v1alpha1.User{}
+				if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(user), fetchedUser); err != nil {
+					return client.IgnoreNotFound(err)
+				}
+				fetchedUser.SetFinalizers(nil)
+				return k8sClient.Update(ctx, fetchedUser)
+			}, userCreationTimeout, time.Second).Should(Succeed())
+			Eventually(objectNotFound, 5).WithArguments(user).Should(BeTrue())
+
+			deleteObjects(secret, database, privileges)
+			fakeDB.Conn.ResetDB()
+		})
+
+		It("reports failed check in status", func() {
+			fetchedDatabase := &v1alpha1.Database{}
+			Eventually(func() bool {
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(database), fetchedDatabase)).To(Succeed())
+				return meta.IsStatusConditionFalse(fetchedDatabase.Status.Conditions, v1alpha1.ConditionReady)
+			}, userCreationTimeout, time.Second).Should(BeTrue())
+
+			Expect(meta.FindStatusCondition(fetchedDatabase.Status.Conditions, v1alpha1.ConditionReady).Message).To(ContainSubstring("missing-password"))
+		})
+
+		It("reports not ready database in user status", func() {
+			createObjects(user)
+
+			fetchedUser := &v1alpha1.User{}
+			Eventually(func() []v1alpha1.DatabaseStatus {
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(user), fetchedUser)).To(Succeed())
+				return fetchedUser.Status.Databases
+			}, userCreationTimeout, time.Second).Should(HaveLen(1))
+
+			Expect(fetchedUser.Status.Databases[0].LastError).To(ContainSubstring("database is not ready"))
+			Expect(fakeDB.Conn.Queries()).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,114 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/alex123012/database-users-operator/api/v1alpha1"
+)
+
+var _ = Describe("NamespacedUserController", Ordered, func() {
+	var (
+		user       *v1alpha1.NamespacedUser
+		secret     *v1.Secret
+		database   *v1alpha1.Database
+		privileges *v1alpha1.Privileges
+	)
+
+	BeforeAll(func() {
+		var clusterUser *v1alpha1.User
+		clusterUser, secret, database, privileges = bundle(namespace, v1alpha1.PostgreSQL)
+		database.Spec.PostgreSQL = defaultPostgresConfig()
+
+		user = &v1alpha1.NamespacedUser{
+			ObjectMeta: metav1.ObjectMeta{Name: "nsuser", Namespace: namespace},
+			Spec:       clusterUser.Spec,
+		}
+		user.Spec.Databases[0].PasswordSecret.Secret.Namespace = ""
+		user.Spec.Databases[0].CreatedSecret.Namespace = ""
+
+		fakeDB.Conn.ResetDB()
+		createObjects(secret, database, privileges, user)
+	})
+
+	AfterAll(func() {
+		deleteObjects(secret, database, privileges)
+		fakeDB.Conn.ResetDB()
+	})
+
+	It("creates user prefixed with namespace", func() {
+		Eventually(func() bool {
+			fetchedUser := &v1alpha1.NamespacedUser{}
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(user), fetchedUser)).To(Succeed())
+			return meta.IsStatusConditionTrue(fetchedUser.Status.Conditions, v1alpha1.ConditionReady)
+		}, userCreationTimeout, time.Second).Should(BeTrue())
+
+		Expect(fakeDB.Conn.Queries()).To(HaveKey(fmt.Sprintf(`CREATE USER "%s_nsuser" WITH PASSWORD 'mysupersecretpass'`, namespace)))
+		Expect(fakeDB.Conn.Queries()).To(HaveKey(fmt.Sprintf(`GRANT MY PRIVILEGE TO "%s_nsuser"`, namespace)))
+	})
+
+	It("rejects secrets from other namespaces", func() {
+		other := &v1alpha1.NamespacedUser{
+			ObjectMeta: metav1.ObjectMeta{Name: "nsuser-other", Namespace: namespace},
+			Spec:       *user.Spec.DeepCopy(),
+		}
+		other.Spec.Databases[0].PasswordSecret.Secret.Namespace = "kube-system"
+		createObjects(other)
+
+		Eventually(func() string {
+			fetchedUser := &v1alpha1.NamespacedUser{}
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(other), fetchedUser)).To(Succeed())
+			return fetchedUser.Status.Summary.Message
+		}, userCreationTimeout, time.Second).Should(ContainSubstring("must be in the " + namespace + " namespace"))
+
+		deleteObjects(other)
+	})
+
+	It("rejects names longer than the database limit", func() {
+		long := &v1alpha1.NamespacedUser{
+			ObjectMeta: metav1.ObjectMeta{Name: strings.Repeat("a", 63), Namespace: namespace},
+			Spec:       *user.Spec.DeepCopy(),
+		}
+		createObjects(long)
+
+		Eventually(func() string {
+			fetchedUser := &v1alpha1.NamespacedUser{}
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(long), fetchedUser)).To(Succeed())
+			return fetchedUser.Status.Summary.Message
+		}, userCreationTimeout, time.Second).Should(ContainSubstring(v1alpha1.ErrUsernameTooLong.Error()))
+		Expect(fakeDB.Conn.Queries()).NotTo(HaveKey(fmt.Sprintf(`CREATE USER "%s_%s" WITH PASSWORD 'mysupersecretpass'`, namespace, long.GetName())))
+
+		deleteObjects(long)
+	})
+
+	It("deletes user from the database", func() {
+		fakeDB.Conn.ResetDB()
+		deleteObjects(user)
+
+		Expect(fakeDB.Conn.Queries()).To(HaveKey(fmt.Sprintf(`DROP USER "%s_nsuser"`, namespace)))
+	})
+})
@@ -0,0 +1,355 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/alex123012/database-users-operator/api/v1alpha1"
+	"github.com/alex123012/database-users-operator/pkg/database"
+)
+
+const (
+	roleFinalizer  = "role.databaseusersoperator.com/finalizer"
+	roleSuccessMsg = "Successfully created role in all specified databases"
+)
+
+// RoleReconciler reconciles a Role object.
+type RoleReconciler struct {
+	client.Client
+	Scheme    *runtime.Scheme
+	Databases *database.Cache
+	Recorder  record.EventRecorder
+}
+
+//+kubebuilder:rbac:groups=databaseusersoperator.com,resources=roles,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=databaseusersoperator.com,resources=roles/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=databaseusersoperator.com,resources=roles/finalizers,verbs=update
+
+// Reconcile creates the role in every database from the spec and applies privileges to it.
+// The role is dropped from the databases, when Role is deleted.
+func (r *RoleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues("NAME", req.NamespacedName.Name)
+
+	role := &v1alpha1.Role{}
+	if err := r.Get(ctx, req.NamespacedName, role); err != nil {
+		if apierrors.IsNotFound(err) {
+			logger.Info("Role resource not found. Ignoring since object must be deleted.")
+			return ctrl.Result{}, nil
+		}
+		logger.Error(err, "unable to fetch Role resource")
+		return ctrl.Result{}, err
+	}
+
+	oldStatus := role.Status.DeepCopy()
+	deleting, err := r.reconcile(ctx, role, logger)
+	if err != nil {
+		r.Recorder.Event(role, v1.EventTypeWarning, "ErrorCreatingRole", err.Error())
+		if deleting {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, r.setStatus(ctx, role, oldStatus, v1alpha1.StatusSummary{Ready: false, Message: err.Error()})
+	}
+
+	if deleting {
+		return ctrl.Result{}, nil
+	}
+
+	if !role.Status.Summary.Ready {
+		r.Recorder.Event(role, v1.EventTypeNormal, "SuccessfullyCreatedRole", roleSuccessMsg)
+	}
+	return ctrl.Result{}, r.setStatus(ctx, role, oldStatus, v1alpha1.StatusSummary{Ready: true, Message: roleSuccessMsg})
+}
+
+func (r *RoleReconciler) reconcile(ctx context.Context, role *v1alpha1.Role, logger logr.Logger) (bool, error) {
+	if role.GetDeletionTimestamp() != nil {
+		logger.Info("Received deletion event")
+		if !controllerutil.ContainsFinalizer(role, roleFinalizer) {
+			return true, nil
+		}
+
+		if err := r.reconcileDatabases(ctx, role, true, logger); err != nil {
+			return true, err
+		}
+		logger.Info("Successfully deleted role from all specified databases")
+
+		controllerutil.RemoveFinalizer(role, roleFinalizer)
+		return true, r.Update(ctx, role)
+	}
+
+	if !controllerutil.ContainsFinalizer(role, roleFinalizer) {
+		logger.Info("Setting finalizer for resource")
+		controllerutil.AddFinalizer(role, roleFinalizer)
+		if err := r.Update(ctx, role); err != nil {
+			return false, err
+		}
+	}
+
+	err := r.reconcileDatabases(ctx, role, false, logger)
+	if err := errors.Join(err, r.dropRemovedDatabases(ctx, role, logger)); err != nil {
+		return false, err
+	}
+
+	logger.Info(roleSuccessMsg)
+	return false, nil
+}
+
+// setStatus updates status of the Role if it differs from the oldStatus.
+func (r *RoleReconciler) setStatus(ctx context.Context, role *v1alpha1.Role, oldStatus *v1alpha1.RoleStatus, summary v1alpha1.StatusSummary) error {
+	role.Status.Summary = summary
+
+	condition := metav1.Condition{
+		Type:               v1alpha1.ConditionReady,
+		Status:             metav1.ConditionTrue,
+		Reason:             reasonReconciled,
+		Message:            summary.Message,
+		ObservedGeneration: role.GetGeneration(),
+	}
+	if !summary.Ready {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = reasonReconcileFailed
+	}
+	meta.SetStatusCondition(&role.Status.Conditions, condition)
+
+	if equality.Semantic.DeepEqual(oldStatus, &role.Status) {
+		return nil
+	}
+	return r.Status().Update(ctx, role)
+}
+
+// reconcileDatabases processes every database from the Role spec, even if some of them fail,
+// and records result for each database in the Role status.
+func (r *RoleReconciler) reconcileDatabases(ctx context.Context, role *v1alpha1.Role, deleteRequest bool, logger logr.Logger) error {
+	var errs []error
+	for _, dbRef := range role.Spec.Databases {
+		err := r.reconcileDatabase(ctx, role, dbRef, deleteRequest, logger)
+
+		status := roleDatabaseStatus(role, dbRef.Name)
+		status.LastError = ""
+		if err != nil {
+			logger.Error(err, "Failed to reconcile role in the database", "DATABASE", dbRef.Name)
+			status.LastError = err.Error()
+			errs = append(errs, fmt.Errorf("database %s: %w", dbRef.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (r *RoleReconciler) reconcileDatabase(ctx context.Context, role *v1alpha1.Role, dbRef v1alpha1.RoleDatabaseRef, deleteRequest bool, logger logr.Logger) error {
+	if deleteRequest && !roleDatabaseStatus(role, dbRef.Name).RoleCreated {
+		// Role wasn't created by the operator (e.g. it refused to take over existing user), so there is nothing to drop.
+		return nil
+	}
+
+	dbConfig := &v1alpha1.Database{}
+	if err := r.Get(ctx, types.NamespacedName{Name: dbRef.Name}, dbConfig); err != nil {
+		return err
+	}
+
+	if !deleteRequest {
+		if condition := meta.FindStatusCondition(dbConfig.Status.Conditions, v1alpha1.ConditionReady); condition != nil && condition.Status == metav1.ConditionFalse {
+			return fmt.Errorf("%w: %s", ErrDatabaseNotReady, condition.Message)
+		}
+	}
+
+	privileges, err := referencedPrivileges(ctx, r.Client, dbRef.Privileges)
+	if err != nil {
+		return err
+	}
+
+	db, err := r.Databases.Get(ctx, dbConfig, r.Client, logger)
+	if err != nil {
+		return errors.Join(ErrDatabaseConnect, err)
+	}
+	defer db.Close(ctx)
+
+	name, status := role.DatabaseRoleName(), roleDatabaseStatus(role, dbRef.Name)
+	// Privileges could be already removed from spec, but still be applied to the role.
+	revoked := missingPrivileges(status.AppliedPrivileges, privileges)
+
+	if deleteRequest {
+		privileges = append(privileges, revoked...)
+		if err := db.RevokeRolePrivileges(ctx, name, privileges); err != nil {
+			return err
+		}
+		return database.WithPrivileges(db, privileges).DeleteRole(ctx, name)
+	}
+
+	if err := db.CreateRole(ctx, name); err != nil {
+		return err
+	}
+	status.RoleCreated = true
+
+	if len(revoked) > 0 {
+		logger.Info("Revoking privileges removed from spec", "DATABASE", dbRef.Name)
+		if err := db.RevokeRolePrivileges(ctx, name, revoked); err != nil {
+			return err
+		}
+	}
+
+	if err := db.ApplyRolePrivileges(ctx, name, privileges); err != nil {
+		return err
+	}
+	status.AppliedPrivileges = privileges
+	return nil
+}
+
+func roleDatabaseStatus(role *v1alpha1.Role, name string) *v1alpha1.RoleDatabaseStatus {
+	for i := range role.Status.Databases {
+		if role.Status.Databases[i].Name == name {
+			return &role.Status.Databases[i]
+		}
+	}
+	role.Status.Databases = append(role.Status.Databases, v1alpha1.RoleDatabaseStatus{Name: name})
+	return &role.Status.Databases[len(role.Status.Databases)-1]
+}
+
+// dropRemovedDatabases drops the role from databases, that were removed from the Role spec since the last reconcile,
+// and removes their statuses. Statuses of databases, where the role wasn't dropped, are kept to retry on the next reconcile.
+func (r *RoleReconciler) dropRemovedDatabases(ctx context.Context, role *v1alpha1.Role, logger logr.Logger) error {
+	var errs []error
+	statuses := make([]v1alpha1.RoleDatabaseStatus, 0, len(role.Status.Databases))
+	for _, status := range role.Status.Databases {
+		if isRoleDatabaseReferenced(role, status.Name) {
+			statuses = append(statuses, status)
+			continue
+		}
+		if !status.RoleCreated {
+			continue
+		}
+
+		logger.Info("Dropping role from database removed from spec", "DATABASE", status.Name)
+		if err := r.dropRemovedDatabase(ctx, role, status, logger); err != nil {
+			logger.Error(err, "Failed to drop role from the database", "DATABASE", status.Name)
+			status.LastError = err.Error()
+			statuses = append(statuses, status)
+			errs = append(errs, fmt.Errorf("database %s: %w", status.Name, err))
+		}
+	}
+	role.Status.Databases = statuses
+	return errors.Join(errs...)
+}
+
+// dropRemovedDatabase revokes applied privileges from the role and drops it from the database,
+// which is no longer specified in the Role spec.
+func (r *RoleReconciler) dropRemovedDatabase(ctx context.Context, role *v1alpha1.Role, status v1alpha1.RoleDatabaseStatus, logger logr.Logger) error {
+	dbConfig := &v1alpha1.Database{}
+	if err := r.Get(ctx, types.NamespacedName{Name: status.Name}, dbConfig); err != nil {
+		// Database CR was deleted together with the reference, there is nothing to connect to.
+		return client.IgnoreNotFound(err)
+	}
+
+	db, err := r.Databases.Get(ctx, dbConfig, r.Client, logger)
+	if err != nil {
+		return errors.Join(ErrDatabaseConnect, err)
+	}
+	defer db.Close(ctx)
+
+	name := role.DatabaseRoleName()
+	if len(status.AppliedPrivileges) > 0 {
+		if err := db.RevokeRolePrivileges(ctx, name, status.AppliedPrivileges); err != nil {
+			return err
+		}
+	}
+	return database.WithPrivileges(db, status.AppliedPrivileges).DeleteRole(ctx, name)
+}
+
+// isRoleDatabaseReferenced reports whether the Database CR with provided name is specified in the Role spec.
+func isRoleDatabaseReferenced(role *v1alpha1.Role, name string) bool {
+	for _, dbRef := range role.Spec.Databases {
+		if dbRef.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// indexRoleDatabases returns names of the databases, that are referenced in the Role.
+func indexRoleDatabases(o client.Object) []string {
+	role := o.(*v1alpha1.Role)
+	databases := make([]string, 0, len(role.Spec.Databases))
+	for _, dbRef := range role.Spec.Databases {
+		databases = append(databases, dbRef.Name)
+	}
+	return databases
+}
+
+// indexRolePrivileges returns names of the Privileges, that are referenced in the Role.
+func indexRolePrivileges(o client.Object) []string {
+	role := o.(*v1alpha1.Role)
+	var privileges []string
+	for _, dbRef := range role.Spec.Databases {
+		for _, privilege := range dbRef.Privileges {
+			privileges = append(privileges, privilege.Name)
+		}
+	}
+	return privileges
+}
+
+func (r *RoleReconciler) rolesForIndex(field string) handler.MapFunc {
+	return func(ctx context.Context, o client.Object) []reconcile.Request {
+		roles := &v1alpha1.RoleList{}
+		if err := r.List(ctx, roles, client.MatchingFields{field: o.GetName()}); err != nil {
+			log.FromContext(ctx).Error(err, "unable to list roles", "FIELD", field, "VALUE", o.GetName())
+			return nil
+		}
+
+		requests := make([]reconcile.Request, 0, len(roles.Items))
+		for _, role := range roles.Items {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&role)})
+		}
+		return requests
+	}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *RoleReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	indexer := mgr.GetFieldIndexer()
+	if err := indexer.IndexField(context.Background(), &v1alpha1.Role{}, databasesField, indexRoleDatabases); err != nil {
+		return err
+	}
+	if err := indexer.IndexField(context.Background(), &v1alpha1.Role{}, privilegesField, indexRolePrivileges); err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.Role{}).
+		Watches(&v1alpha1.Database{}, handler.EnqueueRequestsFromMapFunc(r.rolesForIndex(databasesField)),
+			builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, databaseReadinessChanged()))).
+		Watches(&v1alpha1.Privileges{}, handler.EnqueueRequestsFromMapFunc(r.rolesForIndex(privilegesField))).
+		Complete(r)
+}
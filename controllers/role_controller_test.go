@@ -0,0 +1,194 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/alex123012/database-users-operator/api/v1alpha1"
+)
+
+var _ = Describe("RoleController", Ordered, func() {
+	Context("PostgreSQL group role", Ordered, func() {
+		var (
+			role       *v1alpha1.Role
+			secret     *v1.Secret
+			database   *v1alpha1.Database
+			privileges *v1alpha1.Privileges
+		)
+
+		BeforeAll(func() {
+			_, secret, database, privileges = bundle(namespace, v1alpha1.PostgreSQL)
+			database.Spec.PostgreSQL = defaultPostgresConfig()
+			role = &v1alpha1.Role{
+				ObjectMeta: metav1.ObjectMeta{Name: uniqueName("role", v1alpha1.PostgreSQL)},
+				Spec: v1alpha1.RoleSpec{
+					RoleName: "app_readonly",
+					Databases: []v1alpha1.RoleDatabaseRef{
+						{Name: database.GetName(), Privileges: []v1alpha1.Name{{Name: privileges.GetName()}}},
+					},
+				},
+			}
+
+			fakeDB.Conn.ResetDB()
+			createObjects(secret, database, privileges, role)
+		})
+
+		AfterAll(func() {
+			deleteObjects(secret, database, privileges)
+			fakeDB.Conn.ResetDB()
+		})
+
+		It("creates role and applies privileges", func() {
+			fetchedRole := &v1alpha1.Role{}
+			Eventually(func() bool {
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(role), fetchedRole)).To(Succeed())
+				return meta.IsStatusConditionTrue(fetchedRole.Status.Conditions, v1alpha1.ConditionReady)
+			}, userCreationTimeout, time.Second).Should(BeTrue())
+
+			Expect(fetchedRole.Status.Databases).To(HaveLen(1))
+			Expect(fetchedRole.Status.Databases[0].RoleCreated).To(BeTrue())
+			Expect(fetchedRole.Status.Databases[0].AppliedPrivileges).To(Equal(privileges.Privileges))
+
+			queries := fakeDB.Conn.Queries()
+			Expect(queries).To(HaveKey(`CREATE ROLE "app_readonly" NOLOGIN`))
+			Expect(queries).To(HaveKey(`GRANT MY PRIVILEGE TO "app_readonly"`))
+		})
+
+		It("drops role on deletion", func() {
+			fakeDB.Conn.ResetDB()
+			deleteObjects(role)
+
+			queries := fakeDB.Conn.Queries()
+			Expect(queries).To(HaveKey(`REVOKE MY PRIVILEGE FROM "app_readonly"`))
+			Expect(queries).To(HaveKey(`DROP ROLE "app_readonly"`))
+			Expect(queries[`DROP ROLE "app_readonly"`]).To(BeNumerically(">", queries[`REVOKE MY PRIVILEGE FROM "app_readonly"`]))
+		})
+	})
+
+	Context("PostgreSQL database removed from role spec", Ordered, func() {
+		var (
+			role       *v1alpha1.Role
+			secret     *v1.Secret
+			database   *v1alpha1.Database
+			privileges *v1alpha1.Privileges
+		)
+
+		BeforeAll(func() {
+			_, secret, database, privileges = bundle(namespace, v1alpha1.PostgreSQL)
+			database.Spec.PostgreSQL = defaultPostgresConfig()
+			role = &v1alpha1.Role{
+				ObjectMeta: metav1.ObjectMeta{Name: uniqueName("role-removed", v1alpha1.PostgreSQL)},
+				Spec: v1alpha1.RoleSpec{
+					RoleName: "app_reporting",
+					Databases: []v1alpha1.RoleDatabaseRef{
+						{Name: database.GetName(), Privileges: []v1alpha1.Name{{Name: privileges.GetName()}}},
+					},
+				},
+			}
+
+			fakeDB.Conn.ResetDB()
+			createObjects(secret, database, privileges, role)
+		})
+
+		AfterAll(func() {
+			deleteObjects(role, secret, database, privileges)
+			fakeDB.Conn.ResetDB()
+		})
+
+		It("drops role from the removed database", func() {
+			fetchedRole := &v1alpha1.Role{}
+			Eventually(func() []v1alpha1.RoleDatabaseStatus {
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(role), fetchedRole)).To(Succeed())
+				return fetchedRole.Status.Databases
+			}, userCreationTimeout, time.Second).Should(HaveLen(1))
+			Eventually(func() bool {
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(role), fetchedRole)).To(Succeed())
+				return fetchedRole.Status.Databases[0].RoleCreated
+			}, userCreationTimeout, time.Second).Should(BeTrue())
+
+			fakeDB.Conn.ResetDB()
+			fetchedRole.Spec.Databases = []v1alpha1.RoleDatabaseRef{}
+			Expect(k8sClient.Update(ctx, fetchedRole)).To(Succeed())
+
+			Eventually(func() []v1alpha1.RoleDatabaseStatus {
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(role), fetchedRole)).To(Succeed())
+				return fetchedRole.Status.Databases
+			}, userCreationTimeout, time.Second).Should(BeEmpty())
+
+			queries := fakeDB.Conn.Queries()
+			Expect(queries).To(HaveKey(`REVOKE MY PRIVILEGE FROM "app_reporting"`))
+			Expect(queries).To(HaveKey(`DROP ROLE "app_reporting"`))
+			Expect(queries[`DROP ROLE "app_reporting"`]).To(BeNumerically(">", queries[`REVOKE MY PRIVILEGE FROM "app_reporting"`]))
+		})
+	})
+
+	Context("PostgreSQL role with the name of existing user", Ordered, func() {
+		var (
+			role     *v1alpha1.Role
+			secret   *v1.Secret
+			database *v1alpha1.Database
+		)
+
+		BeforeAll(func() {
+			_, secret, database, _ = bundle(namespace, v1alpha1.PostgreSQL)
+			database.Spec.PostgreSQL = defaultPostgresConfig()
+			role = &v1alpha1.Role{
+				ObjectMeta: metav1.ObjectMeta{Name: uniqueName("role-login", v1alpha1.PostgreSQL)},
+				Spec: v1alpha1.RoleSpec{
+					RoleName:  "app_owner",
+					Databases: []v1alpha1.RoleDatabaseRef{{Name: database.GetName()}},
+				},
+			}
+
+			fakeDB.Conn.ResetDB()
+			fakeDB.Conn.SetError(&pgconn.PgError{Code: "42710"}, `CREATE ROLE "app_owner" NOLOGIN`)
+			fakeDB.Conn.SetResult([]bool{true}, "SELECT rolcanlogin FROM pg_roles WHERE rolname = $1", "app_owner")
+			createObjects(secret, database, role)
+		})
+
+		AfterAll(func() {
+			deleteObjects(secret, database)
+			fakeDB.Conn.ResetDB()
+		})
+
+		It("refuses to take over the user", func() {
+			fetchedRole := &v1alpha1.Role{}
+			Eventually(func() bool {
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(role), fetchedRole)).To(Succeed())
+				return meta.IsStatusConditionFalse(fetchedRole.Status.Conditions, v1alpha1.ConditionReady)
+			}, userCreationTimeout, time.Second).Should(BeTrue())
+
+			Expect(fetchedRole.Status.Databases).To(HaveLen(1))
+			Expect(fetchedRole.Status.Databases[0].RoleCreated).To(BeFalse())
+			Expect(fetchedRole.Status.Databases[0].LastError).To(ContainSubstring("can login"))
+		})
+
+		It("doesn't drop the user on deletion", func() {
+			deleteObjects(role)
+			Expect(fakeDB.Conn.Queries()).NotTo(HaveKey(`DROP ROLE "app_owner"`))
+		})
+	})
+})
@@ -20,6 +20,7 @@ import (
 	"context"
 	"path/filepath"
 	"testing"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -30,6 +31,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 
 	databaseusersoperatorcomv1alpha1 "github.com/alex123012/database-users-operator/api/v1alpha1"
 	"github.com/alex123012/database-users-operator/controllers"
@@ -82,16 +84,42 @@ var _ = BeforeSuite(func() {
 	//+kubebuilder:scaffold:scheme
 
 	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
-		Scheme:             scheme.Scheme,
-		MetricsBindAddress: "0",
+		Scheme:  scheme.Scheme,
+		Metrics: metricsserver.Options{BindAddress: "0"},
 	})
 	Expect(err).NotTo(HaveOccurred())
 
+	databases := database.NewCache(fakeDB.DatabaseCreatorFunc(), 0)
+	Expect(mgr.Add(databases)).To(Succeed())
+
 	Expect((&controllers.UserReconciler{
-		Client:          mgr.GetClient(),
-		Scheme:          mgr.GetScheme(),
-		DatabaseCreator: fakeDB.DatabaseCreatorFunc(),
-		Recorder:        mgr.GetEventRecorderFor("database-users-operator"),
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		Databases: databases,
+		Recorder:  mgr.GetEventRecorderFor("database-users-operator"),
+	}).SetupWithManager(mgr)).To(Succeed())
+
+	Expect((&controllers.NamespacedUserReconciler{
+		UserReconciler: controllers.UserReconciler{
+			Client:    mgr.GetClient(),
+			Scheme:    mgr.GetScheme(),
+			Databases: databases,
+			Recorder:  mgr.GetEventRecorderFor("database-users-operator"),
+		},
+	}).SetupWithManager(mgr)).To(Succeed())
+
+	Expect((&controllers.RoleReconciler{
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		Databases: databases,
+		Recorder:  mgr.GetEventRecorderFor("database-users-operator"),
+	}).SetupWithManager(mgr)).To(Succeed())
+
+	Expect((&controllers.DatabaseReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		Databases:     databases,
+		CheckInterval: 10 * time.Second,
 	}).SetupWithManager(mgr)).To(Succeed())
 
 	go func() {
@@ -19,10 +19,15 @@ package controllers
 import (
 	"context"
 	"errors"
+	"fmt"
+	"slices"
+	"time"
 
 	"github.com/go-logr/logr"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -30,9 +35,14 @@ import (
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/alex123012/database-users-operator/api/v1alpha1"
 	"github.com/alex123012/database-users-operator/pkg/database"
@@ -42,27 +52,58 @@ import (
 const (
 	userFinalizer = "user.databaseusersoperator.com/finalizer"
 	successMsg    = "Successfully created user in all specified databases"
+
+	reasonReconciled      = "Reconciled"
+	reasonReconcileFailed = "ReconcileFailed"
+
+	usernameSecretKey = "username"
+	passwordSecretKey = "password"
+
+	secretsField    = ".spec.databases.secrets"
+	databasesField  = ".spec.databases.name"
+	privilegesField = ".spec.databases.privileges.name"
 )
 
-var ErrDatabaseConnect = errors.New("can't connect to database")
+var (
+	ErrDatabaseConnect       = errors.New("can't connect to database")
+	ErrCreatedSecretRequired = errors.New("createdSecret is required to store generated password, when passwordSecret is not set")
+	ErrInvalidSecretRef      = errors.New("invalid secret reference")
+)
 
-type databaseCreator func(ctx context.Context, s v1alpha1.DatabaseSpec, client client.Client, logger logr.Logger) (database.Database, error)
+// userObject is common interface for User and NamespacedUser.
+type userObject interface {
+	client.Object
+	GetSpec() *v1alpha1.UserSpec
+	GetStatus() *v1alpha1.UserStatus
+}
 
 // UserReconciler reconciles a User object.
 type UserReconciler struct {
 	client.Client
-	Scheme          *runtime.Scheme
-	DatabaseCreator databaseCreator
-	Recorder        record.EventRecorder
+	Scheme    *runtime.Scheme
+	Databases *database.Cache
+	Recorder  record.EventRecorder
+
+	// namespaced is true, if reconciler processes NamespacedUser objects.
+	namespaced bool
+}
+
+// NamespacedUserReconciler reconciles a NamespacedUser object.
+type NamespacedUserReconciler struct {
+	UserReconciler
 }
 
 //+kubebuilder:rbac:groups=databaseusersoperator.com,resources=users,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=databaseusersoperator.com,resources=users/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=databaseusersoperator.com,resources=users/finalizers,verbs=update
+//+kubebuilder:rbac:groups=databaseusersoperator.com,resources=namespacedusers,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=databaseusersoperator.com,resources=namespacedusers/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=databaseusersoperator.com,resources=namespacedusers/finalizers,verbs=update
 // +kubebuilder:rbac:groups=databaseusersoperator.com,resources=databases,verbs=get;list;watch
 // +kubebuilder:rbac:groups=databaseusersoperator.com,resources=privileges,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -77,28 +118,33 @@ func (r *UserReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 		return ctrl.Result{}, err
 	}
 
+	oldStatus := user.GetStatus().DeepCopy()
 	deleting, err := r.reconcile(ctx, user, logger)
 	if err != nil {
+		reason := "ErrorCreatingUser"
+		if deleting {
+			reason = "ErrorDeletingUser"
+		}
+		r.addEvent(user, true, reason, err.Error())
+
 		if deleting {
-			r.addEvent(user, true, "ErrorCreatingUser", err.Error())
 			return ctrl.Result{}, err
 		}
-		r.addEvent(user, true, "ErrorCreatingUser", err.Error())
-		return ctrl.Result{}, r.setStatus(ctx, user, v1alpha1.UserStatus{
-			Summary: v1alpha1.StatusSummary{Ready: false, Message: err.Error()},
-		})
+		return ctrl.Result{}, r.setStatus(ctx, user, oldStatus, v1alpha1.StatusSummary{Ready: false, Message: err.Error()})
 	}
 
-	if !user.Status.Summary.Ready && !deleting {
+	if deleting {
+		return ctrl.Result{}, nil
+	}
+
+	if !user.GetStatus().Summary.Ready {
 		r.addEvent(user, false, "SuccessfullyCreatedUser", successMsg)
-		err = r.setStatus(ctx, user, v1alpha1.UserStatus{
-			Summary: v1alpha1.StatusSummary{Ready: true, Message: successMsg},
-		})
 	}
-	return ctrl.Result{}, err
+	result := ctrl.Result{RequeueAfter: nextRotation(user, time.Now())}
+	return result, r.setStatus(ctx, user, oldStatus, v1alpha1.StatusSummary{Ready: true, Message: successMsg})
 }
 
-func (r *UserReconciler) reconcile(ctx context.Context, user *v1alpha1.User, logger logr.Logger) (bool, error) {
+func (r *UserReconciler) reconcile(ctx context.Context, user userObject, logger logr.Logger) (bool, error) {
 	deleting := false
 	// Check if the resource is marked to be deleted, which is
 	// indicated by the deletion timestamp being set.
@@ -110,11 +156,8 @@ func (r *UserReconciler) reconcile(ctx context.Context, user *v1alpha1.User, log
 		}
 
 		// Process deletetion user logic
-		rec := r.databaseReconciler(user, true, logger)
-		for _, dbRef := range user.Spec.Databases {
-			if err := rec(ctx, dbRef); err != nil {
-				return deleting, err
-			}
+		if err := r.reconcileDatabases(ctx, user, true, logger); err != nil {
+			return deleting, err
 		}
 		logger.Info("Successfully deleted user from all specified databases")
 
@@ -124,6 +167,15 @@ func (r *UserReconciler) reconcile(ctx context.Context, user *v1alpha1.User, log
 		return deleting, r.Update(ctx, user)
 	}
 
+	if err := validateSecretRefs(user); err != nil {
+		return deleting, err
+	}
+
+	// Access policy is also checked by the webhook, but Database could be created or changed after the user.
+	if _, err := v1alpha1.ValidateDatabasesAccess(ctx, r.Client, user.GetNamespace(), *user.GetSpec()); err != nil {
+		return deleting, err
+	}
+
 	if !controllerutil.ContainsFinalizer(user, userFinalizer) {
 		// Add finalizer for this CR
 		logger.Info("Setting finalizer for resource")
@@ -134,11 +186,9 @@ func (r *UserReconciler) reconcile(ctx context.Context, user *v1alpha1.User, log
 	}
 
 	// Process reconcile user logic
-	rec := r.databaseReconciler(user, false, logger)
-	for _, dbRef := range user.Spec.Databases {
-		if err := rec(ctx, dbRef); err != nil {
-			return deleting, err
-		}
+	err := r.reconcileDatabases(ctx, user, false, logger)
+	if err := errors.Join(err, r.dropRemovedDatabases(ctx, user, logger)); err != nil {
+		return deleting, err
 	}
 
 	logger.Info(successMsg)
@@ -146,12 +196,30 @@ func (r *UserReconciler) reconcile(ctx context.Context, user *v1alpha1.User, log
 	return deleting, nil
 }
 
-func (r *UserReconciler) setStatus(ctx context.Context, user *v1alpha1.User, status v1alpha1.UserStatus) error {
-	user.Status = status
+// setStatus updates status of the User if it differs from the oldStatus.
+func (r *UserReconciler) setStatus(ctx context.Context, user userObject, oldStatus *v1alpha1.UserStatus, summary v1alpha1.StatusSummary) error {
+	user.GetStatus().Summary = summary
+
+	condition := metav1.Condition{
+		Type:               v1alpha1.ConditionReady,
+		Status:             metav1.ConditionTrue,
+		Reason:             reasonReconciled,
+		Message:            summary.Message,
+		ObservedGeneration: user.GetGeneration(),
+	}
+	if !summary.Ready {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = reasonReconcileFailed
+	}
+	meta.SetStatusCondition(&user.GetStatus().Conditions, condition)
+
+	if equality.Semantic.DeepEqual(oldStatus, user.GetStatus()) {
+		return nil
+	}
 	return r.Status().Update(ctx, user)
 }
 
-func (r *UserReconciler) addEvent(user *v1alpha1.User, warn bool, reason, message string) {
+func (r *UserReconciler) addEvent(user userObject, warn bool, reason, message string) {
 	eventType := v1.EventTypeNormal
 	if warn {
 		eventType = v1.EventTypeWarning
@@ -160,24 +228,63 @@ func (r *UserReconciler) addEvent(user *v1alpha1.User, warn bool, reason, messag
 	r.Recorder.Event(user, eventType, reason, message)
 }
 
-func (r *UserReconciler) databaseReconciler(user *v1alpha1.User, deleteRequest bool, logger logr.Logger) func(ctx context.Context, dbRef v1alpha1.DatabaseRef) error {
+// reconcileDatabases processes every database from the User spec, even if some of them fail,
+// and records result for each database in the User status.
+// Returned error joins errors from all failed databases.
+func (r *UserReconciler) reconcileDatabases(ctx context.Context, user userObject, deleteRequest bool, logger logr.Logger) error {
+	rec := r.databaseReconciler(user, deleteRequest, logger)
+
+	var errs []error
+	for _, dbRef := range databaseRefs(user) {
+		err := rec(ctx, dbRef)
+
+		status := databaseStatus(user, dbRef.Name)
+		status.ObservedGeneration = user.GetGeneration()
+		status.LastError = ""
+		if err != nil {
+			logger.Error(err, "Failed to reconcile user in the database", "DATABASE", dbRef.Name)
+			status.LastError = err.Error()
+			errs = append(errs, fmt.Errorf("database %s: %w", dbRef.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (r *UserReconciler) databaseReconciler(user userObject, deleteRequest bool, logger logr.Logger) func(ctx context.Context, dbRef v1alpha1.DatabaseRef) error {
 	return func(ctx context.Context, dbRef v1alpha1.DatabaseRef) error {
 		dbConfig, err := r.database(ctx, types.NamespacedName{Name: dbRef.Name}, logger)
 		if err != nil {
 			return err
 		}
 
-		privileges, err := r.privileges(ctx, dbRef.Privileges, logger)
+		if !deleteRequest {
+			if condition := meta.FindStatusCondition(dbConfig.Status.Conditions, v1alpha1.ConditionReady); condition != nil && condition.Status == metav1.ConditionFalse {
+				return fmt.Errorf("%w: %s", ErrDatabaseNotReady, condition.Message)
+			}
+
+			// Length is also checked by the webhook, but Database could be created after the user.
+			if err := v1alpha1.ValidateUsernameLength(dbConfig.Spec.Type, databaseUsername(user), dbRef); err != nil {
+				return err
+			}
+		}
+
+		privileges, err := referencedPrivileges(ctx, r.Client, dbRef.Privileges)
 		if err != nil {
 			return err
 		}
 
-		db, err := r.DatabaseCreator(ctx, dbConfig.Spec, r.Client, logger)
+		db, err := r.Databases.Get(ctx, dbConfig, r.Client, logger)
 		if err != nil {
 			return errors.Join(ErrDatabaseConnect, err)
 		}
 		defer db.Close(ctx)
 
+		hostnames := usersHostnames(dbConfig, dbRef)
+		if err := r.dropRemovedHostnames(ctx, db, user, dbConfig, dbRef, hostnames, logger); err != nil {
+			return err
+		}
+		db = database.WithUsersHostnames(db, hostnames)
+
 		f := r.databaseUserApply
 		if deleteRequest {
 			f = r.databaseUserDelete
@@ -186,8 +293,8 @@ func (r *UserReconciler) databaseReconciler(user *v1alpha1.User, deleteRequest b
 	}
 }
 
-func (r *UserReconciler) user(ctx context.Context, nn types.NamespacedName, logger logr.Logger) (*v1alpha1.User, error) {
-	user := &v1alpha1.User{}
+func (r *UserReconciler) user(ctx context.Context, nn types.NamespacedName, logger logr.Logger) (userObject, error) {
+	user := r.newUser()
 	if err := r.Get(ctx, nn, user); err != nil {
 		if apierrors.IsNotFound(err) {
 			logger.Info("User resource not found. Ignoring since object must be deleted.")
@@ -200,6 +307,20 @@ func (r *UserReconciler) user(ctx context.Context, nn types.NamespacedName, logg
 	return user, nil
 }
 
+func (r *UserReconciler) newUser() userObject {
+	if r.namespaced {
+		return &v1alpha1.NamespacedUser{}
+	}
+	return &v1alpha1.User{}
+}
+
+func (r *UserReconciler) newUserList() client.ObjectList {
+	if r.namespaced {
+		return &v1alpha1.NamespacedUserList{}
+	}
+	return &v1alpha1.UserList{}
+}
+
 func (r *UserReconciler) database(ctx context.Context, nn types.NamespacedName, _ logr.Logger) (*v1alpha1.Database, error) {
 	db := &v1alpha1.Database{}
 	if err := r.Get(ctx, nn, db); err != nil {
@@ -208,11 +329,12 @@ func (r *UserReconciler) database(ctx context.Context, nn types.NamespacedName,
 	return db, nil
 }
 
-func (r *UserReconciler) privileges(ctx context.Context, nns []v1alpha1.Name, _ logr.Logger) ([]v1alpha1.PrivilegeSpec, error) {
+// referencedPrivileges returns privileges from all referenced Privileges CRs.
+func referencedPrivileges(ctx context.Context, c client.Reader, nns []v1alpha1.Name) ([]v1alpha1.PrivilegeSpec, error) {
 	var privileges []v1alpha1.PrivilegeSpec
 	for _, nn := range nns {
 		p := &v1alpha1.Privileges{}
-		if err := r.Get(ctx, nn.ToNamespacedName(), p); err != nil {
+		if err := c.Get(ctx, nn.ToNamespacedName(), p); err != nil {
 			return nil, err
 		}
 		privileges = append(privileges, p.Privileges...)
@@ -220,41 +342,278 @@ func (r *UserReconciler) privileges(ctx context.Context, nns []v1alpha1.Name, _
 	return privileges, nil
 }
 
-func (r *UserReconciler) databaseUserApply(ctx context.Context, db database.Database, user *v1alpha1.User, dbRef v1alpha1.DatabaseRef, privileges []v1alpha1.PrivilegeSpec, logger logr.Logger) error {
-	if err := r.createUserInDatabase(ctx, db, user, dbRef, logger); err != nil {
+func (r *UserReconciler) databaseUserApply(ctx context.Context, db database.Database, user userObject, dbRef v1alpha1.DatabaseRef, privileges []v1alpha1.PrivilegeSpec, logger logr.Logger) error {
+	status := databaseStatus(user, dbRef.Name)
+	status.PrivilegesApplied = false
+	password, passwordSet, err := r.createUserInDatabase(ctx, db, user, dbRef, logger)
+	if err != nil {
 		return err
 	}
-	return db.ApplyPrivileges(ctx, user.Name, privileges)
+	status.UserCreated = true
+
+	// Some options (e.g. MySQL authentication plugin) are applied only together with the password,
+	// so they are applied again, when the password is set. Applied options are compared with the ones
+	// from the previous reconcile, so options removed from spec are still reset.
+	if !equality.Semantic.DeepEqual(status.AppliedOptions, dbRef.Options) || (passwordSet && dbRef.Options != nil) {
+		options, applied := v1alpha1.UserOptions{}, v1alpha1.UserOptions{}
+		if dbRef.Options != nil {
+			options = *dbRef.Options
+		}
+		if status.AppliedOptions != nil {
+			applied = *status.AppliedOptions
+		}
+
+		logger.Info("Applying user options", "DATABASE", dbRef.Name)
+		for _, username := range databaseUsernames(user, dbRef) {
+			// Password of standby user is not stored, so options requiring it are applied after rotation.
+			userPassword := ""
+			if username == status.ActiveUsername || status.ActiveUsername == "" {
+				userPassword = password
+			}
+			if err := db.AlterUser(ctx, username, userPassword, options, applied); err != nil {
+				return err
+			}
+		}
+		status.AppliedOptions = dbRef.Options
+	}
+
+	revoked := missingPrivileges(status.AppliedPrivileges, privileges)
+	if len(revoked) > 0 {
+		logger.Info("Revoking privileges removed from spec", "DATABASE", dbRef.Name)
+	}
+
+	revokedRoles := missingRoles(status.AppliedRoles, dbRef.Roles)
+	if len(revokedRoles) > 0 {
+		logger.Info("Revoking roles removed from spec", "DATABASE", dbRef.Name)
+	}
+
+	for _, username := range databaseUsernames(user, dbRef) {
+		if len(revoked) > 0 {
+			if err := db.RevokePrivileges(ctx, username, revoked); err != nil {
+				return err
+			}
+		}
+
+		if err := db.ApplyPrivileges(ctx, username, privileges); err != nil {
+			return err
+		}
+
+		if len(revokedRoles) > 0 {
+			if err := db.RevokeRoles(ctx, username, revokedRoles); err != nil {
+				return err
+			}
+		}
+
+		if len(dbRef.Roles) > 0 {
+			if err := db.GrantRoles(ctx, username, dbRef.Roles); err != nil {
+				return err
+			}
+		}
+	}
+	status.AppliedPrivileges = privileges
+	status.AppliedRoles = dbRef.Roles
+	status.PrivilegesApplied = true
+	return nil
 }
 
-func (r *UserReconciler) databaseUserDelete(ctx context.Context, db database.Database, user *v1alpha1.User, dbRef v1alpha1.DatabaseRef, privileges []v1alpha1.PrivilegeSpec, _ logr.Logger) error {
+func (r *UserReconciler) databaseUserDelete(ctx context.Context, db database.Database, user userObject, dbRef v1alpha1.DatabaseRef, privileges []v1alpha1.PrivilegeSpec, _ logr.Logger) error {
 	defer func() {
 		_ = r.Delete(ctx, newSecret(dbRef.CreatedSecret.ToNamespacedName(), nil))
 	}()
 
-	if err := db.RevokePrivileges(ctx, user.Name, privileges); err != nil {
+	// Privileges could be already removed from spec, but still be applied to the user.
+	status := databaseStatus(user, dbRef.Name)
+	privileges = append(privileges, missingPrivileges(status.AppliedPrivileges, privileges)...)
+	roles := append(slices.Clip(dbRef.Roles), missingRoles(status.AppliedRoles, dbRef.Roles)...)
+	db = database.WithPrivileges(db, privileges)
+
+	for _, username := range databaseUsernames(user, dbRef) {
+		if err := db.RevokePrivileges(ctx, username, privileges); err != nil {
+			return err
+		}
+
+		if len(roles) > 0 {
+			if err := db.RevokeRoles(ctx, username, roles); err != nil {
+				return err
+			}
+		}
+
+		if err := db.DeleteUser(ctx, username); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropRemovedDatabases drops the user from databases, that were removed from the User spec since the last reconcile,
+// and removes their statuses. Statuses of databases, where the user wasn't dropped, are kept to retry on the next reconcile.
+func (r *UserReconciler) dropRemovedDatabases(ctx context.Context, user userObject, logger logr.Logger) error {
+	var errs []error
+	statuses := make([]v1alpha1.DatabaseStatus, 0, len(user.GetStatus().Databases))
+	for _, status := range user.GetStatus().Databases {
+		if isDatabaseReferenced(user, status.Name) {
+			statuses = append(statuses, status)
+			continue
+		}
+		if !status.UserCreated {
+			continue
+		}
+
+		logger.Info("Dropping user from database removed from spec", "DATABASE", status.Name)
+		if err := r.dropRemovedDatabase(ctx, user, status, logger); err != nil {
+			logger.Error(err, "Failed to drop user from the database", "DATABASE", status.Name)
+			status.LastError = err.Error()
+			statuses = append(statuses, status)
+			errs = append(errs, fmt.Errorf("database %s: %w", status.Name, err))
+		}
+	}
+	user.GetStatus().Databases = statuses
+	return errors.Join(errs...)
+}
+
+// dropRemovedDatabase revokes applied privileges and roles and drops users of the User from the database,
+// which is no longer specified in the User spec. Everything is taken from the database status, as DatabaseRef is gone.
+func (r *UserReconciler) dropRemovedDatabase(ctx context.Context, user userObject, status v1alpha1.DatabaseStatus, logger logr.Logger) error {
+	dbConfig, err := r.database(ctx, types.NamespacedName{Name: status.Name}, logger)
+	if apierrors.IsNotFound(err) {
+		// Database CR was deleted together with the reference, there is nothing to connect to.
+		return nil
+	}
+	if err != nil {
 		return err
 	}
 
-	return db.DeleteUser(ctx, user.Name)
+	db, err := r.Databases.Get(ctx, dbConfig, r.Client, logger)
+	if err != nil {
+		return errors.Join(ErrDatabaseConnect, err)
+	}
+	defer db.Close(ctx)
+	db = database.WithUsersHostnames(db, usersHostnames(dbConfig, v1alpha1.DatabaseRef{UsersHostnames: status.AppliedUsersHostnames}))
+	db = database.WithPrivileges(db, status.AppliedPrivileges)
+
+	usernames := []string{databaseUsername(user)}
+	if status.ActiveUsername != "" {
+		usernames = append(usernames, databaseUsername(user)+v1alpha1.DualCredentialsSuffix)
+	}
+	for _, username := range usernames {
+		if len(status.AppliedPrivileges) > 0 {
+			if err := db.RevokePrivileges(ctx, username, status.AppliedPrivileges); err != nil {
+				return err
+			}
+		}
+
+		if len(status.AppliedRoles) > 0 {
+			if err := db.RevokeRoles(ctx, username, status.AppliedRoles); err != nil {
+				return err
+			}
+		}
+
+		if err := db.DeleteUser(ctx, username); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropRemovedHostnames drops MySQL and MariaDB users for host patterns, that were removed since the last reconcile.
+// Users for new host patterns are created by databaseUserApply.
+func (r *UserReconciler) dropRemovedHostnames(ctx context.Context, db database.Database, user userObject, dbConfig *v1alpha1.Database,
+	dbRef v1alpha1.DatabaseRef, hostnames []string, logger logr.Logger) error {
+	status := databaseStatus(user, dbRef.Name)
+	applied := status.AppliedUsersHostnames
+	if len(applied) == 0 && dbConfig.Spec.Type == v1alpha1.MySQL && dbConfig.Spec.MySQL != nil {
+		// Host patterns weren't recorded for users created before UsersHostnames were introduced,
+		// such users exist only for the legacy host pattern.
+		applied = []string{dbConfig.Spec.MySQL.LegacyHostname()}
+	}
+
+	var removed []string
+	for _, hostname := range applied {
+		if !slices.Contains(hostnames, hostname) {
+			removed = append(removed, hostname)
+		}
+	}
+
+	if len(removed) > 0 {
+		logger.Info("Dropping users for hosts removed from spec", "DATABASE", dbRef.Name, "HOSTS", removed)
+		removedDB := database.WithUsersHostnames(db, removed)
+		for _, username := range databaseUsernames(user, dbRef) {
+			if err := removedDB.DeleteUser(ctx, username); err != nil {
+				return err
+			}
+		}
+	}
+	status.AppliedUsersHostnames = hostnames
+	return nil
 }
 
-func (r *UserReconciler) createUserInDatabase(ctx context.Context, db database.Database, user *v1alpha1.User, dbRef v1alpha1.DatabaseRef, _ logr.Logger) error {
+// createUserInDatabase creates or updates the user and its standby user in the database
+// and returns password of the active user and whether it was set during this reconcile.
+func (r *UserReconciler) createUserInDatabase(ctx context.Context, db database.Database, user userObject, dbRef v1alpha1.DatabaseRef, logger logr.Logger) (string, bool, error) {
+	generatePassword := !isSecretSet(dbRef.PasswordSecret)
+	status := databaseStatus(user, dbRef.Name)
+	username, rotate := databaseUsername(user), false
 	userPassword, err := r.userPassword(ctx, dbRef.PasswordSecret)
+	if generatePassword {
+		rotate = rotationRequired(dbRef.Rotation, status, time.Now())
+		username, userPassword, err = r.generatedCredentials(ctx, user, dbRef, rotate)
+	}
 	if err != nil {
-		return err
+		return "", false, err
 	}
 
-	secretData, err := db.CreateUser(ctx, user.Name, userPassword)
-	if err != nil || len(secretData) < 1 {
-		return err
+	if rotate {
+		logger.Info("Rotating generated password", "DATABASE", dbRef.Name, "USERNAME", username)
 	}
 
-	secretNN := types.NamespacedName{Namespace: dbRef.CreatedSecret.Namespace, Name: dbRef.CreatedSecret.Name}
-	if _, err := utils.Secret(ctx, secretNN, r.Client); err == nil {
+	secretData, passwordSet, err := r.createOrUpdateUser(ctx, db, username, status, userPassword)
+	if err != nil {
+		return "", false, err
+	}
+
+	if err := r.createStandbyUsers(ctx, db, user, dbRef, username); err != nil {
+		return "", false, err
+	}
+
+	if generatePassword {
+		if secretData == nil {
+			secretData = make(map[string]string)
+		}
+		secretData[usernameSecretKey] = username
+		secretData[passwordSecretKey] = userPassword
+	}
+
+	if err := r.ensureCreatedSecret(ctx, user, dbRef, secretData, generatePassword); err != nil {
+		return "", false, err
+	}
+
+	if dbRef.Rotation != nil && generatePassword && (rotate || status.LastRotationTime == nil) {
+		now := metav1.Now()
+		status.LastRotationTime = &now
+	}
+	status.ActiveUsername = ""
+	if isDualCredentials(dbRef) {
+		status.ActiveUsername = username
+	}
+	return userPassword, passwordSet, nil
+}
+
+// ensureCreatedSecret creates CreatedSecret with provided data or adds missing data to the existing one.
+// If overwriteCredentials is true - username and password in the existing secret will be replaced.
+func (r *UserReconciler) ensureCreatedSecret(ctx context.Context, user userObject, dbRef v1alpha1.DatabaseRef, secretData map[string]string, overwriteCredentials bool) error {
+	if len(secretData) < 1 {
 		return nil
 	}
 
+	secretNN := types.NamespacedName{Namespace: dbRef.CreatedSecret.Namespace, Name: dbRef.CreatedSecret.Name}
+	if secret, err := utils.Secret(ctx, secretNN, r.Client); err == nil {
+		var overwrite []string
+		if overwriteCredentials {
+			overwrite = []string{usernameSecretKey, passwordSecretKey}
+		}
+		return r.updateSecretData(ctx, secret, secretData, overwrite...)
+	}
+
 	secret := newSecret(secretNN, secretData)
 
 	// TODO (alex123012): doesn't work GC (WHY???)
@@ -265,8 +624,128 @@ func (r *UserReconciler) createUserInDatabase(ctx context.Context, db database.D
 	return r.Create(ctx, secret)
 }
 
+// createStandbyUsers creates users from dual credentials pair, that are not active now.
+// Their passwords are random and not stored anywhere until they become active.
+func (r *UserReconciler) createStandbyUsers(ctx context.Context, db database.Database, user userObject, dbRef v1alpha1.DatabaseRef, activeUsername string) error {
+	for _, username := range databaseUsernames(user, dbRef) {
+		if username == activeUsername {
+			continue
+		}
+
+		exists, err := db.UserExists(ctx, username)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+
+		password, err := utils.GeneratePassword(utils.DefaultPasswordLength, utils.DefaultPasswordCharset)
+		if err != nil {
+			return err
+		}
+		if _, err := db.CreateUser(ctx, username, password); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createOrUpdateUser creates user in the database, or sets new password for existing user,
+// if it differs from the password applied during previous reconcile. It returns whether the password was set.
+func (r *UserReconciler) createOrUpdateUser(ctx context.Context, db database.Database, username string, status *v1alpha1.DatabaseStatus, password string) (map[string]string, bool, error) {
+	exists, err := db.UserExists(ctx, username)
+	if err != nil {
+		return nil, false, err
+	}
+
+	passwordMatches := utils.PasswordMatchesHash(password, status.PasswordHash)
+
+	var secretData map[string]string
+	switch {
+	case !exists:
+		secretData, err = db.CreateUser(ctx, username, password)
+		// New user has default options, so options are applied to it again,
+		// even if they were applied to the previous one.
+		status.AppliedOptions = nil
+	case !passwordMatches:
+		err = db.SetPassword(ctx, username, password)
+	default:
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	if !passwordMatches {
+		status.PasswordHash, err = utils.HashPassword(password)
+	}
+	return secretData, true, err
+}
+
+// generatedCredentials returns username and password stored in CreatedSecret.
+// If rotate is true or there is no password yet - new password is generated
+// and, in dual credentials mode, the other user from the pair is returned.
+func (r *UserReconciler) generatedCredentials(ctx context.Context, user userObject, dbRef v1alpha1.DatabaseRef, rotate bool) (string, string, error) {
+	if dbRef.CreatedSecret.Name == "" || dbRef.CreatedSecret.Namespace == "" {
+		return "", "", ErrCreatedSecretRequired
+	}
+
+	data, err := utils.DecodeSecretData(ctx, dbRef.CreatedSecret.ToNamespacedName(), r.Client)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return "", "", err
+	}
+
+	usernames := databaseUsernames(user, dbRef)
+	username := usernames[0]
+	if current := data[usernameSecretKey]; slices.Contains(usernames, current) {
+		username = current
+	}
+
+	if password, ok := data[passwordSecretKey]; ok && password != "" && !rotate {
+		return username, password, nil
+	}
+
+	if rotate && len(usernames) > 1 {
+		username = usernames[(slices.Index(usernames, username)+1)%len(usernames)]
+	}
+
+	length, charset := utils.DefaultPasswordLength, utils.DefaultPasswordCharset
+	if cfg := dbRef.PasswordGenerator; cfg != nil {
+		if cfg.Length > 0 {
+			length = cfg.Length
+		}
+		if cfg.Charset != "" {
+			charset = cfg.Charset
+		}
+	}
+	password, err := utils.GeneratePassword(length, charset)
+	return username, password, err
+}
+
+// updateSecretData adds data to the existing secret, if it doesn't contain such keys.
+// Keys from overwrite list are replaced, if their values differ.
+func (r *UserReconciler) updateSecretData(ctx context.Context, secret *v1.Secret, data map[string]string, overwrite ...string) error {
+	updated := false
+	for key, value := range data {
+		if current, ok := secret.Data[key]; ok && (string(current) == value || !slices.Contains(overwrite, key)) {
+			continue
+		}
+		if secret.Data == nil {
+			secret.Data = make(map[string][]byte)
+		}
+		secret.Data[key] = []byte(value)
+		updated = true
+	}
+
+	if !updated {
+		return nil
+	}
+	return r.Update(ctx, secret)
+}
+
 func (r *UserReconciler) userPassword(ctx context.Context, secretCfg v1alpha1.Secret) (string, error) {
-	if secretCfg.Key == "" || secretCfg.Secret.Name == "" || secretCfg.Secret.Namespace == "" {
+	if !isSecretSet(secretCfg) {
 		return "", nil
 	}
 
@@ -290,16 +769,281 @@ func (r *UserReconciler) userPassword(ctx context.Context, secretCfg v1alpha1.Se
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *UserReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	indexer := mgr.GetFieldIndexer()
+	if err := indexer.IndexField(context.Background(), r.newUser(), secretsField, indexSecrets); err != nil {
+		return err
+	}
+	if err := indexer.IndexField(context.Background(), r.newUser(), databasesField, indexDatabases); err != nil {
+		return err
+	}
+	if err := indexer.IndexField(context.Background(), r.newUser(), privilegesField, indexPrivileges); err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&v1alpha1.User{}).
+		For(r.newUser()).
 		Owns(&v1.Secret{}).
-		// Owns(&v1alpha1.Privileges{}).
-		// Owns(&v1alpha1.Database{}).
-		// Owns(&v1alpha1.User{}).
-		// Watches(&source.Kind{Type: &v1alpha1.User{}}, &handler.EnqueueRequestForObject{}).
+		Watches(&v1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.usersForSecret)).
+		Watches(&v1alpha1.Database{}, handler.EnqueueRequestsFromMapFunc(r.usersForIndex(databasesField)),
+			builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, databaseReadinessChanged()))).
+		Watches(&v1alpha1.Privileges{}, handler.EnqueueRequestsFromMapFunc(r.usersForIndex(privilegesField))).
 		Complete(r)
 }
 
+// SetupWithManager sets up the controller with the Manager.
+func (r *NamespacedUserReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.namespaced = true
+	return r.UserReconciler.SetupWithManager(mgr)
+}
+
+// databaseUsername returns name of the user in the databases, see v1alpha1.DatabaseUsername.
+func databaseUsername(user userObject) string {
+	return v1alpha1.DatabaseUsername(user.GetNamespace(), user.GetName())
+}
+
+// databaseRefs returns databases from the user spec.
+// For NamespacedUser omitted namespaces of secrets are set to the user namespace.
+func databaseRefs(user userObject) []v1alpha1.DatabaseRef {
+	namespace := user.GetNamespace()
+	databases := user.GetSpec().Databases
+	if namespace == "" {
+		return databases
+	}
+
+	refs := make([]v1alpha1.DatabaseRef, 0, len(databases))
+	for _, dbRef := range databases {
+		if dbRef.PasswordSecret.Secret.Namespace == "" {
+			dbRef.PasswordSecret.Secret.Namespace = namespace
+		}
+		if dbRef.CreatedSecret.Namespace == "" {
+			dbRef.CreatedSecret.Namespace = namespace
+		}
+		refs = append(refs, dbRef)
+	}
+	return refs
+}
+
+// validateSecretRefs checks, that secrets referenced by NamespacedUser are located in its namespace
+// and that secrets referenced by User have namespace set.
+func validateSecretRefs(user userObject) error {
+	namespace := user.GetNamespace()
+	for _, dbRef := range databaseRefs(user) {
+		for _, secret := range []v1alpha1.NamespacedName{dbRef.PasswordSecret.Secret, dbRef.CreatedSecret} {
+			switch {
+			case secret.Name == "":
+			case secret.Namespace == "":
+				return fmt.Errorf("%w: namespace of secret %s is required", ErrInvalidSecretRef, secret.Name)
+			case namespace != "" && secret.Namespace != namespace:
+				return fmt.Errorf("%w: secret %s must be in the %s namespace", ErrInvalidSecretRef, secret.ToNamespacedName(), namespace)
+			}
+		}
+	}
+	return nil
+}
+
+func isSecretSet(secretCfg v1alpha1.Secret) bool {
+	return secretCfg.Key != "" && secretCfg.Secret.Name != "" && secretCfg.Secret.Namespace != ""
+}
+
+// databaseUsernames returns names of the users, that are created in the database for the User.
+// In dual credentials mode there are two users: "<name>" and "<name>_alt".
+func databaseUsernames(user userObject, dbRef v1alpha1.DatabaseRef) []string {
+	if isDualCredentials(dbRef) {
+		return []string{databaseUsername(user), databaseUsername(user) + v1alpha1.DualCredentialsSuffix}
+	}
+	return []string{databaseUsername(user)}
+}
+
+func isDualCredentials(dbRef v1alpha1.DatabaseRef) bool {
+	return dbRef.Rotation != nil && dbRef.Rotation.DualCredentials && !isSecretSet(dbRef.PasswordSecret)
+}
+
+// rotationRequired reports whether generated password should be rotated according to the policy.
+// Password that was never rotated is considered as just created.
+func rotationRequired(rotation *v1alpha1.RotationPolicy, status *v1alpha1.DatabaseStatus, now time.Time) bool {
+	if rotation == nil || rotation.Interval.Duration <= 0 || status.LastRotationTime == nil {
+		return false
+	}
+	return !now.Before(status.LastRotationTime.Add(rotation.Interval.Duration))
+}
+
+// nextRotation returns duration until the nearest password rotation for the User
+// or zero if rotation is not configured.
+func nextRotation(user userObject, now time.Time) time.Duration {
+	var next time.Duration
+	for _, dbRef := range databaseRefs(user) {
+		if dbRef.Rotation == nil || dbRef.Rotation.Interval.Duration <= 0 || isSecretSet(dbRef.PasswordSecret) {
+			continue
+		}
+
+		status := databaseStatus(user, dbRef.Name)
+		if status.LastRotationTime == nil {
+			continue
+		}
+
+		after := status.LastRotationTime.Add(dbRef.Rotation.Interval.Duration).Sub(now)
+		if after <= 0 {
+			after = time.Second
+		}
+		if next == 0 || after < next {
+			next = after
+		}
+	}
+	return next
+}
+
+// databaseStatus returns status of the user for the Database CR with provided name.
+// If there is no such status - it will be added to the User status.
+func databaseStatus(user userObject, name string) *v1alpha1.DatabaseStatus {
+	for i := range user.GetStatus().Databases {
+		if user.GetStatus().Databases[i].Name == name {
+			return &user.GetStatus().Databases[i]
+		}
+	}
+	user.GetStatus().Databases = append(user.GetStatus().Databases, v1alpha1.DatabaseStatus{Name: name})
+	return &user.GetStatus().Databases[len(user.GetStatus().Databases)-1]
+}
+
+// isDatabaseReferenced reports whether the Database CR with provided name is specified in the User spec.
+func isDatabaseReferenced(user userObject, name string) bool {
+	for _, dbRef := range user.GetSpec().Databases {
+		if dbRef.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// missingPrivileges returns privileges from applied list, that are not present in desired list.
+func missingPrivileges(applied, desired []v1alpha1.PrivilegeSpec) []v1alpha1.PrivilegeSpec {
+	var missing []v1alpha1.PrivilegeSpec
+	for _, privilege := range applied {
+		equal := func(p v1alpha1.PrivilegeSpec) bool { return equality.Semantic.DeepEqual(p, privilege) }
+		if !slices.ContainsFunc(desired, equal) {
+			missing = append(missing, privilege)
+		}
+	}
+	return missing
+}
+
+// missingRoles returns roles from applied list, that are not present in desired list,
+// so roles with changed options are revoked and granted again.
+func missingRoles(applied, desired []v1alpha1.RoleGrant) []v1alpha1.RoleGrant {
+	var missing []v1alpha1.RoleGrant
+	for _, role := range applied {
+		equal := func(r v1alpha1.RoleGrant) bool { return equality.Semantic.DeepEqual(r, role) }
+		if !slices.ContainsFunc(desired, equal) {
+			missing = append(missing, role)
+		}
+	}
+	return missing
+}
+
+// usersHostnames returns host patterns of the MySQL or MariaDB user from the DatabaseRef or from the Database config,
+// nil is returned for other database types.
+func usersHostnames(dbConfig *v1alpha1.Database, dbRef v1alpha1.DatabaseRef) []string {
+	var hostnames []string
+	switch {
+	case dbConfig.Spec.Type == v1alpha1.MySQL && dbConfig.Spec.MySQL != nil:
+		hostnames = dbConfig.Spec.MySQL.Hostnames()
+	case dbConfig.Spec.Type == v1alpha1.MariaDB && dbConfig.Spec.MariaDB != nil:
+		hostnames = dbConfig.Spec.MariaDB.Hostnames()
+	default:
+		return nil
+	}
+
+	if len(dbRef.UsersHostnames) > 0 {
+		return dbRef.UsersHostnames
+	}
+	return hostnames
+}
+
+// indexSecrets returns secrets with users passwords, that are referenced in the User.
+func indexSecrets(o client.Object) []string {
+	user := o.(userObject)
+	var secrets []string
+	for _, dbRef := range databaseRefs(user) {
+		if isSecretSet(dbRef.PasswordSecret) {
+			secrets = append(secrets, dbRef.PasswordSecret.Secret.ToNamespacedName().String())
+		}
+		if dbRef.CreatedSecret.Name != "" {
+			secrets = append(secrets, dbRef.CreatedSecret.ToNamespacedName().String())
+		}
+	}
+	return secrets
+}
+
+// databaseReadinessChanged filters Database updates, that change its Ready condition status,
+// so periodic connectivity checks don't requeue users.
+func databaseReadinessChanged() predicate.Funcs {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldDB, okOld := e.ObjectOld.(*v1alpha1.Database)
+			newDB, okNew := e.ObjectNew.(*v1alpha1.Database)
+			if !okOld || !okNew {
+				return false
+			}
+			return meta.IsStatusConditionTrue(oldDB.Status.Conditions, v1alpha1.ConditionReady) !=
+				meta.IsStatusConditionTrue(newDB.Status.Conditions, v1alpha1.ConditionReady)
+		},
+	}
+}
+
+// indexDatabases returns names of Database CRs, that are referenced in the User.
+func indexDatabases(o client.Object) []string {
+	user := o.(userObject)
+	databases := make([]string, 0, len(databaseRefs(user)))
+	for _, dbRef := range databaseRefs(user) {
+		databases = append(databases, dbRef.Name)
+	}
+	return databases
+}
+
+// indexPrivileges returns names of Privileges CRs, that are referenced in the User.
+func indexPrivileges(o client.Object) []string {
+	user := o.(userObject)
+	var privileges []string
+	for _, dbRef := range databaseRefs(user) {
+		for _, privilege := range dbRef.Privileges {
+			privileges = append(privileges, privilege.Name)
+		}
+	}
+	return privileges
+}
+
+// usersForSecret returns reconcile requests for users, that reference the secret.
+func (r *UserReconciler) usersForSecret(ctx context.Context, secret client.Object) []reconcile.Request {
+	return r.usersRequests(ctx, secretsField, client.ObjectKeyFromObject(secret).String())
+}
+
+// usersForIndex returns function, that maps cluster scoped object to reconcile requests
+// for users, that reference it by name in the indexed field.
+func (r *UserReconciler) usersForIndex(field string) handler.MapFunc {
+	return func(ctx context.Context, o client.Object) []reconcile.Request {
+		return r.usersRequests(ctx, field, o.GetName())
+	}
+}
+
+func (r *UserReconciler) usersRequests(ctx context.Context, field, value string) []reconcile.Request {
+	users := r.newUserList()
+	if err := r.List(ctx, users, client.MatchingFields{field: value}); err != nil {
+		log.FromContext(ctx).Error(err, "unable to list users", "FIELD", field, "VALUE", value)
+		return nil
+	}
+
+	items, err := meta.ExtractList(users)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "unable to extract users from list")
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(items))
+	for _, item := range items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(item.(client.Object))})
+	}
+	return requests
+}
+
 func newSecret(nn types.NamespacedName, stringData map[string]string) *v1.Secret {
 	return &v1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
@@ -17,12 +17,19 @@ limitations under the License.
 package controllers_test
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/alex123012/database-users-operator/api/v1alpha1"
 	"github.com/alex123012/database-users-operator/pkg/utils"
@@ -106,20 +113,697 @@ var _ = Describe("UserController", Ordered, func() {
 		connStrings := []string{defaultMysqlConnString}
 
 		queries := []string{
-			`CREATE USER ?@? IDENTIFIED BY ?user-mysql*mysupersecretpass`,
-			`GRANT ? ON ?.? TO ?MY PRIVILEGEDBCUSTOM ONuser-mysql`,
-			`GRANT ? ON ?.* TO ?MY PRIVILEGEDBuser-mysql`,
-			`GRANT ? TO ?MY PRIVILEGEuser-mysql`,
+			// User could be created for the legacy host pattern before host patterns were recorded in the status.
+			`DROP USER IF EXISTS ?@?user-mysql*`,
+			`CREATE USER ?@? IDENTIFIED BY ?user-mysql%mysupersecretpass`,
+			"GRANT MY PRIVILEGE ON `DB`.`CUSTOM ON` TO ?@?user-mysql%",
+			"GRANT MY PRIVILEGE ON `DB`.* TO ?@?user-mysql%",
+			`GRANT ? TO ?@?MY PRIVILEGEuser-mysql%`,
 		}
 
 		removeQueries := []string{
-			`REVOKE ? ON ?.? FROM ?MY PRIVILEGEDBCUSTOM ONuser-mysql`,
-			`REVOKE ? ON ?.* FROM ?MY PRIVILEGEDBuser-mysql`,
-			`REVOKE ? FROM ?MY PRIVILEGEuser-mysql`,
-			`DROP USER ?@?user-mysql*`,
+			"REVOKE MY PRIVILEGE ON `DB`.`CUSTOM ON` FROM ?@?user-mysql%",
+			"REVOKE MY PRIVILEGE ON `DB`.* FROM ?@?user-mysql%",
+			`REVOKE ? FROM ?@?MY PRIVILEGEuser-mysql%`,
+			`DROP USER IF EXISTS ?@?user-mysql%`,
 		}
 
 		tester := newTestDatabase(v1alpha1.MySQL, cfg, fakeDB, connStrings, queries, removeQueries, false)
 		tester.run()
 	})
+
+	Context("MariaDB", Ordered, func() {
+		cfg := defaultMariaDBConfig()
+
+		connStrings := []string{defaultMariaDBConnString}
+
+		queries := []string{
+			`CREATE OR REPLACE USER ?@? IDENTIFIED BY ?user-mariadb%mysupersecretpass`,
+			"GRANT MY PRIVILEGE ON `DB`.`CUSTOM ON` TO ?@?user-mariadb%",
+			"GRANT MY PRIVILEGE ON `DB`.* TO ?@?user-mariadb%",
+			`GRANT ? TO ?@?MY PRIVILEGEuser-mariadb%`,
+		}
+
+		removeQueries := []string{
+			"REVOKE MY PRIVILEGE ON `DB`.`CUSTOM ON` FROM ?@?user-mariadb%",
+			"REVOKE MY PRIVILEGE ON `DB`.* FROM ?@?user-mariadb%",
+			`REVOKE ? FROM ?@?MY PRIVILEGEuser-mariadb%`,
+			`DROP USER IF EXISTS ?@?user-mariadb%`,
+		}
+
+		tester := newTestDatabase(v1alpha1.MariaDB, cfg, fakeDB, connStrings, queries, removeQueries, false)
+		tester.run()
+	})
+
+	Context("MSSQL", Ordered, func() {
+		cfg := defaultMSSQLConfig()
+
+		connStrings := []string{defaultMSSQLConnString}
+
+		queries := []string{
+			`IF SUSER_ID(N'user-mssql') IS NULL CREATE LOGIN [user-mssql] WITH PASSWORD = N'mysupersecretpass' ELSE ALTER LOGIN [user-mssql] WITH PASSWORD = N'mysupersecretpass'`,
+			`EXEC [DB].sys.sp_executesql @p1IF DATABASE_PRINCIPAL_ID(N'user-mssql') IS NULL CREATE USER [user-mssql] FOR LOGIN [user-mssql]`,
+			`EXEC [DB].sys.sp_executesql @p1GRANT MY PRIVILEGE ON OBJECT::[dbo].[CUSTOM ON] TO [user-mssql]`,
+			`EXEC [DB].sys.sp_executesql @p1GRANT MY PRIVILEGE TO [user-mssql]`,
+			`ALTER SERVER ROLE [MY PRIVILEGE] ADD MEMBER [user-mssql]`,
+		}
+
+		removeQueries := []string{
+			`EXEC [DB].sys.sp_executesql @p1IF DATABASE_PRINCIPAL_ID(N'user-mssql') IS NOT NULL REVOKE MY PRIVILEGE ON OBJECT::[dbo].[CUSTOM ON] FROM [user-mssql]`,
+			`EXEC [DB].sys.sp_executesql @p1IF DATABASE_PRINCIPAL_ID(N'user-mssql') IS NOT NULL REVOKE MY PRIVILEGE FROM [user-mssql]`,
+			`ALTER SERVER ROLE [MY PRIVILEGE] DROP MEMBER [user-mssql]`,
+			`EXEC [DB].sys.sp_executesql @p1DROP USER IF EXISTS [user-mssql]`,
+			`IF SUSER_ID(N'user-mssql') IS NOT NULL DROP LOGIN [user-mssql]`,
+		}
+
+		tester := newTestDatabase(v1alpha1.MSSQL, cfg, fakeDB, connStrings, queries, removeQueries, false)
+		tester.run()
+	})
+
+	Context("ClickHouse", Ordered, func() {
+		cfg := defaultClickHouseConfig()
+
+		connStrings := []string{defaultClickHouseConnString}
+
+		queries := []string{
+			`CREATE USER IF NOT EXISTS ? IDENTIFIED WITH sha256_password BY ?user-clickhousemysupersecretpass`,
+			"GRANT MY PRIVILEGE ON `DB`.`CUSTOM ON` TO ?user-clickhouse",
+			"GRANT MY PRIVILEGE ON `DB`.* TO ?user-clickhouse",
+			`GRANT ? TO ?MY PRIVILEGEuser-clickhouse`,
+		}
+
+		removeQueries := []string{
+			"REVOKE MY PRIVILEGE ON `DB`.`CUSTOM ON` FROM ?user-clickhouse",
+			"REVOKE MY PRIVILEGE ON `DB`.* FROM ?user-clickhouse",
+			`REVOKE ? FROM ?MY PRIVILEGEuser-clickhouse`,
+			`DROP USER IF EXISTS ?user-clickhouse`,
+		}
+
+		tester := newTestDatabase(v1alpha1.ClickHouse, cfg, fakeDB, connStrings, queries, removeQueries, false)
+		tester.run()
+	})
+
+	Context("MongoDB", Ordered, func() {
+		cfg := defaultMongoDBConfig()
+
+		connStrings := []string{defaultMongoDBConnString}
+
+		queries := []string{
+			`admin: {"createUser":"user-mongodb","pwd":"mysupersecretpass","roles":[]}`,
+			`admin: {"grantRolesToUser":"user-mongodb","roles":[{"role":"MY PRIVILEGE","db":"DB"},{"role":"MY PRIVILEGE","db":"admin"}]}`,
+		}
+
+		removeQueries := []string{
+			`admin: {"revokeRolesFromUser":"user-mongodb","roles":[{"role":"MY PRIVILEGE","db":"DB"},{"role":"MY PRIVILEGE","db":"admin"}]}`,
+			`admin: {"dropUser":"user-mongodb"}`,
+		}
+
+		tester := newTestDatabase(v1alpha1.MongoDB, cfg, fakeDB, connStrings, queries, removeQueries, false)
+		tester.run()
+	})
+
+	Context("Redis", Ordered, func() {
+		cfg := defaultRedisConfig()
+
+		connStrings := []string{defaultRedisConnString}
+
+		queries := []string{
+			`ACL SETUSER user-redis on resetpass >mysupersecretpass`,
+			`ACL SETUSER user-redis -@all resetkeys resetchannels +@read -keys ~app:* +@write`,
+		}
+
+		removeQueries := []string{
+			`ACL SETUSER user-redis -@read -@write resetkeys`,
+			`ACL DELUSER user-redis`,
+		}
+
+		tester := newTestDatabase(v1alpha1.Redis, cfg, fakeDB, connStrings, queries, removeQueries, false)
+		tester.run()
+	})
+
+	Context("PostgreSQL with generated password", Ordered, func() {
+		var (
+			user       *v1alpha1.User
+			secret     *v1.Secret
+			database   *v1alpha1.Database
+			privileges *v1alpha1.Privileges
+		)
+
+		BeforeAll(func() {
+			user, secret, database, privileges = bundle(namespace, v1alpha1.PostgreSQL)
+			database.Spec.PostgreSQL = defaultPostgresConfig()
+			user.Spec.Databases[0].PasswordSecret = v1alpha1.Secret{}
+			user.Spec.Databases[0].PasswordGenerator = &v1alpha1.PasswordGenerator{Length: 16, Charset: "abc"}
+
+			fakeDB.Conn.ResetDB()
+			createObjects(secret, database, privileges, user)
+			waitForUsersReadiness(user)
+		})
+
+		AfterAll(func() {
+			deleteObjects(user, secret, database, privileges)
+			fakeDB.Conn.ResetDB()
+		})
+
+		It("stores generated password in created secret", func() {
+			data, err := utils.DecodeSecretData(ctx, user.Spec.Databases[0].CreatedSecret.ToNamespacedName(), k8sClient)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data).To(HaveKeyWithValue("username", user.GetName()))
+			Expect(data["password"]).To(MatchRegexp("^[abc]{16}$"))
+
+			Expect(fakeDB.Conn.Queries()).To(HaveKey(fmt.Sprintf(`CREATE USER "user-postgresql" WITH PASSWORD '%s'`, data["password"])))
+		})
+	})
+
+	Context("PostgreSQL password rotation", Ordered, func() {
+		var (
+			user       *v1alpha1.User
+			secret     *v1.Secret
+			database   *v1alpha1.Database
+			privileges *v1alpha1.Privileges
+		)
+
+		BeforeAll(func() {
+			user, secret, database, privileges = bundle(namespace, v1alpha1.PostgreSQL)
+			database.Spec.PostgreSQL = defaultPostgresConfig()
+
+			fakeDB.Conn.ResetDB()
+			createObjects(secret, database, privileges, user)
+			waitForUsersReadiness(user)
+		})
+
+		AfterAll(func() {
+			deleteObjects(user, secret, database, privileges)
+			fakeDB.Conn.ResetDB()
+		})
+
+		It("stores password hash in status", func() {
+			fetchedUser := &v1alpha1.User{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: user.GetName()}, fetchedUser)).To(Succeed())
+			Expect(fetchedUser.Status.Databases).To(HaveLen(1))
+			Expect(utils.PasswordMatchesHash("mysupersecretpass", fetchedUser.Status.Databases[0].PasswordHash)).To(BeTrue())
+		})
+
+		It("sets new password when secret changes", func() {
+			fakeDB.Conn.ResetDB()
+			fakeDB.Conn.SetResult([]bool{true}, "SELECT true FROM pg_roles WHERE rolname = $1", user.GetName())
+
+			fetchedSecret := &v1.Secret{}
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(secret), fetchedSecret)).To(Succeed())
+			fetchedSecret.Data["pass"] = []byte("mynewsecretpass")
+			Expect(k8sClient.Update(ctx, fetchedSecret)).To(Succeed())
+
+			Eventually(func() map[string]int {
+				return fakeDB.Conn.Queries()
+			}, userCreationTimeout, time.Second).Should(HaveKey(`ALTER USER "user-postgresql" WITH PASSWORD 'mynewsecretpass'`))
+			Expect(fakeDB.Conn.Queries()).NotTo(HaveKey(`CREATE USER "user-postgresql" WITH PASSWORD 'mynewsecretpass'`))
+		})
+	})
+
+	Context("PostgreSQL scheduled rotation with dual credentials", Ordered, func() {
+		var (
+			user       *v1alpha1.User
+			secret     *v1.Secret
+			database   *v1alpha1.Database
+			privileges *v1alpha1.Privileges
+		)
+
+		BeforeAll(func() {
+			user, secret, database, privileges = bundle(namespace, v1alpha1.PostgreSQL)
+			database.Spec.PostgreSQL = defaultPostgresConfig()
+			user.Spec.Databases[0].PasswordSecret = v1alpha1.Secret{}
+			user.Spec.Databases[0].Rotation = &v1alpha1.RotationPolicy{
+				Interval:        metav1.Duration{Duration: 3 * time.Second},
+				DualCredentials: true,
+			}
+
+			fakeDB.Conn.ResetDB()
+			createObjects(secret, database, privileges, user)
+			waitForUsersReadiness(user)
+		})
+
+		AfterAll(func() {
+			deleteObjects(user, secret, database, privileges)
+			fakeDB.Conn.ResetDB()
+		})
+
+		It("creates both users and records rotation time", func() {
+			fetchedUser := &v1alpha1.User{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: user.GetName()}, fetchedUser)).To(Succeed())
+			Expect(fetchedUser.Status.Databases).To(HaveLen(1))
+			Expect(fetchedUser.Status.Databases[0].LastRotationTime).NotTo(BeNil())
+			Expect(fetchedUser.Status.Databases[0].ActiveUsername).To(Equal(user.GetName()))
+
+			Expect(fakeDB.Conn.Queries()).To(HaveKey(`GRANT MY PRIVILEGE TO "user-postgresql_alt"`))
+		})
+
+		It("switches created secret to the other user on rotation", func() {
+			secretNN := user.Spec.Databases[0].CreatedSecret.ToNamespacedName()
+			data, err := utils.DecodeSecretData(ctx, secretNN, k8sClient)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data).To(HaveKeyWithValue("username", user.GetName()))
+
+			Eventually(func() map[string]string {
+				data, _ := utils.DecodeSecretData(ctx, secretNN, k8sClient)
+				return data
+			}, userCreationTimeout, time.Second).Should(HaveKeyWithValue("username", user.GetName()+"_alt"))
+		})
+
+		It("rejects rotation with password secret", func() {
+			invalidUser := user.DeepCopy()
+			invalidUser.ObjectMeta = metav1.ObjectMeta{Name: user.GetName() + "-invalid"}
+			invalidUser.Spec.Databases[0].PasswordSecret = v1alpha1.Secret{
+				Secret: v1alpha1.NamespacedName{Name: secret.GetName(), Namespace: secret.GetNamespace()},
+				Key:    "pass",
+			}
+
+			err := k8sClient.Create(ctx, invalidUser)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		})
+	})
+
+	Context("PostgreSQL with unavailable database", Ordered, func() {
+		var (
+			user       *v1alpha1.User
+			secret     *v1.Secret
+			database   *v1alpha1.Database
+			privileges *v1alpha1.Privileges
+		)
+
+		BeforeAll(func() {
+			user, secret, database, privileges = bundle(namespace, v1alpha1.PostgreSQL)
+			database.Spec.PostgreSQL = defaultPostgresConfig()
+
+			missing := *user.Spec.Databases[0].DeepCopy()
+			missing.Name = "missing-database"
+			missing.CreatedSecret.Name = "missing-created-secret"
+			user.Spec.Databases = append([]v1alpha1.DatabaseRef{missing}, user.Spec.Databases...)
+
+			fakeDB.Conn.ResetDB()
+			createObjects(secret, database, privileges, user)
+		})
+
+		AfterAll(func() {
+			// User can't be deleted from the missing database, so remove it from spec first.
+			Eventually(func() error {
+				fetchedUser := &v1alpha1.User{}
+				if err := k8sClient.Get(ctx, types.NamespacedName{Name: user.GetName()}, fetchedUser); err != nil {
+					return err
+				}
+				fetchedUser.Spec.Databases = fetchedUser.Spec.Databases[1:]
+				return k8sClient.Update(ctx, fetchedUser)
+			}, userCreationTimeout, time.Second).Should(Succeed())
+			waitForUsersReadiness(user)
+
+			deleteObjects(user, secret, database, privileges)
+			fakeDB.Conn.ResetDB()
+		})
+
+		It("reports status for every database", func() {
+			fetchedUser := &v1alpha1.User{}
+			Eventually(func() []v1alpha1.DatabaseStatus {
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: user.GetName()}, fetchedUser)).To(Succeed())
+				return fetchedUser.Status.Databases
+			}, userCreationTimeout, time.Second).Should(HaveLen(2))
+
+			Expect(fetchedUser.Status.Databases[0].Name).To(Equal("missing-database"))
+			Expect(fetchedUser.Status.Databases[0].UserCreated).To(BeFalse())
+			Expect(fetchedUser.Status.Databases[0].LastError).NotTo(BeEmpty())
+
+			Expect(fetchedUser.Status.Databases[1].Name).To(Equal(database.GetName()))
+			Expect(fetchedUser.Status.Databases[1].UserCreated).To(BeTrue())
+			Expect(fetchedUser.Status.Databases[1].PrivilegesApplied).To(BeTrue())
+			Expect(fetchedUser.Status.Databases[1].LastError).To(BeEmpty())
+
+			Expect(meta.IsStatusConditionFalse(fetchedUser.Status.Conditions, v1alpha1.ConditionReady)).To(BeTrue())
+			Expect(fakeDB.Conn.Queries()).To(HaveKey(`CREATE USER "user-postgresql" WITH PASSWORD 'mysupersecretpass'`))
+		})
+	})
+
+	Context("PostgreSQL with access policy", Ordered, func() {
+		var (
+			user       *v1alpha1.User
+			secret     *v1.Secret
+			database   *v1alpha1.Database
+			privileges *v1alpha1.Privileges
+		)
+
+		BeforeAll(func() {
+			user, secret, database, privileges = bundle(namespace, v1alpha1.PostgreSQL)
+			database.Spec.PostgreSQL = defaultPostgresConfig()
+			database.Spec.AccessPolicy = &v1alpha1.AccessPolicy{
+				AllowedPrivileges: []v1alpha1.Name{{Name: "readonly"}},
+			}
+
+			fakeDB.Conn.ResetDB()
+			createObjects(secret, database, privileges, user)
+		})
+
+		AfterAll(func() {
+			deleteObjects(user, secret, database, privileges)
+			fakeDB.Conn.ResetDB()
+		})
+
+		It("rejects not allowed privileges", func() {
+			fetchedUser := &v1alpha1.User{}
+			Eventually(func() string {
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: user.GetName()}, fetchedUser)).To(Succeed())
+				return fetchedUser.Status.Summary.Message
+			}, userCreationTimeout, time.Second).Should(ContainSubstring("access to the database is denied"))
+
+			Expect(fakeDB.Conn.Queries()).NotTo(HaveKey(`CREATE USER "user-postgresql" WITH PASSWORD 'mysupersecretpass'`))
+		})
+
+		It("creates user when privileges are allowed", func() {
+			fetchedDatabase := &v1alpha1.Database{}
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(database), fetchedDatabase)).To(Succeed())
+			fetchedDatabase.Spec.AccessPolicy.AllowedPrivileges = append(fetchedDatabase.Spec.AccessPolicy.AllowedPrivileges, v1alpha1.Name{Name: privileges.GetName()})
+			Expect(k8sClient.Update(ctx, fetchedDatabase)).To(Succeed())
+
+			waitForUsersReadiness(user)
+		})
+
+		It("rejects not allowed roles", func() {
+			fetchedUser := &v1alpha1.User{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: user.GetName()}, fetchedUser)).To(Succeed())
+			fetchedUser.Spec.Databases[0].Roles = []v1alpha1.RoleGrant{{Name: "postgres"}}
+			Expect(k8sClient.Update(ctx, fetchedUser)).To(Succeed())
+
+			Eventually(func() string {
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: user.GetName()}, fetchedUser)).To(Succeed())
+				return fetchedUser.Status.Summary.Message
+			}, userCreationTimeout, time.Second).Should(ContainSubstring("role postgres is not allowed"))
+
+			Expect(fakeDB.Conn.Queries()).NotTo(HaveKey(`GRANT "postgres" TO "user-postgresql"`))
+		})
+
+		It("rejects not allowed role attributes", func() {
+			fetchedUser := &v1alpha1.User{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: user.GetName()}, fetchedUser)).To(Succeed())
+			fetchedUser.Spec.Databases[0].Roles = nil
+			fetchedUser.Spec.Databases[0].Options = &v1alpha1.UserOptions{
+				PostgreSQL: &v1alpha1.PostgreSQLUserOptions{Replication: true},
+			}
+			Expect(k8sClient.Update(ctx, fetchedUser)).To(Succeed())
+
+			Eventually(func() string {
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: user.GetName()}, fetchedUser)).To(Succeed())
+				return fetchedUser.Status.Summary.Message
+			}, userCreationTimeout, time.Second).Should(ContainSubstring("PostgreSQL role attribute REPLICATION is not allowed"))
+		})
+	})
+
+	Context("PostgreSQL privileges drift", Ordered, func() {
+		var (
+			user       *v1alpha1.User
+			secret     *v1.Secret
+			database   *v1alpha1.Database
+			privileges *v1alpha1.Privileges
+			reduced    *v1alpha1.Privileges
+		)
+
+		BeforeAll(func() {
+			user, secret, database, privileges = bundle(namespace, v1alpha1.PostgreSQL)
+			database.Spec.PostgreSQL = defaultPostgresConfig()
+			reduced = &v1alpha1.Privileges{
+				ObjectMeta: metav1.ObjectMeta{Name: uniqueName("privileges-reduced", v1alpha1.PostgreSQL)},
+				Privileges: []v1alpha1.PrivilegeSpec{{Privilege: "MY PRIVILEGE"}},
+			}
+
+			fakeDB.Conn.ResetDB()
+			createObjects(secret, database, privileges, reduced, user)
+			waitForUsersReadiness(user)
+		})
+
+		AfterAll(func() {
+			deleteObjects(user, secret, database, privileges, reduced)
+			fakeDB.Conn.ResetDB()
+		})
+
+		It("records applied privileges in status", func() {
+			fetchedUser := &v1alpha1.User{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: user.GetName()}, fetchedUser)).To(Succeed())
+			Expect(fetchedUser.Status.Databases).To(HaveLen(1))
+			Expect(fetchedUser.Status.Databases[0].Name).To(Equal(database.GetName()))
+			Expect(fetchedUser.Status.Databases[0].AppliedPrivileges).To(Equal(privileges.Privileges))
+		})
+
+		It("revokes privileges removed from spec", func() {
+			fetchedUser := &v1alpha1.User{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: user.GetName()}, fetchedUser)).To(Succeed())
+
+			fakeDB.Conn.ResetDB()
+			fetchedUser.Spec.Databases[0].Privileges = []v1alpha1.Name{{Name: reduced.GetName()}}
+			Expect(k8sClient.Update(ctx, fetchedUser)).To(Succeed())
+
+			Eventually(func() []v1alpha1.PrivilegeSpec {
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: user.GetName()}, fetchedUser)).To(Succeed())
+				return fetchedUser.Status.Databases[0].AppliedPrivileges
+			}, userCreationTimeout, time.Second).Should(Equal(reduced.Privileges))
+
+			queries := fakeDB.Conn.Queries()
+			Expect(queries).To(HaveKey(`REVOKE MY PRIVILEGE ON "CUSTOM ON" FROM "user-postgresql"`))
+			Expect(queries).To(HaveKey(`REVOKE MY PRIVILEGE ON DATABASE "DB" FROM "user-postgresql"`))
+			Expect(queries).To(HaveKey(`GRANT MY PRIVILEGE TO "user-postgresql"`))
+			Expect(queries).NotTo(HaveKey(`REVOKE MY PRIVILEGE FROM "user-postgresql"`))
+		})
+
+		It("applies privileges when referenced Privileges CR changes", func() {
+			fetchedPrivileges := &v1alpha1.Privileges{}
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(reduced), fetchedPrivileges)).To(Succeed())
+
+			fakeDB.Conn.ResetDB()
+			fetchedPrivileges.Privileges = append(fetchedPrivileges.Privileges, v1alpha1.PrivilegeSpec{Privilege: "OTHER PRIVILEGE"})
+			Expect(k8sClient.Update(ctx, fetchedPrivileges)).To(Succeed())
+
+			Eventually(func() map[string]int {
+				return fakeDB.Conn.Queries()
+			}, userCreationTimeout, time.Second).Should(HaveKey(`GRANT OTHER PRIVILEGE TO "user-postgresql"`))
+		})
+	})
+
+	Context("PostgreSQL role membership", Ordered, func() {
+		var (
+			user       *v1alpha1.User
+			secret     *v1.Secret
+			database   *v1alpha1.Database
+			privileges *v1alpha1.Privileges
+		)
+
+		BeforeAll(func() {
+			user, secret, database, privileges = bundle(namespace, v1alpha1.PostgreSQL)
+			database.Spec.PostgreSQL = defaultPostgresConfig()
+			user.Spec.Databases[0].Roles = []v1alpha1.RoleGrant{
+				{Name: "readers"},
+				{Name: "writers", AdminOption: true},
+			}
+
+			fakeDB.Conn.ResetDB()
+			createObjects(secret, database, privileges, user)
+			waitForUsersReadiness(user)
+		})
+
+		AfterAll(func() {
+			deleteObjects(user, secret, database, privileges)
+			fakeDB.Conn.ResetDB()
+		})
+
+		It("grants roles to the user", func() {
+			queries := fakeDB.Conn.Queries()
+			Expect(queries).To(HaveKey(`GRANT "readers" TO "user-postgresql"`))
+			Expect(queries).To(HaveKey(`GRANT "writers" TO "user-postgresql" WITH ADMIN OPTION`))
+
+			fetchedUser := &v1alpha1.User{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: user.GetName()}, fetchedUser)).To(Succeed())
+			Expect(fetchedUser.Status.Databases[0].AppliedRoles).To(Equal(user.Spec.Databases[0].Roles))
+		})
+
+		It("revokes roles removed from spec", func() {
+			fetchedUser := &v1alpha1.User{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: user.GetName()}, fetchedUser)).To(Succeed())
+
+			fakeDB.Conn.ResetDB()
+			fetchedUser.Spec.Databases[0].Roles = []v1alpha1.RoleGrant{{Name: "writers", AdminOption: true}}
+			Expect(k8sClient.Update(ctx, fetchedUser)).To(Succeed())
+
+			Eventually(func() []v1alpha1.RoleGrant {
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: user.GetName()}, fetchedUser)).To(Succeed())
+				return fetchedUser.Status.Databases[0].AppliedRoles
+			}, userCreationTimeout, time.Second).Should(HaveLen(1))
+
+			queries := fakeDB.Conn.Queries()
+			Expect(queries).To(HaveKey(`REVOKE "readers" FROM "user-postgresql"`))
+			Expect(queries).NotTo(HaveKey(`REVOKE "writers" FROM "user-postgresql"`))
+		})
+	})
+
+	Context("PostgreSQL database removed from spec", Ordered, func() {
+		var (
+			user       *v1alpha1.User
+			secret     *v1.Secret
+			database   *v1alpha1.Database
+			privileges *v1alpha1.Privileges
+		)
+
+		BeforeAll(func() {
+			user, secret, database, privileges = bundle(namespace, v1alpha1.PostgreSQL)
+			database.Spec.PostgreSQL = defaultPostgresConfig()
+			user.Spec.Databases[0].Roles = []v1alpha1.RoleGrant{{Name: "readers"}}
+
+			fakeDB.Conn.ResetDB()
+			createObjects(secret, database, privileges, user)
+			waitForUsersReadiness(user)
+		})
+
+		AfterAll(func() {
+			deleteObjects(user, secret, database, privileges)
+			fakeDB.Conn.ResetDB()
+		})
+
+		It("drops the user from the removed database", func() {
+			fetchedUser := &v1alpha1.User{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: user.GetName()}, fetchedUser)).To(Succeed())
+
+			fakeDB.Conn.ResetDB()
+			fetchedUser.Spec.Databases = []v1alpha1.DatabaseRef{}
+			Expect(k8sClient.Update(ctx, fetchedUser)).To(Succeed())
+
+			Eventually(func() []v1alpha1.DatabaseStatus {
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: user.GetName()}, fetchedUser)).To(Succeed())
+				return fetchedUser.Status.Databases
+			}, userCreationTimeout, time.Second).Should(BeEmpty())
+
+			checkQueries(fakeDB, []string{
+				`REVOKE MY PRIVILEGE ON "CUSTOM ON" FROM "user-postgresql"`,
+				`REVOKE MY PRIVILEGE ON DATABASE "DB" FROM "user-postgresql"`,
+				`REVOKE MY PRIVILEGE FROM "user-postgresql"`,
+				`REVOKE "readers" FROM "user-postgresql"`,
+				`DROP USER "user-postgresql"`,
+			})
+		})
+	})
+
+	Context("PostgreSQL user failing to be deleted", Ordered, func() {
+		var (
+			user       *v1alpha1.User
+			secret     *v1.Secret
+			database   *v1alpha1.Database
+			privileges *v1alpha1.Privileges
+		)
+
+		BeforeAll(func() {
+			user, secret, database, privileges = bundle(namespace, v1alpha1.PostgreSQL)
+			database.Spec.PostgreSQL = defaultPostgresConfig()
+
+			fakeDB.Conn.ResetDB()
+			createObjects(secret, database, privileges, user)
+			waitForUsersReadiness(user)
+		})
+
+		AfterAll(func() {
+			deleteObjects(secret, database, privileges)
+			fakeDB.Conn.ResetDB()
+		})
+
+		It("reports deletion error with its own reason", func() {
+			fakeDB.Conn.SetError(errors.New("role is in use"), `DROP USER "user-postgresql"`)
+			Expect(k8sClient.Delete(ctx, user)).To(Succeed())
+
+			Eventually(func() []string {
+				events := &v1.EventList{}
+				Expect(k8sClient.List(ctx, events, &client.ListOptions{Namespace: namespace})).To(Succeed())
+				var reasons []string
+				for _, event := range events.Items {
+					if event.InvolvedObject.Name == user.GetName() && strings.Contains(event.Message, "role is in use") {
+						reasons = append(reasons, event.Reason)
+					}
+				}
+				return reasons
+			}, userCreationTimeout, time.Second).Should(ContainElement("ErrorDeletingUser"))
+
+			fakeDB.Conn.SetError(nil, `DROP USER "user-postgresql"`)
+			Eventually(objectNotFound, userCreationTimeout).WithArguments(user).Should(BeTrue())
+		})
+	})
+
+	Context("PostgreSQL user options", Ordered, func() {
+		var (
+			user       *v1alpha1.User
+			secret     *v1.Secret
+			database   *v1alpha1.Database
+			privileges *v1alpha1.Privileges
+		)
+
+		BeforeAll(func() {
+			user, secret, database, privileges = bundle(namespace, v1alpha1.PostgreSQL)
+			database.Spec.PostgreSQL = defaultPostgresConfig()
+			user.Spec.Databases[0].Options = &v1alpha1.UserOptions{
+				PostgreSQL: &v1alpha1.PostgreSQLUserOptions{
+					CreateDB:   true,
+					Parameters: map[string]string{"statement_timeout": "30s"},
+				},
+			}
+
+			fakeDB.Conn.ResetDB()
+			createObjects(secret, database, privileges, user)
+			waitForUsersReadiness(user)
+		})
+
+		AfterAll(func() {
+			deleteObjects(user, secret, database, privileges)
+			fakeDB.Conn.ResetDB()
+		})
+
+		It("applies options on creation", func() {
+			queries := fakeDB.Conn.Queries()
+			Expect(queries).To(HaveKey(`ALTER ROLE "user-postgresql" WITH LOGIN CREATEDB`))
+			Expect(queries).To(HaveKey(`ALTER ROLE "user-postgresql" SET statement_timeout TO '30s'`))
+			Expect(queries[`CREATE USER "user-postgresql" WITH PASSWORD 'mysupersecretpass'`]).To(BeNumerically("<", queries[`ALTER ROLE "user-postgresql" RESET ALL`]))
+
+			fetchedUser := &v1alpha1.User{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: user.GetName()}, fetchedUser)).To(Succeed())
+			Expect(fetchedUser.Status.Databases[0].AppliedOptions).To(Equal(user.Spec.Databases[0].Options))
+		})
+
+		It("updates options, when they change", func() {
+			fetchedUser := &v1alpha1.User{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: user.GetName()}, fetchedUser)).To(Succeed())
+
+			fakeDB.Conn.ResetDB()
+			fakeDB.Conn.SetResult([]bool{true}, "SELECT true FROM pg_roles WHERE rolname = $1", user.GetName())
+			limit := int32(5)
+			fetchedUser.Spec.Databases[0].Options = &v1alpha1.UserOptions{
+				PostgreSQL: &v1alpha1.PostgreSQLUserOptions{ConnectionLimit: &limit},
+			}
+			Expect(k8sClient.Update(ctx, fetchedUser)).To(Succeed())
+
+			Eventually(func() map[string]int {
+				return fakeDB.Conn.Queries()
+			}, userCreationTimeout, time.Second).Should(HaveKey(`ALTER ROLE "user-postgresql" WITH LOGIN NOCREATEDB CONNECTION LIMIT 5`))
+			Expect(fakeDB.Conn.Queries()).To(HaveKey(`ALTER ROLE "user-postgresql" RESET ALL`))
+			Expect(fakeDB.Conn.Queries()).NotTo(HaveKey(`ALTER ROLE "user-postgresql" SET statement_timeout TO '30s'`))
+		})
+
+		It("resets removed options together with password change", func() {
+			fetchedUser := &v1alpha1.User{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: user.GetName()}, fetchedUser)).To(Succeed())
+
+			fakeDB.Conn.ResetDB()
+			fakeDB.Conn.SetResult([]bool{true}, "SELECT true FROM pg_roles WHERE rolname = $1", user.GetName())
+			fetchedUser.Spec.Databases[0].Options = &v1alpha1.UserOptions{PostgreSQL: &v1alpha1.PostgreSQLUserOptions{}}
+			Expect(k8sClient.Update(ctx, fetchedUser)).To(Succeed())
+
+			fetchedSecret := &v1.Secret{}
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(secret), fetchedSecret)).To(Succeed())
+			fetchedSecret.Data["pass"] = []byte("mynewsecretpass")
+			Expect(k8sClient.Update(ctx, fetchedSecret)).To(Succeed())
+
+			Eventually(func() map[string]int {
+				return fakeDB.Conn.Queries()
+			}, userCreationTimeout, time.Second).Should(And(
+				HaveKey(`ALTER USER "user-postgresql" WITH PASSWORD 'mynewsecretpass'`),
+				HaveKey(`ALTER ROLE "user-postgresql" WITH LOGIN CONNECTION LIMIT -1`),
+			))
+		})
+	})
 })
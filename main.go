@@ -19,6 +19,7 @@ package main
 import (
 	"flag"
 	"os"
+	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -27,6 +28,8 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	databaseusersoperatorcomv1alpha1 "github.com/alex123012/database-users-operator/api/v1alpha1"
 	"github.com/alex123012/database-users-operator/controllers"
@@ -51,8 +54,14 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var databaseCheckInterval time.Duration
+	var databaseIdleTimeout time.Duration
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
+	flag.DurationVar(&databaseCheckInterval, "database-check-interval", controllers.DefaultDatabaseCheckInterval,
+		"Interval between connectivity checks of the databases.")
+	flag.DurationVar(&databaseIdleTimeout, "database-idle-timeout", database.DefaultIdleTimeout,
+		"Time after which unused connection to the database is closed.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
@@ -66,8 +75,8 @@ func main() {
 
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
-		MetricsBindAddress:     metricsAddr,
-		Port:                   9443,
+		Metrics:                metricsserver.Options{BindAddress: metricsAddr},
+		WebhookServer:          webhook.NewServer(webhook.Options{Port: 9443}),
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "54429e69.databaseusersoperator.com",
@@ -88,15 +97,62 @@ func main() {
 		os.Exit(1)
 	}
 
+	databases := database.NewCache(database.NewDatabase, databaseIdleTimeout)
+	if err := mgr.Add(databases); err != nil {
+		setupLog.Error(err, "unable to set up databases connections cache")
+		os.Exit(1)
+	}
+
 	if err = (&controllers.UserReconciler{
-		Client:          mgr.GetClient(),
-		Scheme:          mgr.GetScheme(),
-		DatabaseCreator: database.NewDatabase,
-		Recorder:        mgr.GetEventRecorderFor("database-users-operator"),
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		Databases: databases,
+		Recorder:  mgr.GetEventRecorderFor("database-users-operator"),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "User")
 		os.Exit(1)
 	}
+	if err = (&controllers.NamespacedUserReconciler{
+		UserReconciler: controllers.UserReconciler{
+			Client:    mgr.GetClient(),
+			Scheme:    mgr.GetScheme(),
+			Databases: databases,
+			Recorder:  mgr.GetEventRecorderFor("database-users-operator"),
+		},
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "NamespacedUser")
+		os.Exit(1)
+	}
+	if err = (&controllers.RoleReconciler{
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		Databases: databases,
+		Recorder:  mgr.GetEventRecorderFor("database-users-operator"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Role")
+		os.Exit(1)
+	}
+
+	if err = (&controllers.DatabaseReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		Databases:     databases,
+		CheckInterval: databaseCheckInterval,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Database")
+		os.Exit(1)
+	}
+	// Webhooks require serving certificates, so they are enabled explicitly, see config/webhook.
+	if os.Getenv("ENABLE_WEBHOOKS") == "true" {
+		if err = databaseusersoperatorcomv1alpha1.SetupUserWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "User")
+			os.Exit(1)
+		}
+		if err = databaseusersoperatorcomv1alpha1.SetupPrivilegesWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Privileges")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -0,0 +1,267 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package database
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"golang.org/x/sync/singleflight"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/alex123012/database-users-operator/api/v1alpha1"
+)
+
+// DefaultIdleTimeout is used, if zero idle timeout is passed to NewCache.
+const DefaultIdleTimeout = 5 * time.Minute
+
+// Creator creates new Database connected with the provided spec.
+type Creator func(ctx context.Context, s v1alpha1.DatabaseSpec, client client.Client, logger logr.Logger) (Database, error)
+
+// Cache shares connections to the databases between reconciles.
+// Connections are keyed by Database CR name, its generation
+// and resourceVersions of referenced secrets, so any change of the spec or secrets
+// leads to new connection. Connections that weren't used for idle timeout are closed.
+type Cache struct {
+	creator     Creator
+	idleTimeout time.Duration
+
+	// dials deduplicates concurrent connects with the same key,
+	// connects are made without holding lock, so slow database doesn't block others.
+	dials singleflight.Group
+
+	lock    sync.Mutex
+	entries map[string]*cacheEntry
+}
+
+type cacheEntry struct {
+	key      string
+	db       Database
+	refs     int
+	lastUsed time.Time
+	stale    bool
+}
+
+func NewCache(creator Creator, idleTimeout time.Duration) *Cache {
+	if idleTimeout <= 0 {
+		idleTimeout = DefaultIdleTimeout
+	}
+	return &Cache{
+		creator:     creator,
+		idleTimeout: idleTimeout,
+		entries:     make(map[string]*cacheEntry),
+	}
+}
+
+// Get returns connection to the database from the cache or creates new one.
+// Caller must close returned Database, which only releases it back to the cache.
+func (c *Cache) Get(ctx context.Context, dbConfig *v1alpha1.Database, client client.Client, logger logr.Logger) (Database, error) {
+	key, err := cacheKey(ctx, dbConfig, client)
+	if err != nil {
+		return nil, err
+	}
+
+	name := dbConfig.GetName()
+	for {
+		if db := c.acquire(name, key); db != nil {
+			return db, nil
+		}
+
+		_, err, _ := c.dials.Do(key, func() (interface{}, error) {
+			return nil, c.dial(ctx, name, key, dbConfig.Spec, client, logger)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// acquire returns cached connection with provided key or nil, if there is no such connection.
+func (c *Cache) acquire(name, key string) Database {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	entry, ok := c.entries[name]
+	if !ok || entry.key != key {
+		return nil
+	}
+	entry.refs++
+	return &cachedDatabase{Database: entry.db, cache: c, entry: entry}
+}
+
+// dial connects to the database and stores connection in the cache,
+// replacing connection with previous key.
+func (c *Cache) dial(ctx context.Context, name, key string, spec v1alpha1.DatabaseSpec, client client.Client, logger logr.Logger) error {
+	db, err := c.creator(ctx, spec, client, logger)
+	if err != nil {
+		return err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if entry, ok := c.entries[name]; ok {
+		if entry.key == key {
+			// Connection was stored by another dial, that finished earlier.
+			return db.Close(ctx)
+		}
+		c.retire(ctx, name)
+	}
+	c.entries[name] = &cacheEntry{key: key, db: db, lastUsed: time.Now()}
+	return nil
+}
+
+// Invalidate closes connection to the database with provided name,
+// when it is not used anymore.
+func (c *Cache) Invalidate(ctx context.Context, name string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.retire(ctx, name)
+}
+
+// Start evicts idle connections until context is done and then closes all connections.
+// It implements manager.Runnable interface.
+func (c *Cache) Start(ctx context.Context) error {
+	ticker := time.NewTicker(c.idleTimeout / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			c.closeAll(context.Background())
+			return nil
+		case now := <-ticker.C:
+			c.evict(ctx, now)
+		}
+	}
+}
+
+func (c *Cache) evict(ctx context.Context, now time.Time) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for name, entry := range c.entries {
+		if entry.refs == 0 && now.Sub(entry.lastUsed) >= c.idleTimeout {
+			c.retire(ctx, name)
+		}
+	}
+}
+
+func (c *Cache) closeAll(ctx context.Context) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for name := range c.entries {
+		c.retire(ctx, name)
+	}
+}
+
+// retire removes entry with provided name from the cache and closes its connection, if it is not used.
+// Otherwise connection will be closed on release. Lock must be held by caller.
+func (c *Cache) retire(ctx context.Context, name string) {
+	entry, ok := c.entries[name]
+	if !ok {
+		return
+	}
+	delete(c.entries, name)
+
+	entry.stale = true
+	if entry.refs == 0 {
+		_ = entry.db.Close(ctx)
+	}
+}
+
+func (c *Cache) release(ctx context.Context, entry *cacheEntry) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	entry.refs--
+	entry.lastUsed = time.Now()
+	if entry.stale && entry.refs == 0 {
+		return entry.db.Close(ctx)
+	}
+	return nil
+}
+
+// cachedDatabase releases connection back to the cache on Close.
+type cachedDatabase struct {
+	Database
+	cache    *Cache
+	entry    *cacheEntry
+	released sync.Once
+}
+
+func (d *cachedDatabase) Close(ctx context.Context) error {
+	var err error
+	d.released.Do(func() {
+		err = d.cache.release(ctx, d.entry)
+	})
+	return err
+}
+
+// cacheKey returns key, that changes on every change of the Database CR spec or referenced secrets.
+// Generation is used instead of resourceVersion, because the latter changes on every status update.
+func cacheKey(ctx context.Context, dbConfig *v1alpha1.Database, c client.Client) (string, error) {
+	key := []string{dbConfig.GetName(), strconv.FormatInt(dbConfig.GetGeneration(), 10)}
+	for _, nn := range secretRefs(dbConfig.Spec) {
+		secret := &v1.Secret{}
+		if err := c.Get(ctx, nn, secret); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return "", err
+			}
+		}
+		key = append(key, secret.GetResourceVersion())
+	}
+	return strings.Join(key, "/"), nil
+}
+
+// secretRefs returns secrets, that are used to connect to the database.
+func secretRefs(s v1alpha1.DatabaseSpec) []types.NamespacedName {
+	var secrets []v1alpha1.NamespacedName
+	switch {
+	case s.PostgreSQL != nil:
+		secrets = append(secrets, s.PostgreSQL.PasswordSecret.Secret, s.PostgreSQL.SSLCredentialsSecret, s.PostgreSQL.SSLCAKey.Secret)
+	case s.MySQL != nil:
+		secrets = append(secrets, s.MySQL.PasswordSecret.Secret)
+	case s.MariaDB != nil:
+		secrets = append(secrets, s.MariaDB.PasswordSecret.Secret)
+	case s.MSSQL != nil:
+		secrets = append(secrets, s.MSSQL.PasswordSecret.Secret)
+	case s.ClickHouse != nil:
+		secrets = append(secrets, s.ClickHouse.PasswordSecret.Secret)
+	case s.MongoDB != nil:
+		secrets = append(secrets, s.MongoDB.PasswordSecret.Secret)
+	case s.Redis != nil:
+		secrets = append(secrets, s.Redis.PasswordSecret.Secret)
+	}
+
+	refs := make([]types.NamespacedName, 0, len(secrets))
+	for _, secret := range secrets {
+		if secret.Name != "" && secret.Namespace != "" {
+			refs = append(refs, secret.ToNamespacedName())
+		}
+	}
+	return refs
+}
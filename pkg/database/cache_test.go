@@ -0,0 +1,142 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package database_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/alex123012/database-users-operator/api/v1alpha1"
+	"github.com/alex123012/database-users-operator/pkg/database"
+)
+
+type closeCounter struct {
+	database.Database
+	closed *int
+}
+
+func (c closeCounter) Close(context.Context) error {
+	*c.closed++
+	return nil
+}
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+
+	var created, closed int
+	creator := func(context.Context, v1alpha1.DatabaseSpec, client.Client, logr.Logger) (database.Database, error) {
+		created++
+		return closeCounter{closed: &closed}, nil
+	}
+
+	secret := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "password", Namespace: "default"}}
+	k8sClient := fake.NewClientBuilder().WithObjects(secret).Build()
+
+	dbConfig := &v1alpha1.Database{
+		ObjectMeta: metav1.ObjectMeta{Name: "postgres", Generation: 1, ResourceVersion: "1"},
+		Spec: v1alpha1.DatabaseSpec{
+			Type: v1alpha1.PostgreSQL,
+			PostgreSQL: &v1alpha1.PostgreSQLConfig{
+				PasswordSecret: v1alpha1.Secret{Key: "pass", Secret: v1alpha1.NamespacedName{Name: "password", Namespace: "default"}},
+			},
+		},
+	}
+
+	cache := database.NewCache(creator, 0)
+	get := func() database.Database {
+		db, err := cache.Get(ctx, dbConfig, k8sClient, logr.Discard())
+		if err != nil {
+			t.Fatalf("Cache.Get() error = %v", err)
+		}
+		return db
+	}
+	check := func(step string, wantCreated, wantClosed int) {
+		if created != wantCreated || closed != wantClosed {
+			t.Errorf("%s: created = %d, closed = %d, want %d, %d", step, created, closed, wantCreated, wantClosed)
+		}
+	}
+
+	first, second := get(), get()
+	first.Close(ctx)
+	first.Close(ctx)
+	second.Close(ctx)
+	check("same database", 1, 0)
+
+	dbConfig.ResourceVersion = "2"
+	get().Close(ctx)
+	check("changed status of database", 1, 0)
+
+	dbConfig.Generation = 2
+	inUse := get()
+	check("changed database", 2, 1)
+
+	secret.Data = map[string][]byte{"pass": []byte("new")}
+	if err := k8sClient.Update(ctx, secret); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	get().Close(ctx)
+	check("changed secret while in use", 3, 1)
+
+	inUse.Close(ctx)
+	check("release of stale connection", 3, 2)
+
+	cache.Invalidate(ctx, dbConfig.GetName())
+	check("invalidated database", 3, 3)
+}
+
+func TestCacheSlowDatabase(t *testing.T) {
+	ctx := context.Background()
+
+	unblock := make(chan struct{})
+	creator := func(_ context.Context, s v1alpha1.DatabaseSpec, _ client.Client, _ logr.Logger) (database.Database, error) {
+		if s.Type == v1alpha1.MySQL {
+			<-unblock
+		}
+		return closeCounter{closed: new(int)}, nil
+	}
+	cache := database.NewCache(creator, 0)
+	k8sClient := fake.NewClientBuilder().Build()
+
+	slow := &v1alpha1.Database{ObjectMeta: metav1.ObjectMeta{Name: "slow"}, Spec: v1alpha1.DatabaseSpec{Type: v1alpha1.MySQL}}
+	fast := &v1alpha1.Database{ObjectMeta: metav1.ObjectMeta{Name: "fast"}, Spec: v1alpha1.DatabaseSpec{Type: v1alpha1.PostgreSQL}}
+
+	done := make(chan error)
+	go func() {
+		db, err := cache.Get(ctx, slow, k8sClient, logr.Discard())
+		if err == nil {
+			db.Close(ctx)
+		}
+		done <- err
+	}()
+
+	db, err := cache.Get(ctx, fast, k8sClient, logr.Discard())
+	if err != nil {
+		t.Fatalf("Cache.Get() error = %v", err)
+	}
+	db.Close(ctx)
+
+	close(unblock)
+	if err := <-done; err != nil {
+		t.Fatalf("Cache.Get() error = %v", err)
+	}
+}
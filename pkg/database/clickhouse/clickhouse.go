@@ -0,0 +1,431 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clickhouse
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/go-logr/logr"
+
+	"github.com/alex123012/database-users-operator/api/v1alpha1"
+	"github.com/alex123012/database-users-operator/pkg/database/connection"
+)
+
+var ErrQuotaNotFound = errors.New("quota doesn't exist")
+
+type ClickHouse struct {
+	db     connection.Connection
+	config *Config
+	logger logr.Logger
+}
+
+func NewClickHouse(conn connection.Connection, config *Config, logger logr.Logger) *ClickHouse {
+	return &ClickHouse{
+		db:     conn,
+		logger: logger,
+		config: config,
+	}
+}
+
+func (c *ClickHouse) Connect(ctx context.Context) error {
+	return c.db.Connect(ctx, "clickhouse", c.config.ConnString())
+}
+
+func (c *ClickHouse) Close(ctx context.Context) error {
+	return c.db.Close(ctx)
+}
+
+func (c *ClickHouse) Ping(ctx context.Context) error {
+	return c.db.Ping(ctx)
+}
+
+// CreateUser creates the user with sha256_password authentication or sets the password, if the user already exists,
+// so grants of the existing user are kept.
+func (c *ClickHouse) CreateUser(ctx context.Context, username, password string) (map[string]string, error) {
+	exists, err := c.UserExists(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, c.SetPassword(ctx, username, password)
+	}
+
+	query := "CREATE USER IF NOT EXISTS ? IDENTIFIED WITH sha256_password BY ?"
+	return nil, c.db.Exec(ctx, connection.DisableLogger, query, username, password)
+}
+
+func (c *ClickHouse) SetPassword(ctx context.Context, username, password string) error {
+	query := "ALTER USER ? IDENTIFIED WITH sha256_password BY ?"
+	return c.db.Exec(ctx, connection.DisableLogger, query, username, password)
+}
+
+// AlterUser applies account options to the user. Options, that are not set, are reset to ClickHouse defaults.
+// ALTER USER has no quota clause, so the user is moved from the applied quota to the new one with ALTER QUOTA.
+func (c *ClickHouse) AlterUser(ctx context.Context, username, _ string, options, applied v1alpha1.UserOptions) error {
+	clickhouseOptions := options.ClickHouse
+	if clickhouseOptions == nil {
+		clickhouseOptions = &v1alpha1.ClickHouseUserOptions{}
+	}
+
+	query, args := alterUserQuery(username, clickhouseOptions)
+	if err := c.db.Exec(ctx, connection.EnableLogger, query, args...); err != nil {
+		return err
+	}
+
+	if applied.ClickHouse != nil && applied.ClickHouse.Quota != "" && applied.ClickHouse.Quota != clickhouseOptions.Quota {
+		// Quota could be dropped together with removing it from the spec.
+		if err := c.assignQuota(ctx, applied.ClickHouse.Quota, username, false); err != nil && !errors.Is(err, ErrQuotaNotFound) {
+			return err
+		}
+	}
+	if clickhouseOptions.Quota != "" {
+		return c.assignQuota(ctx, clickhouseOptions.Quota, username, true)
+	}
+	return nil
+}
+
+type quotaAssignees struct {
+	All    uint8    `db:"apply_to_all"`
+	List   []string `db:"apply_to_list"`
+	Except []string `db:"apply_to_except"`
+}
+
+// assignQuota applies the quota to the user or stops applying it.
+// ALTER QUOTA replaces all roles and users of the quota, so current ones are read from system.quotas and kept.
+// For quota applied to all users, the user is removed from or added to the except list.
+func (c *ClickHouse) assignQuota(ctx context.Context, quota, username string, assign bool) error {
+	var assignees quotaAssignees
+	query := "SELECT apply_to_all, apply_to_list, apply_to_except FROM system.quotas WHERE name = ?"
+	if err := c.db.Get(ctx, connection.EnableLogger, &assignees, query, quota); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: %s", ErrQuotaNotFound, quota)
+		}
+		return err
+	}
+
+	all, names := assignees.All != 0, assignees.List
+	if all {
+		names, assign = assignees.Except, !assign
+	}
+	if slices.Contains(names, username) == assign {
+		return nil
+	}
+
+	names = slices.DeleteFunc(slices.Clone(names), func(name string) bool { return name == username })
+	if assign {
+		names = append(names, username)
+	}
+
+	args := []interface{}{quota}
+	for _, name := range names {
+		args = append(args, name)
+	}
+	switch {
+	case all && len(names) == 0:
+		query = "ALTER QUOTA ? TO ALL"
+	case all:
+		query = "ALTER QUOTA ? TO ALL EXCEPT " + placeholders(len(names))
+	case len(names) == 0:
+		query = "ALTER QUOTA ? TO NONE"
+	default:
+		query = "ALTER QUOTA ? TO " + placeholders(len(names))
+	}
+	return c.db.Exec(ctx, connection.EnableLogger, query, args...)
+}
+
+func (c *ClickHouse) DeleteUser(ctx context.Context, username string) error {
+	query := "DROP USER IF EXISTS ?"
+	return c.db.Exec(ctx, connection.EnableLogger, query, username)
+}
+
+func (c *ClickHouse) CreateRole(ctx context.Context, name string) error {
+	query := "CREATE ROLE IF NOT EXISTS ?"
+	return c.db.Exec(ctx, connection.EnableLogger, query, name)
+}
+
+func (c *ClickHouse) DeleteRole(ctx context.Context, name string) error {
+	query := "DROP ROLE IF EXISTS ?"
+	return c.db.Exec(ctx, connection.EnableLogger, query, name)
+}
+
+func (c *ClickHouse) ApplyPrivileges(ctx context.Context, username string, privileges []v1alpha1.PrivilegeSpec) error {
+	return c.privilegesProcessor(ctx, username, privileges, "GRANT", "TO")
+}
+
+func (c *ClickHouse) RevokePrivileges(ctx context.Context, username string, privileges []v1alpha1.PrivilegeSpec) error {
+	return c.privilegesProcessor(ctx, username, privileges, "REVOKE", "FROM")
+}
+
+// ApplyRolePrivileges grants privileges to the role, privileges are granted to roles the same way as to users.
+func (c *ClickHouse) ApplyRolePrivileges(ctx context.Context, name string, privileges []v1alpha1.PrivilegeSpec) error {
+	return c.ApplyPrivileges(ctx, name, privileges)
+}
+
+func (c *ClickHouse) RevokeRolePrivileges(ctx context.Context, name string, privileges []v1alpha1.PrivilegeSpec) error {
+	return c.RevokePrivileges(ctx, name, privileges)
+}
+
+// GrantRoles grants roles to the user.
+// ClickHouse activates all granted roles by default, so if some roles are marked as default, only they are activated.
+func (c *ClickHouse) GrantRoles(ctx context.Context, username string, roles []v1alpha1.RoleGrant) error {
+	var defaultRoles []interface{}
+	for _, role := range roles {
+		query := "GRANT ? TO ?"
+		if role.AdminOption {
+			query += " WITH ADMIN OPTION"
+		}
+		if err := c.db.Exec(ctx, connection.EnableLogger, query, role.Name, username); err != nil {
+			return err
+		}
+		if role.Default {
+			defaultRoles = append(defaultRoles, role.Name)
+		}
+	}
+
+	if len(defaultRoles) < 1 {
+		return nil
+	}
+	query := "SET DEFAULT ROLE " + strings.TrimSuffix(strings.Repeat("?, ", len(defaultRoles)), ", ") + " TO ?"
+	return c.db.Exec(ctx, connection.EnableLogger, query, append(defaultRoles, username)...)
+}
+
+// RevokeRoles revokes roles from the user, ClickHouse removes revoked roles from default ones itself.
+func (c *ClickHouse) RevokeRoles(ctx context.Context, username string, roles []v1alpha1.RoleGrant) error {
+	for _, role := range roles {
+		query := "REVOKE ? FROM ?"
+		if err := c.db.Exec(ctx, connection.EnableLogger, query, role.Name, username); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *ClickHouse) privilegesProcessor(ctx context.Context, username string, privileges []v1alpha1.PrivilegeSpec, statement, arg string) error {
+	for _, privilege := range privileges {
+		if privilege.ObjectType == "" && privilege.Database == "" {
+			// Privilege without database is the name of the role.
+			query := statement + " ? " + arg + " ?"
+			if err := c.db.Exec(ctx, connection.EnableLogger, query, privilege.Privilege, username); err != nil {
+				return err
+			}
+			continue
+		}
+
+		queries, err := prepareStatementsForObjectPrivilege(statement, arg, objectSpec(privilege))
+		if err != nil {
+			return err
+		}
+		for _, query := range queries {
+			if err := c.db.Exec(ctx, connection.EnableLogger, query, username); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c *ClickHouse) UserExists(ctx context.Context, username string) (bool, error) {
+	var users []string
+	query := "SELECT name FROM system.users WHERE name = ?"
+	if err := c.db.Select(ctx, connection.EnableLogger, &users, query, username); err != nil {
+		return false, err
+	}
+	return len(users) > 0, nil
+}
+
+func (c *ClickHouse) ServerVersion(ctx context.Context) (string, error) {
+	var version string
+	query := "SELECT version()"
+	if err := c.db.Get(ctx, connection.EnableLogger, &version, query); err != nil {
+		return "", err
+	}
+	return version, nil
+}
+
+// TLSEnabled returns whether the connection is configured to use TLS,
+// ClickHouse doesn't expose TLS state of the current session.
+func (c *ClickHouse) TLSEnabled(_ context.Context) (bool, error) {
+	return c.config.Secure, nil
+}
+
+type grant struct {
+	AccessType  string `db:"access_type"`
+	Database    string `db:"database"`
+	Table       string `db:"table"`
+	GrantOption uint8  `db:"grant_option"`
+}
+
+// ListPrivileges returns granted roles and privileges of the user, partial revokes and column privileges are skipped.
+func (c *ClickHouse) ListPrivileges(ctx context.Context, username string) ([]v1alpha1.PrivilegeSpec, error) {
+	var roles []string
+	query := "SELECT granted_role_name FROM system.role_grants WHERE user_name = ?"
+	if err := c.db.Select(ctx, connection.EnableLogger, &roles, query, username); err != nil {
+		return nil, err
+	}
+
+	var privileges []v1alpha1.PrivilegeSpec
+	for _, role := range roles {
+		privileges = append(privileges, v1alpha1.PrivilegeSpec{Privilege: v1alpha1.PrivilegeType(role)})
+	}
+
+	var grants []grant
+	query = "SELECT access_type, ifNull(database, '') AS database, ifNull(table, '') AS table, grant_option" +
+		" FROM system.grants WHERE user_name = ? AND is_partial_revoke = 0 AND column IS NULL"
+	if err := c.db.Select(ctx, connection.EnableLogger, &grants, query, username); err != nil {
+		return nil, err
+	}
+	for _, g := range grants {
+		privileges = append(privileges, grantSpec(g))
+	}
+	return privileges, nil
+}
+
+func grantSpec(g grant) v1alpha1.PrivilegeSpec {
+	spec := v1alpha1.PrivilegeSpec{
+		Database:        g.Database,
+		Privileges:      []v1alpha1.PrivilegeType{v1alpha1.PrivilegeType(g.AccessType)},
+		WithGrantOption: g.GrantOption != 0,
+	}
+	switch {
+	case g.Database == "":
+		spec.ObjectType = v1alpha1.ObjectGlobal
+	case g.Table == "":
+		spec.ObjectType = v1alpha1.ObjectDatabase
+	default:
+		spec.ObjectType = v1alpha1.ObjectTable
+		spec.Objects = []string{g.Table}
+	}
+	return spec
+}
+
+// objectSpec converts privilege in legacy format (privilege, database and on) to the structured one,
+// so privileges are rendered as keywords and database and table as quoted identifiers.
+func objectSpec(privilege v1alpha1.PrivilegeSpec) v1alpha1.PrivilegeSpec {
+	if privilege.ObjectType != "" {
+		return privilege
+	}
+
+	spec := v1alpha1.PrivilegeSpec{Database: privilege.Database}
+	switch {
+	case privilege.Database == v1alpha1.MySQLGlobalDatabase:
+		spec.ObjectType = v1alpha1.ObjectGlobal
+	case privilege.On == "" || privilege.On == "*":
+		spec.ObjectType = v1alpha1.ObjectDatabase
+	default:
+		spec.ObjectType = v1alpha1.ObjectTable
+		spec.Objects = []string{privilege.On}
+	}
+	for _, item := range strings.Split(string(privilege.Privilege), ",") {
+		spec.Privileges = append(spec.Privileges, v1alpha1.PrivilegeType(strings.TrimSpace(item)))
+	}
+	return spec
+}
+
+// prepareStatementsForObjectPrivilege returns statements for the privilege with placeholder for the user.
+// ClickHouse allows only one table in the statement, so every table gets its own statement.
+func prepareStatementsForObjectPrivilege(statement, arg string, privilege v1alpha1.PrivilegeSpec) ([]string, error) {
+	if privilege.DefaultPrivileges != nil {
+		return nil, fmt.Errorf("%w: default privileges are not supported by ClickHouse", v1alpha1.ErrInvalidPrivilege)
+	}
+
+	privileges, err := privilege.PrivilegesList()
+	if err != nil {
+		return nil, err
+	}
+
+	targets, err := objectTargets(privilege)
+	if err != nil {
+		return nil, err
+	}
+
+	queries := make([]string, 0, len(targets))
+	for _, target := range targets {
+		stmtBuilder := &strings.Builder{}
+		stmtBuilder.WriteString(statement)
+		stmtBuilder.WriteString(" ")
+		stmtBuilder.WriteString(privileges)
+		stmtBuilder.WriteString(" ON ")
+		stmtBuilder.WriteString(target)
+		stmtBuilder.WriteString(" ")
+		stmtBuilder.WriteString(arg)
+		stmtBuilder.WriteString(" ?")
+		if privilege.WithGrantOption && statement == "GRANT" {
+			stmtBuilder.WriteString(" WITH GRANT OPTION")
+		}
+		queries = append(queries, stmtBuilder.String())
+	}
+	return queries, nil
+}
+
+// objectTargets returns quoted "db.table" scopes of the privilege for "ON" clause.
+func objectTargets(privilege v1alpha1.PrivilegeSpec) ([]string, error) {
+	switch privilege.ObjectType {
+	case v1alpha1.ObjectGlobal:
+		return []string{"*.*"}, nil
+
+	case v1alpha1.ObjectDatabase:
+		return []string{quoteIdentifier(privilege.Database) + ".*"}, nil
+
+	case v1alpha1.ObjectTable:
+		dbname := quoteIdentifier(privilege.Database)
+		if privilege.AllObjects() {
+			return []string{dbname + ".*"}, nil
+		}
+		targets := make([]string, 0, len(privilege.Objects))
+		for _, object := range privilege.Objects {
+			targets = append(targets, dbname+"."+quoteIdentifier(object))
+		}
+		return targets, nil
+	}
+	return nil, fmt.Errorf("%w: objectType %s is not supported by ClickHouse", v1alpha1.ErrInvalidPrivilege, privilege.ObjectType)
+}
+
+func alterUserQuery(username string, options *v1alpha1.ClickHouseUserOptions) (string, []interface{}) {
+	stmtBuilder := &strings.Builder{}
+	stmtBuilder.WriteString("ALTER USER ?")
+	args := []interface{}{username}
+
+	if len(options.HostIPs) > 0 {
+		stmtBuilder.WriteString(" HOST IP ")
+		stmtBuilder.WriteString(placeholders(len(options.HostIPs)))
+		for _, ip := range options.HostIPs {
+			args = append(args, ip)
+		}
+	} else {
+		stmtBuilder.WriteString(" HOST ANY")
+	}
+
+	stmtBuilder.WriteString(" DEFAULT DATABASE ")
+	if options.DefaultDatabase != "" {
+		stmtBuilder.WriteString(quoteIdentifier(options.DefaultDatabase))
+	} else {
+		stmtBuilder.WriteString("NONE")
+	}
+
+	if options.SettingsProfile != "" {
+		stmtBuilder.WriteString(" SETTINGS PROFILE ?")
+		args = append(args, options.SettingsProfile)
+	} else {
+		stmtBuilder.WriteString(" SETTINGS NONE")
+	}
+	return stmtBuilder.String(), args
+}
@@ -0,0 +1,377 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clickhouse_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/go-logr/logr"
+
+	"github.com/alex123012/database-users-operator/api/v1alpha1"
+	"github.com/alex123012/database-users-operator/pkg/database/clickhouse"
+	"github.com/alex123012/database-users-operator/pkg/database/connection"
+	testsutils "github.com/alex123012/database-users-operator/pkg/utils/tests_utils"
+)
+
+func newClickHouse(t *testing.T, mockDB connection.Connection) *clickhouse.ClickHouse {
+	t.Helper()
+	c := clickhouse.NewClickHouse(mockDB, clickhouse.NewConfig("clickhouse", 9000, "default", "password", "", v1alpha1.ClickHouseNative, false), logr.Discard())
+	if err := c.Connect(context.Background()); err != nil {
+		t.Fatalf("ClickHouse.Connect() error = %v", err)
+	}
+	t.Cleanup(func() { c.Close(context.Background()) })
+	return c
+}
+
+func TestClickHouse_CreateUser(t *testing.T) {
+	const usersQuery = "SELECT name FROM system.users WHERE name = ?"
+
+	tests := []struct {
+		name   string
+		exists bool
+		want   string
+	}{
+		{
+			name: "New user is created with sha256_password",
+			want: fmt.Sprint("CREATE USER IF NOT EXISTS ? IDENTIFIED WITH sha256_password BY ?", "john", "newpass"),
+		},
+		{
+			name:   "Existing user only gets the password, so its grants are kept",
+			exists: true,
+			want:   fmt.Sprint("ALTER USER ? IDENTIFIED WITH sha256_password BY ?", "john", "newpass"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := connection.NewFakeConnection()
+			c := newClickHouse(t, mockDB)
+			if tt.exists {
+				mockDB.SetResult([]string{"john"}, usersQuery, "john")
+			}
+
+			if _, err := c.CreateUser(context.Background(), "john", "newpass"); err != nil {
+				t.Errorf("ClickHouse.CreateUser() error = %v", err)
+			}
+			testsutils.CheckQueries(t, []string{tt.want}, mockDB.Queries())
+			testsutils.CheckQueries(t, []string{fmt.Sprint(usersQuery, "john")}, mockDB.Reads())
+		})
+	}
+}
+
+func TestClickHouse_Privileges(t *testing.T) {
+	tests := []struct {
+		name       string
+		privileges []v1alpha1.PrivilegeSpec
+		want       []string
+	}{
+		{
+			name: "Privilege without database is the name of the role",
+			privileges: []v1alpha1.PrivilegeSpec{
+				{Privilege: "readers"},
+				{Privilege: "SYSTEM FLUSH LOGS", Database: v1alpha1.MySQLGlobalDatabase},
+			},
+			want: []string{
+				fmt.Sprint("GRANT ? TO ?", "readers", "john"),
+				fmt.Sprint("GRANT SYSTEM FLUSH LOGS ON *.* TO ?", "john"),
+				fmt.Sprint("REVOKE ? FROM ?", "readers", "john"),
+				fmt.Sprint("REVOKE SYSTEM FLUSH LOGS ON *.* FROM ?", "john"),
+			},
+		},
+		{
+			name: "Table privileges are granted on every table, grant option is revoked with the privilege",
+			privileges: []v1alpha1.PrivilegeSpec{
+				{Privilege: "SELECT, insert", On: "events", Database: "analytics"},
+				{ObjectType: v1alpha1.ObjectTable, Database: "analytics", Objects: []string{"events", "sessions"}, Privileges: []v1alpha1.PrivilegeType{v1alpha1.SELECT}, WithGrantOption: true},
+				{ObjectType: v1alpha1.ObjectTable, Database: "raw", Privileges: []v1alpha1.PrivilegeType{v1alpha1.INSERT}},
+			},
+			want: []string{
+				fmt.Sprint("GRANT SELECT, INSERT ON `analytics`.`events` TO ?", "john"),
+				fmt.Sprint("GRANT SELECT ON `analytics`.`events` TO ? WITH GRANT OPTION", "john"),
+				fmt.Sprint("GRANT SELECT ON `analytics`.`sessions` TO ? WITH GRANT OPTION", "john"),
+				fmt.Sprint("GRANT INSERT ON `raw`.* TO ?", "john"),
+				fmt.Sprint("REVOKE SELECT, INSERT ON `analytics`.`events` FROM ?", "john"),
+				fmt.Sprint("REVOKE SELECT ON `analytics`.`events` FROM ?", "john"),
+				fmt.Sprint("REVOKE SELECT ON `analytics`.`sessions` FROM ?", "john"),
+				fmt.Sprint("REVOKE INSERT ON `raw`.* FROM ?", "john"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			mockDB := connection.NewFakeConnection()
+			c := newClickHouse(t, mockDB)
+			if err := c.ApplyPrivileges(ctx, "john", tt.privileges); err != nil {
+				t.Errorf("ClickHouse.ApplyPrivileges() error = %v", err)
+			}
+			if err := c.RevokePrivileges(ctx, "john", tt.privileges); err != nil {
+				t.Errorf("ClickHouse.RevokePrivileges() error = %v", err)
+			}
+			testsutils.CheckQueries(t, tt.want, mockDB.Queries())
+		})
+	}
+}
+
+func TestClickHouse_UnsupportedPrivileges(t *testing.T) {
+	// ClickHouse has no functions, schemas and default privileges.
+	privileges := []v1alpha1.PrivilegeSpec{
+		{ObjectType: v1alpha1.ObjectFunction, Database: "dat", Objects: []string{"calc"}, Privileges: []v1alpha1.PrivilegeType{v1alpha1.EXECUTE}},
+		{ObjectType: v1alpha1.ObjectSchema, Database: "dat", Privileges: []v1alpha1.PrivilegeType{v1alpha1.USAGE}},
+		{ObjectType: v1alpha1.ObjectTable, Database: "dat", Privileges: []v1alpha1.PrivilegeType{v1alpha1.SELECT}, DefaultPrivileges: &v1alpha1.DefaultPrivileges{}},
+	}
+
+	mockDB := connection.NewFakeConnection()
+	c := newClickHouse(t, mockDB)
+	for _, privilege := range privileges {
+		if err := c.ApplyPrivileges(context.Background(), "john", []v1alpha1.PrivilegeSpec{privilege}); !errors.Is(err, v1alpha1.ErrInvalidPrivilege) {
+			t.Errorf("ClickHouse.ApplyPrivileges(%v) error = %v, want %v", privilege, err, v1alpha1.ErrInvalidPrivilege)
+		}
+	}
+
+	if queries := mockDB.Queries(); len(queries) != 0 {
+		t.Errorf("Queries executed for invalid privileges: %v", queries)
+	}
+}
+
+func TestClickHouse_DefaultRoles(t *testing.T) {
+	tests := []struct {
+		name  string
+		roles []v1alpha1.RoleGrant
+		want  []string
+	}{
+		{
+			name: "Only roles marked as default are activated",
+			roles: []v1alpha1.RoleGrant{
+				{Name: "readers", Default: true},
+				{Name: "writers", AdminOption: true},
+				{Name: "auditors", Default: true},
+			},
+			want: []string{
+				fmt.Sprint("GRANT ? TO ?", "readers", "john"),
+				fmt.Sprint("GRANT ? TO ? WITH ADMIN OPTION", "writers", "john"),
+				fmt.Sprint("GRANT ? TO ?", "auditors", "john"),
+				fmt.Sprint("SET DEFAULT ROLE ?, ? TO ?", "readers", "auditors", "john"),
+			},
+		},
+		{
+			name: "All roles stay activated without default ones",
+			roles: []v1alpha1.RoleGrant{
+				{Name: "readers"},
+				{Name: "writers"},
+			},
+			want: []string{
+				fmt.Sprint("GRANT ? TO ?", "readers", "john"),
+				fmt.Sprint("GRANT ? TO ?", "writers", "john"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			mockDB := connection.NewFakeConnection()
+			c := newClickHouse(t, mockDB)
+			if err := c.GrantRoles(ctx, "john", tt.roles); err != nil {
+				t.Errorf("ClickHouse.GrantRoles() error = %v", err)
+			}
+			// Revoked roles are removed from default ones by ClickHouse itself.
+			if err := c.RevokeRoles(ctx, "john", tt.roles[:1]); err != nil {
+				t.Errorf("ClickHouse.RevokeRoles() error = %v", err)
+			}
+			testsutils.CheckQueries(t, append(tt.want, fmt.Sprint("REVOKE ? FROM ?", tt.roles[0].Name, "john")), mockDB.Queries())
+		})
+	}
+}
+
+func TestClickHouse_AlterUser(t *testing.T) {
+	tests := []struct {
+		name    string
+		options v1alpha1.UserOptions
+		want    string
+	}{
+		{
+			name:    "Reset to defaults, MySQL options are ignored",
+			options: v1alpha1.UserOptions{MySQL: &v1alpha1.MySQLUserOptions{MySQLAccountOptions: v1alpha1.MySQLAccountOptions{AccountLocked: true}}},
+			want:    fmt.Sprint("ALTER USER ? HOST ANY DEFAULT DATABASE NONE SETTINGS NONE", "john"),
+		},
+		{
+			name: "Set hosts, default database and settings profile",
+			options: v1alpha1.UserOptions{ClickHouse: &v1alpha1.ClickHouseUserOptions{
+				SettingsProfile: "analysts",
+				DefaultDatabase: "analytics",
+				HostIPs:         []string{"10.0.0.0/8", "192.168.0.0/16"},
+			}},
+			want: fmt.Sprint("ALTER USER ? HOST IP ?, ? DEFAULT DATABASE `analytics` SETTINGS PROFILE ?", "john", "10.0.0.0/8", "192.168.0.0/16", "analysts"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := connection.NewFakeConnection()
+			c := newClickHouse(t, mockDB)
+			if err := c.AlterUser(context.Background(), "john", "", tt.options, v1alpha1.UserOptions{}); err != nil {
+				t.Fatalf("ClickHouse.AlterUser() error = %v", err)
+			}
+
+			if queries := mockDB.Queries(); len(queries) != 1 || queries[tt.want] != 1 {
+				t.Errorf("ClickHouse.AlterUser() queries = %v, want %s", queries, tt.want)
+			}
+		})
+	}
+}
+
+func TestClickHouse_Quota(t *testing.T) {
+	const quotasQuery = "SELECT apply_to_all, apply_to_list, apply_to_except FROM system.quotas WHERE name = ?"
+	resetUser := fmt.Sprint("ALTER USER ? HOST ANY DEFAULT DATABASE NONE SETTINGS NONE", "john")
+	withQuota := func(quota string) v1alpha1.UserOptions {
+		return v1alpha1.UserOptions{ClickHouse: &v1alpha1.ClickHouseUserOptions{Quota: quota}}
+	}
+
+	tests := []struct {
+		name    string
+		quotas  map[string]map[string]interface{}
+		options v1alpha1.UserOptions
+		applied v1alpha1.UserOptions
+		want    []string
+		wantErr error
+	}{
+		{
+			name:    "Users of the quota are kept",
+			quotas:  map[string]map[string]interface{}{"analysts": {"apply_to_list": []string{"jane"}}},
+			options: withQuota("analysts"),
+			want:    []string{resetUser, fmt.Sprint("ALTER QUOTA ? TO ?, ?", "analysts", "jane", "john")},
+		},
+		{
+			name:    "Quota is already applied to the user",
+			quotas:  map[string]map[string]interface{}{"analysts": {"apply_to_list": []string{"jane", "john"}}},
+			options: withQuota("analysts"),
+			applied: withQuota("analysts"),
+			want:    []string{resetUser},
+		},
+		{
+			name:    "User is removed from except list of the quota for all users",
+			quotas:  map[string]map[string]interface{}{"analysts": {"apply_to_all": uint8(1), "apply_to_except": []string{"john", "jane"}}},
+			options: withQuota("analysts"),
+			want:    []string{resetUser, fmt.Sprint("ALTER QUOTA ? TO ALL EXCEPT ?", "analysts", "jane")},
+		},
+		{
+			name: "User is moved to another quota",
+			quotas: map[string]map[string]interface{}{
+				"analysts":   {"apply_to_list": []string{"john", "jane"}},
+				"developers": {},
+			},
+			options: withQuota("developers"),
+			applied: withQuota("analysts"),
+			want: []string{
+				resetUser,
+				fmt.Sprint("ALTER QUOTA ? TO ?", "analysts", "jane"),
+				fmt.Sprint("ALTER QUOTA ? TO ?", "developers", "john"),
+			},
+		},
+		{
+			name:    "Quota for all users is removed from the user",
+			quotas:  map[string]map[string]interface{}{"analysts": {"apply_to_all": uint8(1)}},
+			applied: withQuota("analysts"),
+			want:    []string{resetUser, fmt.Sprint("ALTER QUOTA ? TO ALL EXCEPT ?", "analysts", "john")},
+		},
+		{
+			name:    "Removed quota was already dropped",
+			applied: withQuota("analysts"),
+			want:    []string{resetUser},
+		},
+		{
+			name:    "Quota doesn't exist",
+			options: withQuota("analysts"),
+			want:    []string{resetUser},
+			wantErr: clickhouse.ErrQuotaNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := connection.NewFakeConnection()
+			c := newClickHouse(t, mockDB)
+			for quota, assignees := range tt.quotas {
+				mockDB.SetResult(assignees, quotasQuery, quota)
+			}
+
+			if err := c.AlterUser(context.Background(), "john", "", tt.options, tt.applied); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ClickHouse.AlterUser() error = %v, want %v", err, tt.wantErr)
+			}
+
+			testsutils.CheckQueries(t, tt.want, mockDB.Queries())
+		})
+	}
+}
+
+func TestClickHouse_Introspection(t *testing.T) {
+	ctx := context.Background()
+	username := "john"
+
+	mockDB := connection.NewFakeConnection()
+	c := newClickHouse(t, mockDB)
+
+	exists, err := c.UserExists(ctx, username)
+	if err != nil || exists {
+		t.Errorf("ClickHouse.UserExists() = %v, %v, want false, nil", exists, err)
+	}
+
+	mockDB.SetResult([]string{username}, "SELECT name FROM system.users WHERE name = ?", username)
+	mockDB.SetResult([]string{"readers"}, "SELECT granted_role_name FROM system.role_grants WHERE user_name = ?", username)
+	mockDB.SetResult([]map[string]interface{}{
+		{"access_type": "SELECT", "database": "analytics", "table": "events", "grant_option": uint8(1)},
+		{"access_type": "INSERT", "database": "analytics", "table": "", "grant_option": uint8(0)},
+		{"access_type": "SYSTEM FLUSH LOGS", "database": "", "table": "", "grant_option": uint8(0)},
+	}, "SELECT access_type, ifNull(database, '') AS database, ifNull(table, '') AS table, grant_option"+
+		" FROM system.grants WHERE user_name = ? AND is_partial_revoke = 0 AND column IS NULL", username)
+	mockDB.SetResult("23.8.2.7", "SELECT version()")
+
+	exists, err = c.UserExists(ctx, username)
+	if err != nil || !exists {
+		t.Errorf("ClickHouse.UserExists() = %v, %v, want true, nil", exists, err)
+	}
+
+	privileges, err := c.ListPrivileges(ctx, username)
+	if err != nil {
+		t.Fatalf("ClickHouse.ListPrivileges() error = %v", err)
+	}
+	want := []v1alpha1.PrivilegeSpec{
+		{Privilege: "readers"},
+		{ObjectType: v1alpha1.ObjectTable, Database: "analytics", Objects: []string{"events"}, Privileges: []v1alpha1.PrivilegeType{v1alpha1.SELECT}, WithGrantOption: true},
+		{ObjectType: v1alpha1.ObjectDatabase, Database: "analytics", Privileges: []v1alpha1.PrivilegeType{v1alpha1.INSERT}},
+		{ObjectType: v1alpha1.ObjectGlobal, Privileges: []v1alpha1.PrivilegeType{"SYSTEM FLUSH LOGS"}},
+	}
+	if !reflect.DeepEqual(privileges, want) {
+		t.Errorf("ClickHouse.ListPrivileges() = %v, want %v", privileges, want)
+	}
+
+	version, err := c.ServerVersion(ctx)
+	if err != nil || version != "23.8.2.7" {
+		t.Errorf("ClickHouse.ServerVersion() = %v, %v, want 23.8.2.7, nil", version, err)
+	}
+
+	tls, err := c.TLSEnabled(ctx)
+	if err != nil || tls {
+		t.Errorf("ClickHouse.TLSEnabled() = %v, %v, want false, nil", tls, err)
+	}
+}
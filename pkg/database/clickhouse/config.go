@@ -0,0 +1,76 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clickhouse
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/alex123012/database-users-operator/api/v1alpha1"
+)
+
+type Config struct {
+	Host         string
+	Port         int
+	User         string
+	Password     string
+	DatabaseName string
+	Protocol     v1alpha1.ClickHouseProtocol
+	Secure       bool
+}
+
+func NewConfig(host string, port int, user, pass, dbname string, protocol v1alpha1.ClickHouseProtocol, secure bool) *Config {
+	return &Config{
+		Host:         host,
+		User:         user,
+		Password:     pass,
+		Port:         port,
+		DatabaseName: dbname,
+		Protocol:     protocol,
+		Secure:       secure,
+	}
+}
+
+// ConnString returns DSN for ClickHouse driver, protocol is chosen by the scheme of DSN.
+func (c *Config) ConnString() string {
+	userInfo := url.User(c.User)
+	if c.Password != "" {
+		userInfo = url.UserPassword(c.User, c.Password)
+	}
+
+	scheme := "clickhouse"
+	if c.Protocol == v1alpha1.ClickHouseHTTP {
+		scheme = "http"
+		if c.Secure {
+			scheme = "https"
+		}
+	}
+
+	query := url.Values{}
+	if c.Secure {
+		query.Set("secure", "true")
+	}
+
+	u := url.URL{
+		Scheme:   scheme,
+		Host:     fmt.Sprintf("%s:%d", c.Host, c.Port),
+		User:     userInfo,
+		Path:     "/" + c.DatabaseName,
+		RawQuery: query.Encode(),
+	}
+	return u.String()
+}
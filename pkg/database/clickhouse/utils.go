@@ -0,0 +1,31 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clickhouse
+
+import "strings"
+
+var identifierReplacer = strings.NewReplacer(`\`, `\\`, "`", "\\`")
+
+// quoteIdentifier quotes identifier with backticks, backticks and backslashes inside of it are escaped with backslash.
+func quoteIdentifier(str string) string {
+	return "`" + identifierReplacer.Replace(str) + "`"
+}
+
+// placeholders returns comma separated list of n placeholders.
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
@@ -31,5 +31,8 @@ type Connection interface {
 	Copy() Connection
 	Close(ctx context.Context) error
 	Connect(ctx context.Context, driver string, connString string) error
+	Ping(ctx context.Context) error
 	Exec(ctx context.Context, disableLog LogInfo, query string, args ...interface{}) error
+	Select(ctx context.Context, disableLog LogInfo, dest interface{}, query string, args ...interface{}) error
+	Get(ctx context.Context, disableLog LogInfo, dest interface{}, query string, args ...interface{}) error
 }
@@ -19,13 +19,18 @@ package connection
 import (
 	"context"
 	"fmt"
+	"time"
 
+	_ "github.com/ClickHouse/clickhouse-go/v2" // package for clickhouse
 	"github.com/go-logr/logr"
 	_ "github.com/go-sql-driver/mysql" // package for mysql
 	_ "github.com/jackc/pgx/v5/stdlib" // package for postgres
 	"github.com/jmoiron/sqlx"
+	_ "github.com/microsoft/go-mssqldb" // package for mssql
 )
 
+const connMaxIdleTime = 10 * time.Minute
+
 type DefaultConnector struct {
 	db     *sqlx.DB
 	logger logr.Logger
@@ -46,8 +51,11 @@ func (d *DefaultConnector) Connect(ctx context.Context, driver, connString strin
 	if err != nil {
 		return err
 	}
-	db.SetMaxIdleConns(0)
+	// Connection is reused between reconciles, so keep it open,
+	// but let it be reestablished, if it is idle for a long time.
+	db.SetMaxIdleConns(1)
 	db.SetMaxOpenConns(1)
+	db.SetConnMaxIdleTime(connMaxIdleTime)
 	d.db = db
 	return nil
 }
@@ -56,6 +64,10 @@ func (d *DefaultConnector) Close(_ context.Context) error {
 	return d.db.Close()
 }
 
+func (d *DefaultConnector) Ping(ctx context.Context) error {
+	return d.db.PingContext(ctx)
+}
+
 func (d *DefaultConnector) infoLog(disableLog LogInfo, query string, args ...interface{}) {
 	if disableLog == DisableLogger {
 		return
@@ -68,3 +80,13 @@ func (d *DefaultConnector) Exec(ctx context.Context, disableLog LogInfo, query s
 	_, err := d.db.ExecContext(ctx, query, args...)
 	return err
 }
+
+func (d *DefaultConnector) Select(ctx context.Context, disableLog LogInfo, dest interface{}, query string, args ...interface{}) error {
+	d.infoLog(disableLog, query, args...)
+	return d.db.SelectContext(ctx, dest, query, args...)
+}
+
+func (d *DefaultConnector) Get(ctx context.Context, disableLog LogInfo, dest interface{}, query string, args ...interface{}) error {
+	d.infoLog(disableLog, query, args...)
+	return d.db.GetContext(ctx, dest, query, args...)
+}
@@ -18,14 +18,22 @@ package connection
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"reflect"
+	"strings"
 	"sync"
 )
 
 type FakeConnection struct {
 	queries     map[string]int
 	count       int
+	reads       map[string]int
+	readsCount  int
 	connections map[string]bool
+	results     map[string]interface{}
+	errors      map[string]error
+	pingErr     error
 	lock        *sync.RWMutex
 }
 
@@ -56,6 +64,19 @@ func (m *FakeConnection) Close(_ context.Context) error {
 	return nil
 }
 
+// Ping returns error from SetPingError, so tests can emulate unreachable database.
+func (m *FakeConnection) Ping(_ context.Context) error {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return m.pingErr
+}
+
+func (m *FakeConnection) SetPingError(err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.pingErr = err
+}
+
 func (m *FakeConnection) Copy() Connection {
 	m.lock.RLock()
 	defer m.lock.RUnlock()
@@ -65,10 +86,54 @@ func (m *FakeConnection) Copy() Connection {
 func (m *FakeConnection) Exec(_ context.Context, _ LogInfo, query string, args ...interface{}) error {
 	m.lock.Lock()
 	defer m.lock.Unlock()
-	q := fmt.Sprint(append([]interface{}{query}, args...)...)
+	q := queryKey(query, args...)
 	m.count++
 	m.queries[q] = m.count
-	return nil
+	return m.errors[q]
+}
+
+// Select records the query in Reads and sets dest to the rows from SetResult for the query.
+// If there are no rows for the query - dest is left unchanged.
+func (m *FakeConnection) Select(_ context.Context, _ LogInfo, dest interface{}, query string, args ...interface{}) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	result, ok := m.results[m.read(query, args...)]
+	if !ok {
+		return nil
+	}
+	return assignResult(dest, result)
+}
+
+// Get records the query in Reads and sets dest to the row from SetResult for the query.
+// If there is no row for the query - sql.ErrNoRows is returned.
+func (m *FakeConnection) Get(_ context.Context, _ LogInfo, dest interface{}, query string, args ...interface{}) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	result, ok := m.results[m.read(query, args...)]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	return assignResult(dest, result)
+}
+
+// SetResult scripts result that will be returned by Select or Get for the query with args.
+func (m *FakeConnection) SetResult(result interface{}, query string, args ...interface{}) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.results == nil {
+		m.results = make(map[string]interface{})
+	}
+	m.results[queryKey(query, args...)] = result
+}
+
+// SetError scripts error, that will be returned by Exec for the query with args.
+func (m *FakeConnection) SetError(err error, query string, args ...interface{}) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.errors == nil {
+		m.errors = make(map[string]error)
+	}
+	m.errors[queryKey(query, args...)] = err
 }
 
 func (m *FakeConnection) Queries() map[string]int {
@@ -77,6 +142,25 @@ func (m *FakeConnection) Queries() map[string]int {
 	return m.queries
 }
 
+// Reads returns queries executed with Select or Get with the order of their last execution.
+// They are counted separately from Queries, so reads don't change order of executed statements.
+func (m *FakeConnection) Reads() map[string]int {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return m.reads
+}
+
+// read records the query in reads and returns its key, must be called with the lock held.
+func (m *FakeConnection) read(query string, args ...interface{}) string {
+	q := queryKey(query, args...)
+	if m.reads == nil {
+		m.reads = make(map[string]int)
+	}
+	m.readsCount++
+	m.reads[q] = m.readsCount
+	return q
+}
+
 func (m *FakeConnection) Connections() map[string]bool {
 	m.lock.RLock()
 	defer m.lock.RUnlock()
@@ -87,5 +171,83 @@ func (m *FakeConnection) ResetDB() {
 	m.lock.Lock()
 	defer m.lock.Unlock()
 	m.queries = make(map[string]int)
+	m.reads = make(map[string]int)
 	m.connections = make(map[string]bool)
+	m.results = make(map[string]interface{})
+	m.errors = make(map[string]error)
+	m.pingErr = nil
+}
+
+func queryKey(query string, args ...interface{}) string {
+	return fmt.Sprint(append([]interface{}{query}, args...)...)
+}
+
+// assignResult sets dest to the result. Result can be value assignable to dest
+// or result set as []map[string]interface{} for Select and map[string]interface{} for Get,
+// where map keys are column names, that are matched with "db" tags of struct fields.
+func assignResult(dest, result interface{}) error {
+	destValue := reflect.ValueOf(dest)
+	if destValue.Kind() != reflect.Pointer || destValue.IsNil() {
+		return fmt.Errorf("destination must be a non-nil pointer, got %T", dest)
+	}
+	destValue = destValue.Elem()
+
+	switch rows := result.(type) {
+	case []map[string]interface{}:
+		if destValue.Kind() != reflect.Slice {
+			return fmt.Errorf("can't assign rows to %T", dest)
+		}
+		slice := reflect.MakeSlice(destValue.Type(), len(rows), len(rows))
+		for i, row := range rows {
+			if err := scanRow(slice.Index(i), row); err != nil {
+				return err
+			}
+		}
+		destValue.Set(slice)
+		return nil
+
+	case map[string]interface{}:
+		return scanRow(destValue, rows)
+	}
+
+	resultValue := reflect.ValueOf(result)
+	if !resultValue.Type().AssignableTo(destValue.Type()) {
+		return fmt.Errorf("can't assign result of type %T to %T", result, dest)
+	}
+	destValue.Set(resultValue)
+	return nil
+}
+
+func scanRow(dest reflect.Value, row map[string]interface{}) error {
+	if dest.Kind() != reflect.Struct {
+		if len(row) != 1 {
+			return fmt.Errorf("scannable dest type %s with >1 columns (%d) in result", dest.Type(), len(row))
+		}
+		for _, value := range row {
+			return setValue(dest, value)
+		}
+	}
+
+	for i := 0; i < dest.NumField(); i++ {
+		field := dest.Type().Field(i)
+		column := field.Tag.Get("db")
+		if column == "" {
+			column = strings.ToLower(field.Name)
+		}
+		if value, ok := row[column]; ok {
+			if err := setValue(dest.Field(i), value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func setValue(dest reflect.Value, value interface{}) error {
+	v := reflect.ValueOf(value)
+	if !v.Type().ConvertibleTo(dest.Type()) {
+		return fmt.Errorf("can't convert %s to %s", v.Type(), dest.Type())
+	}
+	dest.Set(v.Convert(dest.Type()))
+	return nil
 }
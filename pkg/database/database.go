@@ -25,26 +25,78 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/alex123012/database-users-operator/api/v1alpha1"
+	"github.com/alex123012/database-users-operator/pkg/database/clickhouse"
 	"github.com/alex123012/database-users-operator/pkg/database/connection"
+	"github.com/alex123012/database-users-operator/pkg/database/mariadb"
+	"github.com/alex123012/database-users-operator/pkg/database/mongodb"
+	"github.com/alex123012/database-users-operator/pkg/database/mssql"
 	"github.com/alex123012/database-users-operator/pkg/database/mysql"
 	"github.com/alex123012/database-users-operator/pkg/database/postgresql"
+	"github.com/alex123012/database-users-operator/pkg/database/redis"
 	"github.com/alex123012/database-users-operator/pkg/utils"
 )
 
 type Database interface {
 	Close(cxt context.Context) error
+	// Ping checks, that the database is still reachable with the connection.
+	Ping(ctx context.Context) error
 	CreateUser(ctx context.Context, username, password string) (map[string]string, error)
 	DeleteUser(ctx context.Context, username string) error
+	CreateRole(ctx context.Context, name string) error
+	DeleteRole(ctx context.Context, name string) error
+	// ApplyRolePrivileges and RevokeRolePrivileges manage privileges of the role created by CreateRole,
+	// that could be addressed differently from the user with the same name.
+	ApplyRolePrivileges(ctx context.Context, name string, privileges []v1alpha1.PrivilegeSpec) error
+	RevokeRolePrivileges(ctx context.Context, name string, privileges []v1alpha1.PrivilegeSpec) error
+	SetPassword(ctx context.Context, username, password string) error
+	AlterUser(ctx context.Context, username, password string, options, applied v1alpha1.UserOptions) error
 	ApplyPrivileges(ctx context.Context, username string, privileges []v1alpha1.PrivilegeSpec) error
 	RevokePrivileges(ctx context.Context, username string, privileges []v1alpha1.PrivilegeSpec) error
+	GrantRoles(ctx context.Context, username string, roles []v1alpha1.RoleGrant) error
+	RevokeRoles(ctx context.Context, username string, roles []v1alpha1.RoleGrant) error
+	UserExists(ctx context.Context, username string) (bool, error)
+	ListPrivileges(ctx context.Context, username string) ([]v1alpha1.PrivilegeSpec, error)
+	ServerVersion(ctx context.Context) (string, error)
+	TLSEnabled(ctx context.Context) (bool, error)
+}
+
+// WithUsersHostnames returns Database, that manages MySQL and MariaDB users for provided host patterns
+// instead of the ones from the Database config, other databases are returned as is.
+// Returned Database shares connection with db, so only db must be closed.
+func WithUsersHostnames(db Database, hostnames []string) Database {
+	switch d := db.(type) {
+	case *cachedDatabase:
+		return WithUsersHostnames(d.Database, hostnames)
+	case *mysql.Mysql:
+		return d.WithUsersHostnames(hostnames)
+	case *mariadb.MariaDB:
+		return d.WithUsersHostnames(hostnames)
+	}
+	return db
+}
+
+// WithPrivileges returns Database, that drops SQL Server principals also from databases of provided privileges,
+// other databases are returned as is. Returned Database shares connection with db, so only db must be closed.
+func WithPrivileges(db Database, privileges []v1alpha1.PrivilegeSpec) Database {
+	switch d := db.(type) {
+	case *cachedDatabase:
+		return WithPrivileges(d.Database, privileges)
+	case *mssql.MSSQL:
+		return d.WithPrivileges(privileges)
+	}
+	return db
 }
 
 func NewDatabase(ctx context.Context, s v1alpha1.DatabaseSpec, client client.Client, logger logr.Logger) (Database, error) {
 	conn := connection.NewDefaultConnector(logger)
-	return newDatabase(ctx, conn, s, client, logger)
+	mongoClient := mongodb.NewDefaultClient(logger)
+	redisClient := redis.NewDefaultClient(logger)
+	return newDatabase(ctx, conn, mongoClient, redisClient, s, client, logger)
 }
 
-func newDatabase(ctx context.Context, conn connection.Connection, s v1alpha1.DatabaseSpec, client client.Client, logger logr.Logger) (Database, error) {
+// newDatabase creates Database for the spec, SQL databases use conn, MongoDB uses mongoClient and Redis uses redisClient.
+func newDatabase(ctx context.Context, conn connection.Connection, mongoClient mongodb.Client, redisClient redis.Client,
+	s v1alpha1.DatabaseSpec, client client.Client, logger logr.Logger) (Database, error) {
 	var db Database
 	var err error
 	switch s.Type {
@@ -52,6 +104,16 @@ func newDatabase(ctx context.Context, conn connection.Connection, s v1alpha1.Dat
 		db, err = newPostgresql(ctx, conn, s.PostgreSQL, client, logger)
 	case v1alpha1.MySQL:
 		db, err = newMysql(ctx, conn, s.MySQL, client, logger)
+	case v1alpha1.MariaDB:
+		db, err = newMariaDB(ctx, conn, s.MariaDB, client, logger)
+	case v1alpha1.MSSQL:
+		db, err = newMSSQL(ctx, conn, s.MSSQL, client, logger)
+	case v1alpha1.ClickHouse:
+		db, err = newClickHouse(ctx, conn, s.ClickHouse, client, logger)
+	case v1alpha1.MongoDB:
+		db, err = newMongoDB(ctx, mongoClient, s.MongoDB, client, logger)
+	case v1alpha1.Redis:
+		db, err = newRedis(ctx, redisClient, s.Redis, client, logger)
 	default:
 		err = fmt.Errorf("can't find supported DB type '%s'", s.Type)
 	}
@@ -91,11 +153,61 @@ func newMysql(ctx context.Context, conn connection.Connection, c *v1alpha1.MySQL
 	if err != nil {
 		return nil, err
 	}
-	cfg := mysql.NewConfig(c.Host, c.Port, c.User, password, c.DatabaseName, c.UsersHostname)
+	cfg := mysql.NewConfig(c.Host, c.Port, c.User, password, c.DatabaseName, c.Hostnames())
 	m := mysql.NewMysql(conn, cfg, logger)
 	return m, m.Connect(ctx)
 }
 
+func newMariaDB(ctx context.Context, conn connection.Connection, c *v1alpha1.MariaDBConfig, client client.Client, logger logr.Logger) (*mariadb.MariaDB, error) {
+	password, err := passwordFromSecret(ctx, client, c.PasswordSecret)
+	if err != nil {
+		return nil, err
+	}
+	cfg := mysql.NewConfig(c.Host, c.Port, c.User, password, c.DatabaseName, c.Hostnames())
+	m := mariadb.NewMariaDB(conn, cfg, logger)
+	return m, m.Connect(ctx)
+}
+
+func newMSSQL(ctx context.Context, conn connection.Connection, c *v1alpha1.MSSQLConfig, client client.Client, logger logr.Logger) (*mssql.MSSQL, error) {
+	password, err := passwordFromSecret(ctx, client, c.PasswordSecret)
+	if err != nil {
+		return nil, err
+	}
+	cfg := mssql.NewConfig(c.Host, c.Port, c.User, password, c.DatabaseName, c.Databases())
+	m := mssql.NewMSSQL(conn, cfg, logger)
+	return m, m.Connect(ctx)
+}
+
+func newClickHouse(ctx context.Context, conn connection.Connection, c *v1alpha1.ClickHouseConfig, client client.Client, logger logr.Logger) (*clickhouse.ClickHouse, error) {
+	password, err := passwordFromSecret(ctx, client, c.PasswordSecret)
+	if err != nil {
+		return nil, err
+	}
+	cfg := clickhouse.NewConfig(c.Host, c.Port, c.User, password, c.DatabaseName, c.Protocol, c.Secure)
+	ch := clickhouse.NewClickHouse(conn, cfg, logger)
+	return ch, ch.Connect(ctx)
+}
+
+func newMongoDB(ctx context.Context, mongoClient mongodb.Client, c *v1alpha1.MongoDBConfig, client client.Client, logger logr.Logger) (*mongodb.MongoDB, error) {
+	password, err := passwordFromSecret(ctx, client, c.PasswordSecret)
+	if err != nil {
+		return nil, err
+	}
+	cfg := mongodb.NewConfig(c.Host, c.Port, c.User, password, c.AuthDatabase, c.UsersDB(), c.Secure)
+	m := mongodb.NewMongoDB(mongoClient, cfg, logger)
+	return m, m.Connect(ctx)
+}
+
+func newRedis(ctx context.Context, redisClient redis.Client, c *v1alpha1.RedisConfig, client client.Client, logger logr.Logger) (*redis.Redis, error) {
+	password, err := passwordFromSecret(ctx, client, c.PasswordSecret)
+	if err != nil {
+		return nil, err
+	}
+	cfg := redis.NewConfig(c.Host, c.Port, c.User, password, c.Secure)
+	r := redis.NewRedis(redisClient, cfg, logger)
+	return r, r.Connect(ctx)
+}
+
 func passwordFromSecret(ctx context.Context, client client.Client, secretNN v1alpha1.Secret) (string, error) {
 	var password string
 	if secretNN.Key != "" && secretNN.Secret.Name != "" && secretNN.Secret.Namespace != "" {
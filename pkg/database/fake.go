@@ -24,27 +24,36 @@ import (
 
 	"github.com/alex123012/database-users-operator/api/v1alpha1"
 	"github.com/alex123012/database-users-operator/pkg/database/connection"
+	"github.com/alex123012/database-users-operator/pkg/database/mongodb"
+	"github.com/alex123012/database-users-operator/pkg/database/redis"
 )
 
 type fakeConnection interface {
 	connection.Connection
 	Queries() map[string]int
 	Connections() map[string]bool
+	SetResult(result interface{}, query string, args ...interface{})
+	SetError(err error, query string, args ...interface{})
+	SetPingError(err error)
 	ResetDB()
 }
 
 type FakeDatabase struct {
-	Conn fakeConnection
+	Conn  fakeConnection
+	Mongo *mongodb.FakeClient
+	Redis *redis.FakeClient
 }
 
 func NewFakeDatabase() *FakeDatabase {
 	return &FakeDatabase{
-		Conn: connection.NewFakeConnection(),
+		Conn:  connection.NewFakeConnection(),
+		Mongo: mongodb.NewFakeClient(),
+		Redis: redis.NewFakeClient(),
 	}
 }
 
-func (f *FakeDatabase) DatabaseCreatorFunc() func(context.Context, v1alpha1.DatabaseSpec, client.Client, logr.Logger) (Database, error) {
+func (f *FakeDatabase) DatabaseCreatorFunc() Creator {
 	return func(ctx context.Context, s v1alpha1.DatabaseSpec, client client.Client, logger logr.Logger) (Database, error) {
-		return newDatabase(ctx, f.Conn, s, client, logger)
+		return newDatabase(ctx, f.Conn, f.Mongo, f.Redis, s, client, logger)
 	}
 }
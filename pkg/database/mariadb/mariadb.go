@@ -0,0 +1,208 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mariadb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/go-logr/logr"
+
+	"github.com/alex123012/database-users-operator/api/v1alpha1"
+	"github.com/alex123012/database-users-operator/pkg/database/connection"
+	"github.com/alex123012/database-users-operator/pkg/database/mysql"
+)
+
+const (
+	nativePasswordPlugin = "mysql_native_password"
+	ed25519Plugin        = "ed25519"
+	unixSocketPlugin     = "unix_socket"
+)
+
+var (
+	ErrUnsupportedAuthPlugin = errors.New("unsupported authentication plugin")
+	ErrMultipleDefaultRoles  = errors.New("only one default role is allowed")
+)
+
+// MariaDB reuses MySQL implementation and overrides statements, that differ in MariaDB:
+// user creation, authentication plugins, default roles and roles without host.
+type MariaDB struct {
+	*mysql.Mysql
+	db connection.Connection
+}
+
+func NewMariaDB(conn connection.Connection, config *mysql.Config, logger logr.Logger) *MariaDB {
+	return &MariaDB{
+		Mysql: mysql.NewMysql(conn, config, logger),
+		db:    conn,
+	}
+}
+
+// WithUsersHostnames returns copy of MariaDB, that manages users for provided host patterns
+// instead of the ones from the Config. The copy shares connection with m.
+func (m *MariaDB) WithUsersHostnames(hostnames []string) *MariaDB {
+	return &MariaDB{
+		Mysql: m.Mysql.WithUsersHostnames(hostnames),
+		db:    m.db,
+	}
+}
+
+// CreateUser creates the user for every host pattern.
+// Password is set for the hosts, for which the user already exists, so all of them share the same password.
+// Missing users are created with "CREATE OR REPLACE USER", so concurrently created user doesn't fail creation.
+func (m *MariaDB) CreateUser(ctx context.Context, username, password string) (map[string]string, error) {
+	existing, err := m.ExistingHostnames(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, hostname := range m.UsersHostnames() {
+		query := "CREATE OR REPLACE USER ?@? IDENTIFIED BY ?"
+		if slices.Contains(existing, hostname) {
+			query = "ALTER USER ?@? IDENTIFIED BY ?"
+		}
+		if err := m.db.Exec(ctx, connection.DisableLogger, query, username, hostname, password); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// AlterUser applies account options to the user. Options, that are not set, are reset to MariaDB defaults.
+// Authentication plugin, that requires password, is changed only if password is provided.
+func (m *MariaDB) AlterUser(ctx context.Context, username, password string, options, _ v1alpha1.UserOptions) error {
+	mariadbOptions := options.MariaDB
+	if mariadbOptions == nil {
+		mariadbOptions = &v1alpha1.MariaDBUserOptions{}
+	}
+
+	auth, authArgs, err := identifiedVia(mariadbOptions.AuthPlugin, password)
+	if err != nil {
+		return err
+	}
+
+	for _, account := range m.UserAccounts(username) {
+		query, args := mysql.AlterUserQuery(account, auth, authArgs, mariadbOptions.MySQLAccountOptions)
+		if err := m.db.Exec(ctx, connection.DisableLogger, query, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateRole creates the role with the operator user as its admin, so it can grant the role to users.
+func (m *MariaDB) CreateRole(ctx context.Context, name string) error {
+	query := "CREATE ROLE IF NOT EXISTS ? WITH ADMIN CURRENT_USER"
+	return m.db.Exec(ctx, connection.EnableLogger, query, name)
+}
+
+// ApplyRolePrivileges grants privileges to the role created by CreateRole.
+// Roles in MariaDB don't have host, so privileges are granted to "<role>".
+func (m *MariaDB) ApplyRolePrivileges(ctx context.Context, name string, privileges []v1alpha1.PrivilegeSpec) error {
+	return m.ApplyAccountsPrivileges(ctx, roleAccounts(name), privileges)
+}
+
+func (m *MariaDB) RevokeRolePrivileges(ctx context.Context, name string, privileges []v1alpha1.PrivilegeSpec) error {
+	return m.RevokeAccountsPrivileges(ctx, roleAccounts(name), privileges)
+}
+
+// GrantRoles grants roles to the user and sets the role marked as default as user's default role.
+// MariaDB allows only one default role, so roles with several default ones are rejected.
+func (m *MariaDB) GrantRoles(ctx context.Context, username string, roles []v1alpha1.RoleGrant) error {
+	var defaultRoles []string
+	for _, role := range roles {
+		if role.Default {
+			defaultRoles = append(defaultRoles, role.Name)
+		}
+	}
+	if len(defaultRoles) > 1 {
+		return fmt.Errorf("%w: %s", ErrMultipleDefaultRoles, strings.Join(defaultRoles, ", "))
+	}
+
+	for _, hostname := range m.UsersHostnames() {
+		for _, role := range roles {
+			query := "GRANT ? TO ?@?"
+			if role.AdminOption {
+				query += " WITH ADMIN OPTION"
+			}
+			if err := m.db.Exec(ctx, connection.EnableLogger, query, role.Name, username, hostname); err != nil {
+				return err
+			}
+		}
+
+		if len(defaultRoles) > 0 {
+			query := "SET DEFAULT ROLE ? FOR ?@?"
+			if err := m.db.Exec(ctx, connection.EnableLogger, query, defaultRoles[0], username, hostname); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// RevokeRoles revokes roles from the user.
+// MariaDB keeps revoked default role, so it is reset, if one of revoked roles was default.
+func (m *MariaDB) RevokeRoles(ctx context.Context, username string, roles []v1alpha1.RoleGrant) error {
+	if err := m.Mysql.RevokeRoles(ctx, username, roles); err != nil {
+		return err
+	}
+
+	if !slices.ContainsFunc(roles, func(role v1alpha1.RoleGrant) bool { return role.Default }) {
+		return nil
+	}
+	for _, hostname := range m.UsersHostnames() {
+		query := "SET DEFAULT ROLE NONE FOR ?@?"
+		if err := m.db.Exec(ctx, connection.EnableLogger, query, username, hostname); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TLSEnabled checks session cipher in information_schema, because performance_schema is disabled in MariaDB by default.
+func (m *MariaDB) TLSEnabled(ctx context.Context) (bool, error) {
+	var cipher string
+	query := "SELECT VARIABLE_VALUE FROM information_schema.SESSION_STATUS WHERE VARIABLE_NAME = 'SSL_CIPHER'"
+	if err := m.db.Get(ctx, connection.EnableLogger, &cipher, query); err != nil {
+		return false, err
+	}
+	return cipher != "", nil
+}
+
+// roleAccounts returns the role ("?"), roles in MariaDB don't have host.
+func roleAccounts(name string) []mysql.Account {
+	return []mysql.Account{{Placeholder: "?", Args: []interface{}{name}}}
+}
+
+// identifiedVia returns "IDENTIFIED VIA" clause of ALTER USER statement for the authentication plugin.
+func identifiedVia(plugin, password string) (string, []interface{}, error) {
+	switch plugin {
+	case "":
+	case unixSocketPlugin:
+		return " IDENTIFIED VIA " + unixSocketPlugin, nil, nil
+	case nativePasswordPlugin, ed25519Plugin:
+		if password != "" {
+			return " IDENTIFIED VIA " + plugin + " USING PASSWORD(?)", []interface{}{password}, nil
+		}
+	default:
+		return "", nil, fmt.Errorf("%w: %s", ErrUnsupportedAuthPlugin, plugin)
+	}
+	return "", nil, nil
+}
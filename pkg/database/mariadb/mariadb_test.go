@@ -0,0 +1,277 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mariadb_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/go-logr/logr"
+
+	"github.com/alex123012/database-users-operator/api/v1alpha1"
+	"github.com/alex123012/database-users-operator/pkg/database/connection"
+	"github.com/alex123012/database-users-operator/pkg/database/mariadb"
+	"github.com/alex123012/database-users-operator/pkg/database/mysql"
+	testsutils "github.com/alex123012/database-users-operator/pkg/utils/tests_utils"
+)
+
+func newMariaDB(t *testing.T, mockDB connection.Connection, usersHostnames []string) *mariadb.MariaDB {
+	m := mariadb.NewMariaDB(mockDB, mysql.NewConfig("mariadb", 3306, "user", "password", "dbname", usersHostnames), logr.Discard())
+	if err := m.Connect(context.Background()); err != nil {
+		t.Fatalf("MariaDB.Connect() error = %v", err)
+	}
+	return m
+}
+
+// Grants and other statements, that are the same in MySQL and MariaDB, are tested in the mysql package.
+
+func TestMariaDB_CreateUser(t *testing.T) {
+	ctx := context.Background()
+	mockDB := connection.NewFakeConnection()
+	m := newMariaDB(t, mockDB, []string{"10.0.%", "localhost"})
+	defer m.Close(ctx)
+
+	// User for one of host patterns already exists, so only its password is changed and its grants are kept.
+	mockDB.SetResult([]string{"localhost"}, "SELECT host FROM mysql.user WHERE user = ?", "john")
+	if _, err := m.CreateUser(ctx, "john", "mysupersecretpass"); err != nil {
+		t.Fatalf("MariaDB.CreateUser() error = %v", err)
+	}
+
+	testsutils.CheckQueries(t, []string{
+		fmt.Sprint("CREATE OR REPLACE USER ?@? IDENTIFIED BY ?", "john", "10.0.%", "mysupersecretpass"),
+		fmt.Sprint("ALTER USER ?@? IDENTIFIED BY ?", "john", "localhost", "mysupersecretpass"),
+	}, mockDB.Queries())
+}
+
+func TestMariaDB_DefaultRole(t *testing.T) {
+	tests := []struct {
+		name    string
+		grant   []v1alpha1.RoleGrant
+		revoke  []v1alpha1.RoleGrant
+		want    []string
+		wantErr error
+	}{
+		{
+			name:  "Single default role is set for every host",
+			grant: []v1alpha1.RoleGrant{{Name: "readers", Default: true}, {Name: "writers", AdminOption: true}},
+			want: []string{
+				fmt.Sprint("GRANT ? TO ?@?", "readers", "john", "10.0.%"),
+				fmt.Sprint("GRANT ? TO ?@? WITH ADMIN OPTION", "writers", "john", "10.0.%"),
+				fmt.Sprint("SET DEFAULT ROLE ? FOR ?@?", "readers", "john", "10.0.%"),
+				fmt.Sprint("GRANT ? TO ?@?", "readers", "john", "localhost"),
+				fmt.Sprint("GRANT ? TO ?@? WITH ADMIN OPTION", "writers", "john", "localhost"),
+				fmt.Sprint("SET DEFAULT ROLE ? FOR ?@?", "readers", "john", "localhost"),
+			},
+		},
+		{
+			name:    "Several default roles are rejected before any grant",
+			grant:   []v1alpha1.RoleGrant{{Name: "readers", Default: true}, {Name: "auditors", Default: true}},
+			wantErr: mariadb.ErrMultipleDefaultRoles,
+		},
+		{
+			name:   "Revoked default role is reset",
+			revoke: []v1alpha1.RoleGrant{{Name: "readers", Default: true}},
+			want: []string{
+				fmt.Sprint("REVOKE ? FROM ?@?", "readers", "john", "10.0.%"),
+				fmt.Sprint("REVOKE ? FROM ?@?", "readers", "john", "localhost"),
+				fmt.Sprint("SET DEFAULT ROLE NONE FOR ?@?", "john", "10.0.%"),
+				fmt.Sprint("SET DEFAULT ROLE NONE FOR ?@?", "john", "localhost"),
+			},
+		},
+		{
+			name:   "Default role is kept, when other roles are revoked",
+			revoke: []v1alpha1.RoleGrant{{Name: "writers"}},
+			want: []string{
+				fmt.Sprint("REVOKE ? FROM ?@?", "writers", "john", "10.0.%"),
+				fmt.Sprint("REVOKE ? FROM ?@?", "writers", "john", "localhost"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			mockDB := connection.NewFakeConnection()
+			m := newMariaDB(t, mockDB, []string{"10.0.%", "localhost"})
+			defer m.Close(ctx)
+
+			if len(tt.grant) > 0 {
+				if err := m.GrantRoles(ctx, "john", tt.grant); !errors.Is(err, tt.wantErr) {
+					t.Errorf("MariaDB.GrantRoles() error = %v, want %v", err, tt.wantErr)
+				}
+			}
+			if len(tt.revoke) > 0 {
+				if err := m.RevokeRoles(ctx, "john", tt.revoke); err != nil {
+					t.Errorf("MariaDB.RevokeRoles() error = %v", err)
+				}
+			}
+			testsutils.CheckQueries(t, tt.want, mockDB.Queries())
+		})
+	}
+}
+
+func TestMariaDB_RolesWithoutHost(t *testing.T) {
+	ctx := context.Background()
+	mockDB := connection.NewFakeConnection()
+	m := newMariaDB(t, mockDB, []string{"10.0.%", "localhost"})
+	defer m.Close(ctx)
+
+	privileges := []v1alpha1.PrivilegeSpec{
+		{ObjectType: v1alpha1.ObjectDatabase, Database: "dat", Privileges: []v1alpha1.PrivilegeType{v1alpha1.SELECT}},
+		{Privilege: "readers"},
+	}
+	if err := m.CreateRole(ctx, "app_readonly"); err != nil {
+		t.Errorf("MariaDB.CreateRole() error = %v", err)
+	}
+	if err := m.ApplyRolePrivileges(ctx, "app_readonly", privileges); err != nil {
+		t.Errorf("MariaDB.ApplyRolePrivileges() error = %v", err)
+	}
+	if err := m.RevokeRolePrivileges(ctx, "app_readonly", privileges); err != nil {
+		t.Errorf("MariaDB.RevokeRolePrivileges() error = %v", err)
+	}
+
+	// Role is granted once, not for every users host pattern.
+	testsutils.CheckQueries(t, []string{
+		fmt.Sprint("CREATE ROLE IF NOT EXISTS ? WITH ADMIN CURRENT_USER", "app_readonly"),
+		fmt.Sprint("GRANT SELECT ON `dat`.* TO ?", "app_readonly"),
+		fmt.Sprint("GRANT ? TO ?", "readers", "app_readonly"),
+		fmt.Sprint("REVOKE SELECT ON `dat`.* FROM ?", "app_readonly"),
+		fmt.Sprint("REVOKE ? FROM ?", "readers", "app_readonly"),
+	}, mockDB.Queries())
+}
+
+func TestMariaDB_AlterUser(t *testing.T) {
+	expireDays := int32(90)
+
+	tests := []struct {
+		name     string
+		password string
+		options  v1alpha1.UserOptions
+		want     string
+		wantErr  bool
+	}{
+		{
+			name: "Reset to defaults, MySQL options are ignored",
+			options: v1alpha1.UserOptions{MySQL: &v1alpha1.MySQLUserOptions{
+				MySQLAccountOptions: v1alpha1.MySQLAccountOptions{AccountLocked: true},
+			}},
+			want: fmt.Sprint("ALTER USER ?@? REQUIRE NONE WITH MAX_QUERIES_PER_HOUR ? MAX_UPDATES_PER_HOUR ? MAX_CONNECTIONS_PER_HOUR ? MAX_USER_CONNECTIONS ? PASSWORD EXPIRE DEFAULT ACCOUNT UNLOCK",
+				"john", "%", int32(0), int32(0), int32(0), int32(0)),
+		},
+		{
+			name:     "Set plugin, TLS and limits",
+			password: "secret",
+			options: v1alpha1.UserOptions{MariaDB: &v1alpha1.MariaDBUserOptions{
+				AuthPlugin: "ed25519",
+				MySQLAccountOptions: v1alpha1.MySQLAccountOptions{
+					RequireTLS:         "SSL",
+					MaxUpdatesPerHour:  10,
+					PasswordExpireDays: &expireDays,
+					AccountLocked:      true,
+				},
+			}},
+			want: fmt.Sprint("ALTER USER ?@? IDENTIFIED VIA ed25519 USING PASSWORD(?) REQUIRE SSL WITH MAX_QUERIES_PER_HOUR ? MAX_UPDATES_PER_HOUR ? MAX_CONNECTIONS_PER_HOUR ? MAX_USER_CONNECTIONS ? PASSWORD EXPIRE INTERVAL ? DAY ACCOUNT LOCK",
+				"john", "%", "secret", int32(0), int32(10), int32(0), int32(0), int32(90)),
+		},
+		{
+			name: "Keep plugin without password",
+			options: v1alpha1.UserOptions{MariaDB: &v1alpha1.MariaDBUserOptions{
+				AuthPlugin:          "mysql_native_password",
+				MySQLAccountOptions: v1alpha1.MySQLAccountOptions{RequireSubject: "/CN=john"},
+			}},
+			want: fmt.Sprint("ALTER USER ?@? REQUIRE SUBJECT ? WITH MAX_QUERIES_PER_HOUR ? MAX_UPDATES_PER_HOUR ? MAX_CONNECTIONS_PER_HOUR ? MAX_USER_CONNECTIONS ? PASSWORD EXPIRE DEFAULT ACCOUNT UNLOCK",
+				"john", "%", "/CN=john", int32(0), int32(0), int32(0), int32(0)),
+		},
+		{
+			name:    "Socket authentication",
+			options: v1alpha1.UserOptions{MariaDB: &v1alpha1.MariaDBUserOptions{AuthPlugin: "unix_socket"}},
+			want: fmt.Sprint("ALTER USER ?@? IDENTIFIED VIA unix_socket REQUIRE NONE WITH MAX_QUERIES_PER_HOUR ? MAX_UPDATES_PER_HOUR ? MAX_CONNECTIONS_PER_HOUR ? MAX_USER_CONNECTIONS ? PASSWORD EXPIRE DEFAULT ACCOUNT UNLOCK",
+				"john", "%", int32(0), int32(0), int32(0), int32(0)),
+		},
+		{
+			name:    "Unsupported plugin",
+			options: v1alpha1.UserOptions{MariaDB: &v1alpha1.MariaDBUserOptions{AuthPlugin: "caching_sha2_password"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			mockDB := connection.NewFakeConnection()
+			m := newMariaDB(t, mockDB, nil)
+			defer m.Close(ctx)
+
+			if err := m.AlterUser(ctx, "john", tt.password, tt.options, v1alpha1.UserOptions{}); (err != nil) != tt.wantErr {
+				t.Fatalf("MariaDB.AlterUser() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			queries := mockDB.Queries()
+			if tt.wantErr {
+				if len(queries) != 0 {
+					t.Errorf("MariaDB.AlterUser() queries = %v, want none", queries)
+				}
+				return
+			}
+			if len(queries) != 1 || queries[tt.want] != 1 {
+				t.Errorf("MariaDB.AlterUser() queries = %v, want %s", queries, tt.want)
+			}
+		})
+	}
+}
+
+func TestMariaDB_Introspection(t *testing.T) {
+	ctx := context.Background()
+	mockDB := connection.NewFakeConnection()
+	m := newMariaDB(t, mockDB, nil)
+	defer m.Close(ctx)
+
+	// MariaDB adds password hash to the USAGE grant and reports default role as a separate row.
+	mockDB.SetResult([]string{
+		"GRANT USAGE ON *.* TO `john`@`%` IDENTIFIED BY PASSWORD '*6C8989366EAF75BB670AD8EA7A7FC1176A95CEF4'",
+		"GRANT SELECT, INSERT ON `dat`.* TO `john`@`%`",
+		"GRANT `readers` TO `john`@`%`",
+		"SET DEFAULT ROLE `readers` FOR `john`@`%`",
+	}, "SHOW GRANTS FOR ?@?", "john", "%")
+
+	privileges, err := m.ListPrivileges(ctx, "john")
+	if err != nil {
+		t.Fatalf("MariaDB.ListPrivileges() error = %v", err)
+	}
+	want := []v1alpha1.PrivilegeSpec{
+		{Privilege: "SELECT", Database: "dat"},
+		{Privilege: "INSERT", Database: "dat"},
+		{Privilege: "readers"},
+	}
+	if !reflect.DeepEqual(privileges, want) {
+		t.Errorf("MariaDB.ListPrivileges() = %v, want %v", privileges, want)
+	}
+
+	// performance_schema is disabled in MariaDB by default, so cipher is read from information_schema.
+	cipherQuery := "SELECT VARIABLE_VALUE FROM information_schema.SESSION_STATUS WHERE VARIABLE_NAME = 'SSL_CIPHER'"
+	mockDB.SetResult("", cipherQuery)
+	if tls, err := m.TLSEnabled(ctx); err != nil || tls {
+		t.Errorf("MariaDB.TLSEnabled() = %v, %v, want false, nil", tls, err)
+	}
+	mockDB.SetResult("TLS_AES_256_GCM_SHA384", cipherQuery)
+	if tls, err := m.TLSEnabled(ctx); err != nil || !tls {
+		t.Errorf("MariaDB.TLSEnabled() = %v, %v, want true, nil", tls, err)
+	}
+}
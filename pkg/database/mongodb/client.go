@@ -0,0 +1,75 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Client runs MongoDB database commands. It is used by MongoDB instead of SQL connection.Connection.
+type Client interface {
+	Connect(ctx context.Context, uri string) error
+	Close(ctx context.Context) error
+	// RunCommand runs the command in the database and decodes the reply to result, if it is not nil.
+	RunCommand(ctx context.Context, dbname string, command bson.D, result interface{}) error
+}
+
+type DefaultClient struct {
+	client *mongo.Client
+	logger logr.Logger
+}
+
+func NewDefaultClient(logger logr.Logger) *DefaultClient {
+	return &DefaultClient{
+		logger: logger,
+	}
+}
+
+func (d *DefaultClient) Connect(ctx context.Context, uri string) error {
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		return err
+	}
+	if err := client.Ping(ctx, nil); err != nil {
+		_ = client.Disconnect(ctx)
+		return err
+	}
+	d.client = client
+	return nil
+}
+
+func (d *DefaultClient) Close(ctx context.Context) error {
+	return d.client.Disconnect(ctx)
+}
+
+// RunCommand logs only name of the command, because arguments can contain passwords.
+func (d *DefaultClient) RunCommand(ctx context.Context, dbname string, command bson.D, result interface{}) error {
+	if len(command) > 0 {
+		d.logger.Info(fmt.Sprintf("Running command '%s' in database '%s'", command[0].Key, dbname))
+	}
+	reply := d.client.Database(dbname).RunCommand(ctx, command)
+	if result == nil {
+		return reply.Err()
+	}
+	return reply.Decode(result)
+}
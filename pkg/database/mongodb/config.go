@@ -0,0 +1,79 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mongodb
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/alex123012/database-users-operator/api/v1alpha1"
+)
+
+type Config struct {
+	Host          string
+	Port          int
+	User          string
+	Password      string
+	AuthDatabase  string
+	UsersDatabase string
+	Secure        bool
+}
+
+func NewConfig(host string, port int, user, pass, authDatabase, usersDatabase string, secure bool) *Config {
+	return &Config{
+		Host:          host,
+		User:          user,
+		Password:      pass,
+		Port:          port,
+		AuthDatabase:  authDatabase,
+		UsersDatabase: usersDatabase,
+		Secure:        secure,
+	}
+}
+
+// ConnString returns connection string URI for MongoDB.
+func (c *Config) ConnString() string {
+	query := url.Values{}
+	if c.AuthDatabase != "" {
+		query.Set("authSource", c.AuthDatabase)
+	}
+	if c.Secure {
+		query.Set("tls", "true")
+	}
+
+	u := url.URL{
+		Scheme:   "mongodb",
+		Host:     fmt.Sprintf("%s:%d", c.Host, c.Port),
+		Path:     "/",
+		RawQuery: query.Encode(),
+	}
+	switch {
+	case c.Password != "":
+		u.User = url.UserPassword(c.User, c.Password)
+	case c.User != "":
+		u.User = url.User(c.User)
+	}
+	return u.String()
+}
+
+// UsersDB returns the database, where users and roles are created, defaults to "admin".
+func (c *Config) UsersDB() string {
+	if c.UsersDatabase == "" {
+		return v1alpha1.MongoDBDefaultUsersDatabase
+	}
+	return c.UsersDatabase
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// FakeClient records commands and returns results scripted with SetResult and errors scripted with SetError.
+type FakeClient struct {
+	commands    map[string]int
+	count       int
+	connections map[string]bool
+	results     map[string]interface{}
+	errors      map[string]error
+	lock        *sync.RWMutex
+}
+
+func NewFakeClient() *FakeClient {
+	return &FakeClient{
+		commands:    make(map[string]int),
+		connections: make(map[string]bool),
+		results:     make(map[string]interface{}),
+		errors:      make(map[string]error),
+		lock:        &sync.RWMutex{},
+	}
+}
+
+func (f *FakeClient) Connect(_ context.Context, uri string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.connections[uri] = true
+	return nil
+}
+
+func (f *FakeClient) Close(_ context.Context) error {
+	return nil
+}
+
+// RunCommand records the command, if its reply isn't decoded (like FakeConnection.Exec),
+// otherwise decodes result from SetResult for it to result.
+// If there is no result for the command - result is left unchanged.
+func (f *FakeClient) RunCommand(_ context.Context, dbname string, command bson.D, result interface{}) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	key := CommandKey(dbname, command)
+	if result == nil {
+		f.count++
+		f.commands[key] = f.count
+		return f.errors[key]
+	}
+
+	scripted, ok := f.results[key]
+	if !ok {
+		return nil
+	}
+	data, err := bson.Marshal(scripted)
+	if err != nil {
+		return err
+	}
+	return bson.Unmarshal(data, result)
+}
+
+// SetResult scripts reply, that will be returned for the command in the database.
+func (f *FakeClient) SetResult(result interface{}, dbname string, command bson.D) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.results[CommandKey(dbname, command)] = result
+}
+
+// SetError scripts error, that will be returned for the command in the database.
+func (f *FakeClient) SetError(err error, dbname string, command bson.D) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.errors[CommandKey(dbname, command)] = err
+}
+
+func (f *FakeClient) Commands() map[string]int {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	return f.commands
+}
+
+func (f *FakeClient) Connections() map[string]bool {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	return f.connections
+}
+
+func (f *FakeClient) ResetDB() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.commands = make(map[string]int)
+	f.connections = make(map[string]bool)
+	f.results = make(map[string]interface{})
+	f.errors = make(map[string]error)
+	f.count = 0
+}
+
+// CommandKey returns the database and the command in relaxed extended JSON, that are used as keys of Commands.
+func CommandKey(dbname string, command bson.D) string {
+	data, err := bson.MarshalExtJSON(command, false, false)
+	if err != nil {
+		return fmt.Sprintf("%s: %v", dbname, command)
+	}
+	return fmt.Sprintf("%s: %s", dbname, data)
+}
@@ -0,0 +1,286 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/alex123012/database-users-operator/api/v1alpha1"
+)
+
+// Error codes of not existing user and role, that are ignored on deletion.
+const (
+	userNotFoundCode = 11
+	roleNotFoundCode = 31
+)
+
+// roleRef is a role in the database, that is granted to the user or the role.
+type roleRef struct {
+	Role string `bson:"role"`
+	DB   string `bson:"db"`
+}
+
+type userInfo struct {
+	User  string    `bson:"user"`
+	DB    string    `bson:"db"`
+	Roles []roleRef `bson:"roles"`
+}
+
+type usersInfoReply struct {
+	Users []userInfo `bson:"users"`
+}
+
+type rolesInfoReply struct {
+	Roles []roleRef `bson:"roles"`
+}
+
+type buildInfoReply struct {
+	Version string `bson:"version"`
+}
+
+// MongoDB manages users with user management commands (createUser, grantRolesToUser, dropUser etc.),
+// PrivilegeSpec is mapped to the role ("privilege") in the database ("database").
+type MongoDB struct {
+	client Client
+	config *Config
+	logger logr.Logger
+}
+
+func NewMongoDB(client Client, config *Config, logger logr.Logger) *MongoDB {
+	return &MongoDB{
+		client: client,
+		logger: logger,
+		config: config,
+	}
+}
+
+func (m *MongoDB) Connect(ctx context.Context) error {
+	return m.client.Connect(ctx, m.config.ConnString())
+}
+
+func (m *MongoDB) Close(ctx context.Context) error {
+	return m.client.Close(ctx)
+}
+
+func (m *MongoDB) Ping(ctx context.Context) error {
+	var reply bson.M
+	command := bson.D{{Key: "ping", Value: 1}}
+	return m.client.RunCommand(ctx, v1alpha1.MongoDBDefaultUsersDatabase, command, &reply)
+}
+
+// CreateUser creates the user without roles or sets the password, if the user already exists.
+func (m *MongoDB) CreateUser(ctx context.Context, username, password string) (map[string]string, error) {
+	exists, err := m.UserExists(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, m.SetPassword(ctx, username, password)
+	}
+
+	command := bson.D{{Key: "createUser", Value: username}, {Key: "pwd", Value: password}, {Key: "roles", Value: bson.A{}}}
+	return nil, m.client.RunCommand(ctx, m.config.UsersDB(), command, nil)
+}
+
+func (m *MongoDB) SetPassword(ctx context.Context, username, password string) error {
+	command := bson.D{{Key: "updateUser", Value: username}, {Key: "pwd", Value: password}}
+	return m.client.RunCommand(ctx, m.config.UsersDB(), command, nil)
+}
+
+// AlterUser does nothing, MongoDB has no user options yet.
+func (m *MongoDB) AlterUser(_ context.Context, _, _ string, _, _ v1alpha1.UserOptions) error {
+	return nil
+}
+
+// DeleteUser drops the user, not existing user is skipped.
+func (m *MongoDB) DeleteUser(ctx context.Context, username string) error {
+	command := bson.D{{Key: "dropUser", Value: username}}
+	return ignoreCode(m.client.RunCommand(ctx, m.config.UsersDB(), command, nil), userNotFoundCode)
+}
+
+// CreateRole creates the role without privileges and roles, if it doesn't exist.
+func (m *MongoDB) CreateRole(ctx context.Context, name string) error {
+	exists, err := m.roleExists(ctx, name)
+	if err != nil || exists {
+		return err
+	}
+
+	command := bson.D{{Key: "createRole", Value: name}, {Key: "privileges", Value: bson.A{}}, {Key: "roles", Value: bson.A{}}}
+	return m.client.RunCommand(ctx, m.config.UsersDB(), command, nil)
+}
+
+// DeleteRole drops the role, not existing role is skipped.
+func (m *MongoDB) DeleteRole(ctx context.Context, name string) error {
+	command := bson.D{{Key: "dropRole", Value: name}}
+	return ignoreCode(m.client.RunCommand(ctx, m.config.UsersDB(), command, nil), roleNotFoundCode)
+}
+
+// ApplyPrivileges grants roles of privileges, privilege can contain comma separated list of roles.
+func (m *MongoDB) ApplyPrivileges(ctx context.Context, username string, privileges []v1alpha1.PrivilegeSpec) error {
+	return m.rolesProcessor(ctx, "grantRolesToUser", username, privileges)
+}
+
+// RevokePrivileges revokes roles of privileges, missing user has no roles to revoke, so it is skipped.
+func (m *MongoDB) RevokePrivileges(ctx context.Context, username string, privileges []v1alpha1.PrivilegeSpec) error {
+	return ignoreCode(m.rolesProcessor(ctx, "revokeRolesFromUser", username, privileges), userNotFoundCode)
+}
+
+// ApplyRolePrivileges grants roles of privileges to the role created by CreateRole.
+func (m *MongoDB) ApplyRolePrivileges(ctx context.Context, name string, privileges []v1alpha1.PrivilegeSpec) error {
+	return m.rolesProcessor(ctx, "grantRolesToRole", name, privileges)
+}
+
+// RevokeRolePrivileges revokes roles of privileges from the role, missing role is skipped.
+func (m *MongoDB) RevokeRolePrivileges(ctx context.Context, name string, privileges []v1alpha1.PrivilegeSpec) error {
+	return ignoreCode(m.rolesProcessor(ctx, "revokeRolesFromRole", name, privileges), roleNotFoundCode)
+}
+
+// GrantRoles grants roles created by CreateRole to the user.
+// MongoDB has no admin option and default roles, so they are ignored.
+func (m *MongoDB) GrantRoles(ctx context.Context, username string, roles []v1alpha1.RoleGrant) error {
+	return m.runRolesCommand(ctx, "grantRolesToUser", username, m.roleGrantRefs(roles))
+}
+
+// RevokeRoles revokes roles from the user, missing user and already dropped roles are skipped.
+func (m *MongoDB) RevokeRoles(ctx context.Context, username string, roles []v1alpha1.RoleGrant) error {
+	err := m.runRolesCommand(ctx, "revokeRolesFromUser", username, m.roleGrantRefs(roles))
+	return ignoreCode(ignoreCode(err, userNotFoundCode), roleNotFoundCode)
+}
+
+func (m *MongoDB) rolesProcessor(ctx context.Context, command, name string, privileges []v1alpha1.PrivilegeSpec) error {
+	refs, err := m.privilegeRefs(privileges)
+	if err != nil {
+		return err
+	}
+	return m.runRolesCommand(ctx, command, name, refs)
+}
+
+// runRolesCommand runs grantRolesTo*/revokeRolesFrom* command with all roles at once.
+func (m *MongoDB) runRolesCommand(ctx context.Context, command, name string, refs []roleRef) error {
+	if len(refs) < 1 {
+		return nil
+	}
+
+	roles := make(bson.A, 0, len(refs))
+	for _, ref := range refs {
+		roles = append(roles, bson.D{{Key: "role", Value: ref.Role}, {Key: "db", Value: ref.DB}})
+	}
+	cmd := bson.D{{Key: command, Value: name}, {Key: "roles", Value: roles}}
+	return m.client.RunCommand(ctx, m.config.UsersDB(), cmd, nil)
+}
+
+// privilegeRefs maps privileges to {role, db} pairs, database defaults to the users database.
+func (m *MongoDB) privilegeRefs(privileges []v1alpha1.PrivilegeSpec) ([]roleRef, error) {
+	var refs []roleRef
+	for _, privilege := range privileges {
+		if privilege.ObjectType != "" || privilege.On != "" {
+			return nil, fmt.Errorf("%w: MongoDB privilege must be a role name with optional database", v1alpha1.ErrInvalidPrivilege)
+		}
+
+		db := privilege.Database
+		if db == "" {
+			db = m.config.UsersDB()
+		}
+		for _, role := range strings.Split(string(privilege.Privilege), ",") {
+			role = strings.TrimSpace(role)
+			if role == "" {
+				return nil, fmt.Errorf("%w: role name is required", v1alpha1.ErrInvalidPrivilege)
+			}
+			refs = append(refs, roleRef{Role: role, DB: db})
+		}
+	}
+	return refs, nil
+}
+
+func (m *MongoDB) roleGrantRefs(roles []v1alpha1.RoleGrant) []roleRef {
+	refs := make([]roleRef, 0, len(roles))
+	for _, role := range roles {
+		refs = append(refs, roleRef{Role: role.Name, DB: m.config.UsersDB()})
+	}
+	return refs
+}
+
+func (m *MongoDB) usersInfo(ctx context.Context, username string) ([]userInfo, error) {
+	var reply usersInfoReply
+	command := bson.D{{Key: "usersInfo", Value: username}}
+	if err := m.client.RunCommand(ctx, m.config.UsersDB(), command, &reply); err != nil {
+		return nil, err
+	}
+	return reply.Users, nil
+}
+
+func (m *MongoDB) roleExists(ctx context.Context, name string) (bool, error) {
+	var reply rolesInfoReply
+	command := bson.D{{Key: "rolesInfo", Value: name}}
+	if err := m.client.RunCommand(ctx, m.config.UsersDB(), command, &reply); err != nil {
+		return false, err
+	}
+	return len(reply.Roles) > 0, nil
+}
+
+func (m *MongoDB) UserExists(ctx context.Context, username string) (bool, error) {
+	users, err := m.usersInfo(ctx, username)
+	if err != nil {
+		return false, err
+	}
+	return len(users) > 0, nil
+}
+
+// ListPrivileges returns roles of the user as privileges with role name and database.
+func (m *MongoDB) ListPrivileges(ctx context.Context, username string) ([]v1alpha1.PrivilegeSpec, error) {
+	users, err := m.usersInfo(ctx, username)
+	if err != nil || len(users) < 1 {
+		return nil, err
+	}
+
+	var privileges []v1alpha1.PrivilegeSpec
+	for _, role := range users[0].Roles {
+		privileges = append(privileges, v1alpha1.PrivilegeSpec{Privilege: v1alpha1.PrivilegeType(role.Role), Database: role.DB})
+	}
+	return privileges, nil
+}
+
+func (m *MongoDB) ServerVersion(ctx context.Context) (string, error) {
+	var reply buildInfoReply
+	command := bson.D{{Key: "buildInfo", Value: 1}}
+	if err := m.client.RunCommand(ctx, v1alpha1.MongoDBDefaultUsersDatabase, command, &reply); err != nil {
+		return "", err
+	}
+	return reply.Version, nil
+}
+
+// TLSEnabled returns whether the connection is configured to use TLS,
+// MongoDB doesn't expose TLS state of the current connection.
+func (m *MongoDB) TLSEnabled(_ context.Context) (bool, error) {
+	return m.config.Secure, nil
+}
+
+// ignoreCode returns nil, if err is the command error with the code.
+func ignoreCode(err error, code int32) error {
+	var cmdErr mongo.CommandError
+	if errors.As(err, &cmdErr) && cmdErr.Code == code {
+		return nil
+	}
+	return err
+}
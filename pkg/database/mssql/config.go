@@ -0,0 +1,71 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mssql
+
+import (
+	"fmt"
+	"net/url"
+)
+
+type Config struct {
+	Host         string
+	Port         int
+	User         string
+	Password     string
+	DatabaseName string
+
+	usersDatabases []string
+	// Databases of the privileges, where principals were created by grants, see MSSQL.WithPrivileges.
+	privilegesDatabases []string
+}
+
+func NewConfig(host string, port int, user, pass, dbname string, usersDatabases []string) *Config {
+	return &Config{
+		Host:           host,
+		User:           user,
+		Password:       pass,
+		Port:           port,
+		DatabaseName:   dbname,
+		usersDatabases: usersDatabases,
+	}
+}
+
+// ConnString returns URL for the SQL Server driver.
+func (c *Config) ConnString() string {
+	userInfo := url.User(c.User)
+	if c.Password != "" {
+		userInfo = url.UserPassword(c.User, c.Password)
+	}
+
+	query := url.Values{}
+	if c.DatabaseName != "" {
+		query.Set("database", c.DatabaseName)
+	}
+
+	u := url.URL{
+		Scheme:   "sqlserver",
+		Host:     fmt.Sprintf("%s:%d", c.Host, c.Port),
+		User:     userInfo,
+		RawQuery: query.Encode(),
+	}
+	return u.String()
+}
+
+// UsersDatabases returns databases, where database users and roles are created.
+func (c *Config) UsersDatabases() []string {
+	return c.usersDatabases
+}
@@ -0,0 +1,521 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mssql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/go-logr/logr"
+
+	"github.com/alex123012/database-users-operator/api/v1alpha1"
+	"github.com/alex123012/database-users-operator/pkg/database/connection"
+)
+
+const defaultSchema = "dbo"
+
+var ErrNoUsersDatabases = errors.New("no databases to create roles in, set usersDatabases or databaseName")
+
+type MSSQL struct {
+	db     connection.Connection
+	config *Config
+	logger logr.Logger
+}
+
+func NewMSSQL(conn connection.Connection, config *Config, logger logr.Logger) *MSSQL {
+	return &MSSQL{
+		db:     conn,
+		logger: logger,
+		config: config,
+	}
+}
+
+// WithPrivileges returns copy of MSSQL, that drops principals also from databases of provided privileges,
+// where they were created before the first grant. The copy shares connection with m.
+func (m *MSSQL) WithPrivileges(privileges []v1alpha1.PrivilegeSpec) *MSSQL {
+	config := *m.config
+	config.privilegesDatabases = nil
+	for _, privilege := range privileges {
+		if privilege.ObjectType == "" && privilege.Database == "" {
+			// Server role membership doesn't create principals in databases.
+			continue
+		}
+		spec := objectSpec(privilege)
+		if spec.ObjectType != v1alpha1.ObjectGlobal && !slices.Contains(config.privilegesDatabases, spec.Database) {
+			config.privilegesDatabases = append(config.privilegesDatabases, spec.Database)
+		}
+	}
+	return NewMSSQL(m.db, &config, m.logger)
+}
+
+func (m *MSSQL) Connect(ctx context.Context) error {
+	return m.db.Connect(ctx, "sqlserver", m.config.ConnString())
+}
+
+func (m *MSSQL) Close(ctx context.Context) error {
+	return m.db.Close(ctx)
+}
+
+func (m *MSSQL) Ping(ctx context.Context) error {
+	return m.db.Ping(ctx)
+}
+
+// CreateUser creates the login, or sets its password, if it already exists,
+// and the database user for the login in every users database.
+func (m *MSSQL) CreateUser(ctx context.Context, username, password string) (map[string]string, error) {
+	query := "IF SUSER_ID(" + quoteString(username) + ") IS NULL CREATE LOGIN " + quoteIdentifier(username) +
+		" WITH PASSWORD = " + quoteString(password) +
+		" ELSE ALTER LOGIN " + quoteIdentifier(username) + " WITH PASSWORD = " + quoteString(password)
+	if err := m.db.Exec(ctx, connection.DisableLogger, query); err != nil {
+		return nil, err
+	}
+
+	for _, dbname := range m.config.UsersDatabases() {
+		if err := m.ensurePrincipal(ctx, dbname, username, false); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+func (m *MSSQL) SetPassword(ctx context.Context, username, password string) error {
+	query := "ALTER LOGIN " + quoteIdentifier(username) + " WITH PASSWORD = " + quoteString(password)
+	return m.db.Exec(ctx, connection.DisableLogger, query)
+}
+
+// AlterUser does nothing, SQL Server has no user options yet.
+func (m *MSSQL) AlterUser(_ context.Context, _, _ string, _, _ v1alpha1.UserOptions) error {
+	return nil
+}
+
+// DeleteUser drops database users for the login from users databases and databases of privileges
+// provided with WithPrivileges and then the login itself.
+func (m *MSSQL) DeleteUser(ctx context.Context, username string) error {
+	for _, dbname := range m.principalDatabases() {
+		if err := m.execInDatabase(ctx, dbname, "DROP USER IF EXISTS "+quoteIdentifier(username)); err != nil {
+			return err
+		}
+	}
+
+	query := "IF SUSER_ID(" + quoteString(username) + ") IS NOT NULL DROP LOGIN " + quoteIdentifier(username)
+	return m.db.Exec(ctx, connection.EnableLogger, query)
+}
+
+// CreateRole creates the database role in every users database.
+func (m *MSSQL) CreateRole(ctx context.Context, name string) error {
+	databases := m.config.UsersDatabases()
+	if len(databases) < 1 {
+		return ErrNoUsersDatabases
+	}
+
+	for _, dbname := range databases {
+		if err := m.ensurePrincipal(ctx, dbname, name, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteRole drops the database role from users databases and databases of privileges provided with WithPrivileges.
+func (m *MSSQL) DeleteRole(ctx context.Context, name string) error {
+	for _, dbname := range m.principalDatabases() {
+		if err := m.execInDatabase(ctx, dbname, "DROP ROLE IF EXISTS "+quoteIdentifier(name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *MSSQL) ApplyPrivileges(ctx context.Context, username string, privileges []v1alpha1.PrivilegeSpec) error {
+	return m.privilegesProcessor(ctx, username, false, privileges, "GRANT", "TO")
+}
+
+func (m *MSSQL) RevokePrivileges(ctx context.Context, username string, privileges []v1alpha1.PrivilegeSpec) error {
+	return m.privilegesProcessor(ctx, username, false, privileges, "REVOKE", "FROM")
+}
+
+// ApplyRolePrivileges grants privileges to the database role created by CreateRole.
+// Roles in SQL Server are database principals, so they don't have login and can't be given server privileges.
+func (m *MSSQL) ApplyRolePrivileges(ctx context.Context, name string, privileges []v1alpha1.PrivilegeSpec) error {
+	return m.privilegesProcessor(ctx, name, true, privileges, "GRANT", "TO")
+}
+
+func (m *MSSQL) RevokeRolePrivileges(ctx context.Context, name string, privileges []v1alpha1.PrivilegeSpec) error {
+	return m.privilegesProcessor(ctx, name, true, privileges, "REVOKE", "FROM")
+}
+
+// GrantRoles adds the database user to roles in every users database.
+// AdminOption is given as ALTER permission on the role, that allows to add and remove its members.
+func (m *MSSQL) GrantRoles(ctx context.Context, username string, roles []v1alpha1.RoleGrant) error {
+	databases := m.config.UsersDatabases()
+	if len(databases) < 1 && len(roles) > 0 {
+		return ErrNoUsersDatabases
+	}
+
+	for _, dbname := range databases {
+		if err := m.ensurePrincipal(ctx, dbname, username, false); err != nil {
+			return err
+		}
+
+		for _, role := range roles {
+			query := "ALTER ROLE " + quoteIdentifier(role.Name) + " ADD MEMBER " + quoteIdentifier(username)
+			if err := m.execInDatabase(ctx, dbname, query); err != nil {
+				return err
+			}
+
+			if role.AdminOption {
+				query := "GRANT ALTER ON ROLE::" + quoteIdentifier(role.Name) + " TO " + quoteIdentifier(username)
+				if err := m.execInDatabase(ctx, dbname, query); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// RevokeRoles removes the database user from roles in every users database.
+func (m *MSSQL) RevokeRoles(ctx context.Context, username string, roles []v1alpha1.RoleGrant) error {
+	for _, dbname := range m.config.UsersDatabases() {
+		for _, role := range roles {
+			if role.AdminOption {
+				query := "REVOKE ALTER ON ROLE::" + quoteIdentifier(role.Name) + " FROM " + quoteIdentifier(username)
+				if err := m.execInDatabase(ctx, dbname, ifPrincipalExists(username, query)); err != nil {
+					return err
+				}
+			}
+
+			query := "ALTER ROLE " + quoteIdentifier(role.Name) + " DROP MEMBER " + quoteIdentifier(username)
+			if err := m.execInDatabase(ctx, dbname, ifPrincipalExists(username, query)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// privilegesProcessor grants or revokes privileges.
+// Database user is created in the database of the privilege before the first grant in it,
+// revokes are skipped for databases without the user. Role is true for the database role created by CreateRole.
+func (m *MSSQL) privilegesProcessor(ctx context.Context, name string, role bool, privileges []v1alpha1.PrivilegeSpec, statement, arg string) error {
+	prepared := make(map[string]bool)
+	for _, privilege := range privileges {
+		if privilege.ObjectType == "" && privilege.Database == "" {
+			// Privilege without database is the name of the server role.
+			if role {
+				return fmt.Errorf("%w: server roles can't be granted to database roles", v1alpha1.ErrInvalidPrivilege)
+			}
+			if err := m.serverRoleMembership(ctx, name, string(privilege.Privilege), statement); err != nil {
+				return err
+			}
+			continue
+		}
+
+		spec := objectSpec(privilege)
+		queries, err := prepareStatementsForObjectPrivilege(statement, arg, name, spec)
+		if err != nil {
+			return err
+		}
+
+		if spec.ObjectType == v1alpha1.ObjectGlobal {
+			if role {
+				return fmt.Errorf("%w: server privileges can't be given to database roles", v1alpha1.ErrInvalidPrivilege)
+			}
+			for _, query := range queries {
+				if err := m.db.Exec(ctx, connection.EnableLogger, query); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if statement == "GRANT" && !prepared[spec.Database] {
+			if err := m.ensurePrincipal(ctx, spec.Database, name, role); err != nil {
+				return err
+			}
+			prepared[spec.Database] = true
+		}
+
+		for _, query := range queries {
+			if statement == "REVOKE" {
+				query = ifPrincipalExists(name, query)
+			}
+			if err := m.execInDatabase(ctx, spec.Database, query); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (m *MSSQL) serverRoleMembership(ctx context.Context, username, role, statement string) error {
+	action := " ADD MEMBER "
+	if statement == "REVOKE" {
+		action = " DROP MEMBER "
+	}
+	query := "ALTER SERVER ROLE " + quoteIdentifier(role) + action + quoteIdentifier(username)
+	return m.db.Exec(ctx, connection.EnableLogger, query)
+}
+
+// ensurePrincipal creates the database user for the login (or the database role, if role is true) in the database,
+// if it doesn't exist yet.
+func (m *MSSQL) ensurePrincipal(ctx context.Context, dbname, name string, role bool) error {
+	create := "CREATE USER " + quoteIdentifier(name) + " FOR LOGIN " + quoteIdentifier(name)
+	if role {
+		create = "CREATE ROLE " + quoteIdentifier(name)
+	}
+	query := "IF DATABASE_PRINCIPAL_ID(" + quoteString(name) + ") IS NULL " + create
+	return m.execInDatabase(ctx, dbname, query)
+}
+
+// execInDatabase executes the statement in the context of the database,
+// so connection to every database isn't required.
+func (m *MSSQL) execInDatabase(ctx context.Context, dbname, statement string) error {
+	query := "EXEC " + quoteIdentifier(dbname) + ".sys.sp_executesql @p1"
+	return m.db.Exec(ctx, connection.EnableLogger, query, statement)
+}
+
+// principalDatabases returns databases, where principals could be created by the operator:
+// users databases and databases of privileges provided with WithPrivileges.
+func (m *MSSQL) principalDatabases() []string {
+	databases := slices.Clone(m.config.UsersDatabases())
+	for _, dbname := range m.config.privilegesDatabases {
+		if !slices.Contains(databases, dbname) {
+			databases = append(databases, dbname)
+		}
+	}
+	return databases
+}
+
+func (m *MSSQL) UserExists(ctx context.Context, username string) (bool, error) {
+	var logins []string
+	query := "SELECT name FROM sys.server_principals WHERE name = @p1 AND type = 'S'"
+	if err := m.db.Select(ctx, connection.EnableLogger, &logins, query, username); err != nil {
+		return false, err
+	}
+	return len(logins) > 0, nil
+}
+
+func (m *MSSQL) ServerVersion(ctx context.Context) (string, error) {
+	var version string
+	query := "SELECT CAST(SERVERPROPERTY('ProductVersion') AS nvarchar(128))"
+	if err := m.db.Get(ctx, connection.EnableLogger, &version, query); err != nil {
+		return "", err
+	}
+	return version, nil
+}
+
+func (m *MSSQL) TLSEnabled(ctx context.Context) (bool, error) {
+	var encrypted string
+	query := "SELECT encrypt_option FROM sys.dm_exec_connections WHERE session_id = @@SPID"
+	if err := m.db.Get(ctx, connection.EnableLogger, &encrypted, query); err != nil {
+		return false, err
+	}
+	return strings.EqualFold(encrypted, "TRUE"), nil
+}
+
+type permission struct {
+	Name   string `db:"permission_name"`
+	Class  string `db:"class_desc"`
+	Schema string `db:"schema_name"`
+	Object string `db:"object_name"`
+	State  string `db:"state"`
+}
+
+// ListPrivileges returns server roles and server privileges of the login
+// and privileges of the database user in every users database.
+func (m *MSSQL) ListPrivileges(ctx context.Context, username string) ([]v1alpha1.PrivilegeSpec, error) {
+	var roles []string
+	query := "SELECT r.name FROM sys.server_role_members rm" +
+		" JOIN sys.server_principals r ON r.principal_id = rm.role_principal_id" +
+		" JOIN sys.server_principals u ON u.principal_id = rm.member_principal_id WHERE u.name = @p1"
+	if err := m.db.Select(ctx, connection.EnableLogger, &roles, query, username); err != nil {
+		return nil, err
+	}
+
+	var privileges []v1alpha1.PrivilegeSpec
+	for _, role := range roles {
+		privileges = append(privileges, v1alpha1.PrivilegeSpec{Privilege: v1alpha1.PrivilegeType(role)})
+	}
+
+	var serverPermissions []permission
+	query = "SELECT p.permission_name, p.class_desc, p.state FROM sys.server_permissions p" +
+		" JOIN sys.server_principals u ON u.principal_id = p.grantee_principal_id" +
+		" WHERE u.name = @p1 AND p.state IN ('G', 'W') AND p.permission_name <> 'CONNECT SQL'"
+	if err := m.db.Select(ctx, connection.EnableLogger, &serverPermissions, query, username); err != nil {
+		return nil, err
+	}
+	for _, p := range serverPermissions {
+		privileges = append(privileges, v1alpha1.PrivilegeSpec{
+			ObjectType:      v1alpha1.ObjectGlobal,
+			Privileges:      []v1alpha1.PrivilegeType{v1alpha1.PrivilegeType(p.Name)},
+			WithGrantOption: p.State == "W",
+		})
+	}
+
+	for _, dbname := range m.config.UsersDatabases() {
+		var permissions []permission
+		db := quoteIdentifier(dbname)
+		query := "SELECT p.permission_name, p.class_desc, ISNULL(s.name, '') AS schema_name, ISNULL(o.name, '') AS object_name, p.state" +
+			" FROM " + db + ".sys.database_permissions p" +
+			" JOIN " + db + ".sys.database_principals u ON u.principal_id = p.grantee_principal_id" +
+			" LEFT JOIN " + db + ".sys.objects o ON p.class = 1 AND o.object_id = p.major_id" +
+			" LEFT JOIN " + db + ".sys.schemas s ON s.schema_id = CASE p.class WHEN 1 THEN o.schema_id WHEN 3 THEN p.major_id END" +
+			" WHERE u.name = @p1 AND p.state IN ('G', 'W') AND p.permission_name <> 'CONNECT'"
+		if err := m.db.Select(ctx, connection.EnableLogger, &permissions, query, username); err != nil {
+			return nil, err
+		}
+		for _, p := range permissions {
+			if spec, ok := permissionSpec(dbname, p); ok {
+				privileges = append(privileges, spec)
+			}
+		}
+	}
+	return privileges, nil
+}
+
+// permissionSpec converts database permission to the privilege, permissions on other classes of securables are skipped.
+func permissionSpec(dbname string, p permission) (v1alpha1.PrivilegeSpec, bool) {
+	spec := v1alpha1.PrivilegeSpec{
+		Database:        dbname,
+		Privileges:      []v1alpha1.PrivilegeType{v1alpha1.PrivilegeType(p.Name)},
+		WithGrantOption: p.State == "W",
+	}
+	switch p.Class {
+	case "DATABASE":
+		spec.ObjectType = v1alpha1.ObjectDatabase
+	case "SCHEMA":
+		spec.ObjectType = v1alpha1.ObjectSchema
+		spec.Schema = p.Schema
+	case "OBJECT_OR_COLUMN":
+		spec.ObjectType = v1alpha1.ObjectTable
+		spec.Schema = p.Schema
+		spec.Objects = []string{p.Object}
+	default:
+		return v1alpha1.PrivilegeSpec{}, false
+	}
+	return spec, true
+}
+
+// ifPrincipalExists guards the statement, so it is skipped in databases without the principal.
+func ifPrincipalExists(name, statement string) string {
+	return "IF DATABASE_PRINCIPAL_ID(" + quoteString(name) + ") IS NOT NULL " + statement
+}
+
+// objectSpec converts privilege in legacy format (privilege, database and on) to the structured one,
+// "on" can be qualified with schema ("schema.table"), otherwise "dbo" schema is used.
+func objectSpec(privilege v1alpha1.PrivilegeSpec) v1alpha1.PrivilegeSpec {
+	if privilege.ObjectType != "" {
+		return privilege
+	}
+
+	spec := v1alpha1.PrivilegeSpec{Database: privilege.Database}
+	switch {
+	case privilege.Database == v1alpha1.MSSQLServerDatabase:
+		spec.ObjectType = v1alpha1.ObjectGlobal
+	case privilege.On == "":
+		spec.ObjectType = v1alpha1.ObjectDatabase
+	default:
+		spec.ObjectType = v1alpha1.ObjectTable
+		object := privilege.On
+		if schema, name, ok := strings.Cut(privilege.On, "."); ok {
+			spec.Schema, object = schema, name
+		}
+		spec.Objects = []string{object}
+	}
+	for _, item := range strings.Split(string(privilege.Privilege), ",") {
+		spec.Privileges = append(spec.Privileges, v1alpha1.PrivilegeType(strings.TrimSpace(item)))
+	}
+	return spec
+}
+
+// prepareStatementsForObjectPrivilege returns statements for the privilege.
+// SQL Server allows only one securable in the statement, so every object gets its own statement.
+func prepareStatementsForObjectPrivilege(statement, arg, name string, privilege v1alpha1.PrivilegeSpec) ([]string, error) {
+	if privilege.DefaultPrivileges != nil {
+		return nil, fmt.Errorf("%w: default privileges are not supported by MSSQL", v1alpha1.ErrInvalidPrivilege)
+	}
+	if privilege.ObjectType != v1alpha1.ObjectGlobal && privilege.Database == "" {
+		return nil, fmt.Errorf("%w: database is required for objectType %s", v1alpha1.ErrInvalidPrivilege, privilege.ObjectType)
+	}
+
+	privileges, err := privilege.PrivilegesList()
+	if err != nil {
+		return nil, err
+	}
+
+	targets, err := objectTargets(privilege)
+	if err != nil {
+		return nil, err
+	}
+
+	queries := make([]string, 0, len(targets))
+	for _, target := range targets {
+		stmtBuilder := &strings.Builder{}
+		stmtBuilder.WriteString(statement)
+		stmtBuilder.WriteString(" ")
+		stmtBuilder.WriteString(privileges)
+		if target != "" {
+			stmtBuilder.WriteString(" ON ")
+			stmtBuilder.WriteString(target)
+		}
+		stmtBuilder.WriteString(" ")
+		stmtBuilder.WriteString(arg)
+		stmtBuilder.WriteString(" ")
+		stmtBuilder.WriteString(quoteIdentifier(name))
+		if privilege.WithGrantOption {
+			if statement == "GRANT" {
+				stmtBuilder.WriteString(" WITH GRANT OPTION")
+			} else {
+				stmtBuilder.WriteString(" CASCADE")
+			}
+		}
+		queries = append(queries, stmtBuilder.String())
+	}
+	return queries, nil
+}
+
+// objectTargets returns quoted securables of the privilege for "ON" clause,
+// empty target means database or server privilege.
+func objectTargets(privilege v1alpha1.PrivilegeSpec) ([]string, error) {
+	schema := privilege.Schema
+	if schema == "" {
+		schema = defaultSchema
+	}
+
+	switch privilege.ObjectType {
+	case v1alpha1.ObjectGlobal, v1alpha1.ObjectDatabase:
+		return []string{""}, nil
+
+	case v1alpha1.ObjectSchema:
+		return []string{"SCHEMA::" + quoteIdentifier(schema)}, nil
+
+	case v1alpha1.ObjectTable, v1alpha1.ObjectSequence, v1alpha1.ObjectFunction:
+		if privilege.AllObjects() {
+			return []string{"SCHEMA::" + quoteIdentifier(schema)}, nil
+		}
+		targets := make([]string, 0, len(privilege.Objects))
+		for _, object := range privilege.Objects {
+			targets = append(targets, "OBJECT::"+quoteIdentifier(schema)+"."+quoteIdentifier(object))
+		}
+		return targets, nil
+	}
+	return nil, fmt.Errorf("%w: objectType %s is not supported by MSSQL", v1alpha1.ErrInvalidPrivilege, privilege.ObjectType)
+}
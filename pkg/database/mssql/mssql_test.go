@@ -0,0 +1,359 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mssql_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/go-logr/logr"
+
+	"github.com/alex123012/database-users-operator/api/v1alpha1"
+	"github.com/alex123012/database-users-operator/pkg/database/connection"
+	"github.com/alex123012/database-users-operator/pkg/database/mssql"
+	testsutils "github.com/alex123012/database-users-operator/pkg/utils/tests_utils"
+)
+
+func newMSSQL(t *testing.T, mockDB connection.Connection, usersDatabases []string) *mssql.MSSQL {
+	t.Helper()
+	m := mssql.NewMSSQL(mockDB, mssql.NewConfig("mssql", 1433, "sa", "password", "", usersDatabases), logr.Discard())
+	if err := m.Connect(context.Background()); err != nil {
+		t.Fatalf("MSSQL.Connect() error = %v", err)
+	}
+	t.Cleanup(func() { m.Close(context.Background()) })
+	return m
+}
+
+func inDatabase(dbname, statement string) string {
+	return "EXEC [" + dbname + "].sys.sp_executesql @p1" + statement
+}
+
+func TestMSSQL_CreateUser(t *testing.T) {
+	tests := []struct {
+		name           string
+		usersDatabases []string
+		want           []string
+	}{
+		{
+			name:           "Login with database user in every users database",
+			usersDatabases: []string{"app", "reports"},
+			want: []string{
+				"IF SUSER_ID(N'john') IS NULL CREATE LOGIN [john] WITH PASSWORD = N'my''pass' ELSE ALTER LOGIN [john] WITH PASSWORD = N'my''pass'",
+				inDatabase("app", "IF DATABASE_PRINCIPAL_ID(N'john') IS NULL CREATE USER [john] FOR LOGIN [john]"),
+				inDatabase("reports", "IF DATABASE_PRINCIPAL_ID(N'john') IS NULL CREATE USER [john] FOR LOGIN [john]"),
+			},
+		},
+		{
+			name: "Only login without users databases",
+			want: []string{
+				"IF SUSER_ID(N'john') IS NULL CREATE LOGIN [john] WITH PASSWORD = N'my''pass' ELSE ALTER LOGIN [john] WITH PASSWORD = N'my''pass'",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := connection.NewFakeConnection()
+			m := newMSSQL(t, mockDB, tt.usersDatabases)
+			if _, err := m.CreateUser(context.Background(), "john", "my'pass"); err != nil {
+				t.Errorf("MSSQL.CreateUser() error = %v", err)
+			}
+			testsutils.CheckQueries(t, tt.want, mockDB.Queries())
+		})
+	}
+}
+
+func TestMSSQL_Privileges(t *testing.T) {
+	tests := []struct {
+		name       string
+		privileges []v1alpha1.PrivilegeSpec
+		want       []string
+	}{
+		{
+			name: "Database user is created once before the first grant in the database",
+			privileges: []v1alpha1.PrivilegeSpec{
+				{Privilege: "SELECT, insert", On: "sales.orders", Database: "dat"},
+				{Privilege: "CREATE TABLE", Database: "dat"},
+				{ObjectType: v1alpha1.ObjectDatabase, Database: "reports", Privileges: []v1alpha1.PrivilegeType{v1alpha1.SELECT}},
+			},
+			want: []string{
+				inDatabase("dat", "IF DATABASE_PRINCIPAL_ID(N'john') IS NULL CREATE USER [john] FOR LOGIN [john]"),
+				inDatabase("dat", "GRANT SELECT, INSERT ON OBJECT::[sales].[orders] TO [john]"),
+				inDatabase("dat", "GRANT CREATE TABLE TO [john]"),
+				inDatabase("reports", "IF DATABASE_PRINCIPAL_ID(N'john') IS NULL CREATE USER [john] FOR LOGIN [john]"),
+				inDatabase("reports", "GRANT SELECT TO [john]"),
+
+				inDatabase("dat", "IF DATABASE_PRINCIPAL_ID(N'john') IS NOT NULL REVOKE SELECT, INSERT ON OBJECT::[sales].[orders] FROM [john]"),
+				inDatabase("dat", "IF DATABASE_PRINCIPAL_ID(N'john') IS NOT NULL REVOKE CREATE TABLE FROM [john]"),
+				inDatabase("reports", "IF DATABASE_PRINCIPAL_ID(N'john') IS NOT NULL REVOKE SELECT FROM [john]"),
+			},
+		},
+		{
+			name: "Server privileges and server roles are applied to the login",
+			privileges: []v1alpha1.PrivilegeSpec{
+				{Privilege: "VIEW SERVER STATE", Database: v1alpha1.MSSQLServerDatabase},
+				{ObjectType: v1alpha1.ObjectGlobal, Privileges: []v1alpha1.PrivilegeType{"ALTER ANY LOGIN"}},
+				{Privilege: "sysadmin"},
+			},
+			want: []string{
+				"GRANT VIEW SERVER STATE TO [john]",
+				"GRANT ALTER ANY LOGIN TO [john]",
+				"ALTER SERVER ROLE [sysadmin] ADD MEMBER [john]",
+
+				"REVOKE VIEW SERVER STATE FROM [john]",
+				"REVOKE ALTER ANY LOGIN FROM [john]",
+				"ALTER SERVER ROLE [sysadmin] DROP MEMBER [john]",
+			},
+		},
+		{
+			name: "Objects default to dbo schema and grant option is revoked with cascade",
+			privileges: []v1alpha1.PrivilegeSpec{
+				{ObjectType: v1alpha1.ObjectTable, Database: "dat", Schema: "app", Privileges: []v1alpha1.PrivilegeType{v1alpha1.SELECT}, WithGrantOption: true},
+				{ObjectType: v1alpha1.ObjectFunction, Database: "dat", Objects: []string{"calc", "sum"}, Privileges: []v1alpha1.PrivilegeType{v1alpha1.EXECUTE}},
+			},
+			want: []string{
+				inDatabase("dat", "IF DATABASE_PRINCIPAL_ID(N'john') IS NULL CREATE USER [john] FOR LOGIN [john]"),
+				inDatabase("dat", "GRANT SELECT ON SCHEMA::[app] TO [john] WITH GRANT OPTION"),
+				inDatabase("dat", "GRANT EXECUTE ON OBJECT::[dbo].[calc] TO [john]"),
+				inDatabase("dat", "GRANT EXECUTE ON OBJECT::[dbo].[sum] TO [john]"),
+
+				inDatabase("dat", "IF DATABASE_PRINCIPAL_ID(N'john') IS NOT NULL REVOKE SELECT ON SCHEMA::[app] FROM [john] CASCADE"),
+				inDatabase("dat", "IF DATABASE_PRINCIPAL_ID(N'john') IS NOT NULL REVOKE EXECUTE ON OBJECT::[dbo].[calc] FROM [john]"),
+				inDatabase("dat", "IF DATABASE_PRINCIPAL_ID(N'john') IS NOT NULL REVOKE EXECUTE ON OBJECT::[dbo].[sum] FROM [john]"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			mockDB := connection.NewFakeConnection()
+			m := newMSSQL(t, mockDB, []string{"app"})
+			if err := m.ApplyPrivileges(ctx, "john", tt.privileges); err != nil {
+				t.Errorf("MSSQL.ApplyPrivileges() error = %v", err)
+			}
+			if err := m.RevokePrivileges(ctx, "john", tt.privileges); err != nil {
+				t.Errorf("MSSQL.RevokePrivileges() error = %v", err)
+			}
+			testsutils.CheckQueries(t, tt.want, mockDB.Queries())
+		})
+	}
+}
+
+func TestMSSQL_RolePrivileges(t *testing.T) {
+	ctx := context.Background()
+	mockDB := connection.NewFakeConnection()
+	m := newMSSQL(t, mockDB, []string{"app"})
+
+	if err := m.CreateRole(ctx, "app_readonly"); err != nil {
+		t.Errorf("MSSQL.CreateRole() error = %v", err)
+	}
+	privileges := []v1alpha1.PrivilegeSpec{{ObjectType: v1alpha1.ObjectDatabase, Database: "dat", Privileges: []v1alpha1.PrivilegeType{v1alpha1.SELECT}}}
+	if err := m.ApplyRolePrivileges(ctx, "app_readonly", privileges); err != nil {
+		t.Errorf("MSSQL.ApplyRolePrivileges() error = %v", err)
+	}
+	if err := m.RevokeRolePrivileges(ctx, "app_readonly", privileges); err != nil {
+		t.Errorf("MSSQL.RevokeRolePrivileges() error = %v", err)
+	}
+
+	// Database role is created in the database of the privilege before the grant.
+	testsutils.CheckQueries(t, []string{
+		inDatabase("app", "IF DATABASE_PRINCIPAL_ID(N'app_readonly') IS NULL CREATE ROLE [app_readonly]"),
+		inDatabase("dat", "IF DATABASE_PRINCIPAL_ID(N'app_readonly') IS NULL CREATE ROLE [app_readonly]"),
+		inDatabase("dat", "GRANT SELECT TO [app_readonly]"),
+		inDatabase("dat", "IF DATABASE_PRINCIPAL_ID(N'app_readonly') IS NOT NULL REVOKE SELECT FROM [app_readonly]"),
+	}, mockDB.Queries())
+
+	withoutDatabases := newMSSQL(t, mockDB, nil)
+	if err := withoutDatabases.CreateRole(ctx, "app_readonly"); !errors.Is(err, mssql.ErrNoUsersDatabases) {
+		t.Errorf("MSSQL.CreateRole() error = %v, want %v", err, mssql.ErrNoUsersDatabases)
+	}
+}
+
+func TestMSSQL_UnsupportedPrivileges(t *testing.T) {
+	ctx := context.Background()
+	mockDB := connection.NewFakeConnection()
+	m := newMSSQL(t, mockDB, []string{"app"})
+
+	privileges := []v1alpha1.PrivilegeSpec{
+		{ObjectType: v1alpha1.ObjectTable, Privileges: []v1alpha1.PrivilegeType{v1alpha1.SELECT}},
+		{ObjectType: v1alpha1.ObjectTable, Database: "dat", Privileges: []v1alpha1.PrivilegeType{v1alpha1.SELECT}, DefaultPrivileges: &v1alpha1.DefaultPrivileges{}},
+	}
+	for _, privilege := range privileges {
+		if err := m.ApplyPrivileges(ctx, "john", []v1alpha1.PrivilegeSpec{privilege}); !errors.Is(err, v1alpha1.ErrInvalidPrivilege) {
+			t.Errorf("MSSQL.ApplyPrivileges(%v) error = %v, want %v", privilege, err, v1alpha1.ErrInvalidPrivilege)
+		}
+	}
+
+	// Server roles and server privileges belong to logins, database roles can't get them.
+	serverPrivileges := []v1alpha1.PrivilegeSpec{
+		{Privilege: "sysadmin"},
+		{ObjectType: v1alpha1.ObjectGlobal, Privileges: []v1alpha1.PrivilegeType{"VIEW SERVER STATE"}},
+	}
+	for _, privilege := range serverPrivileges {
+		if err := m.ApplyRolePrivileges(ctx, "app_readonly", []v1alpha1.PrivilegeSpec{privilege}); !errors.Is(err, v1alpha1.ErrInvalidPrivilege) {
+			t.Errorf("MSSQL.ApplyRolePrivileges(%v) error = %v, want %v", privilege, err, v1alpha1.ErrInvalidPrivilege)
+		}
+	}
+
+	if queries := mockDB.Queries(); len(queries) != 0 {
+		t.Errorf("Queries executed for invalid privileges: %v", queries)
+	}
+}
+
+func TestMSSQL_DropPrincipals(t *testing.T) {
+	ctx := context.Background()
+	mockDB := connection.NewFakeConnection()
+	m := newMSSQL(t, mockDB, []string{"app"})
+
+	privileges := []v1alpha1.PrivilegeSpec{
+		{Privilege: "SELECT", On: "sales.orders", Database: "dat"},
+		{ObjectType: v1alpha1.ObjectDatabase, Database: "dat", Privileges: []v1alpha1.PrivilegeType{v1alpha1.CONNECT}},
+		{ObjectType: v1alpha1.ObjectDatabase, Database: "app", Privileges: []v1alpha1.PrivilegeType{v1alpha1.SELECT}},
+		{ObjectType: v1alpha1.ObjectTable, Database: "reports", Objects: []string{"daily"}, Privileges: []v1alpha1.PrivilegeType{v1alpha1.SELECT}},
+		{Privilege: "VIEW SERVER STATE", Database: v1alpha1.MSSQLServerDatabase},
+		{Privilege: "sysadmin"},
+	}
+	if err := m.WithPrivileges(privileges).DeleteUser(ctx, "john"); err != nil {
+		t.Errorf("MSSQL.DeleteUser() error = %v", err)
+	}
+	if err := m.WithPrivileges(privileges[:1]).DeleteRole(ctx, "app_readonly"); err != nil {
+		t.Errorf("MSSQL.DeleteRole() error = %v", err)
+	}
+	// Copy returned by WithPrivileges doesn't change databases of the original.
+	if err := m.DeleteRole(ctx, "app_writers"); err != nil {
+		t.Errorf("MSSQL.DeleteRole() error = %v", err)
+	}
+
+	// Principals are dropped only from users databases and databases of the privileges,
+	// where they could be created by grants, server privileges don't create principals in databases.
+	testsutils.CheckQueries(t, []string{
+		inDatabase("app", "DROP USER IF EXISTS [john]"),
+		inDatabase("dat", "DROP USER IF EXISTS [john]"),
+		inDatabase("reports", "DROP USER IF EXISTS [john]"),
+		"IF SUSER_ID(N'john') IS NOT NULL DROP LOGIN [john]",
+		inDatabase("app", "DROP ROLE IF EXISTS [app_readonly]"),
+		inDatabase("dat", "DROP ROLE IF EXISTS [app_readonly]"),
+		inDatabase("app", "DROP ROLE IF EXISTS [app_writers]"),
+	}, mockDB.Queries())
+}
+
+func TestMSSQL_RoleMembership(t *testing.T) {
+	ctx := context.Background()
+	mockDB := connection.NewFakeConnection()
+	m := newMSSQL(t, mockDB, []string{"app", "reports"})
+
+	roles := []v1alpha1.RoleGrant{
+		{Name: "readers"},
+		{Name: "writers", AdminOption: true},
+	}
+	if err := m.GrantRoles(ctx, "john", roles); err != nil {
+		t.Errorf("MSSQL.GrantRoles() error = %v", err)
+	}
+	if err := m.RevokeRoles(ctx, "john", roles[1:]); err != nil {
+		t.Errorf("MSSQL.RevokeRoles() error = %v", err)
+	}
+
+	// Database roles are granted in every users database, admin option is ALTER permission on the role.
+	testsutils.CheckQueries(t, []string{
+		inDatabase("app", "IF DATABASE_PRINCIPAL_ID(N'john') IS NULL CREATE USER [john] FOR LOGIN [john]"),
+		inDatabase("app", "ALTER ROLE [readers] ADD MEMBER [john]"),
+		inDatabase("app", "ALTER ROLE [writers] ADD MEMBER [john]"),
+		inDatabase("app", "GRANT ALTER ON ROLE::[writers] TO [john]"),
+		inDatabase("reports", "IF DATABASE_PRINCIPAL_ID(N'john') IS NULL CREATE USER [john] FOR LOGIN [john]"),
+		inDatabase("reports", "ALTER ROLE [readers] ADD MEMBER [john]"),
+		inDatabase("reports", "ALTER ROLE [writers] ADD MEMBER [john]"),
+		inDatabase("reports", "GRANT ALTER ON ROLE::[writers] TO [john]"),
+		inDatabase("app", "IF DATABASE_PRINCIPAL_ID(N'john') IS NOT NULL REVOKE ALTER ON ROLE::[writers] FROM [john]"),
+		inDatabase("app", "IF DATABASE_PRINCIPAL_ID(N'john') IS NOT NULL ALTER ROLE [writers] DROP MEMBER [john]"),
+		inDatabase("reports", "IF DATABASE_PRINCIPAL_ID(N'john') IS NOT NULL REVOKE ALTER ON ROLE::[writers] FROM [john]"),
+		inDatabase("reports", "IF DATABASE_PRINCIPAL_ID(N'john') IS NOT NULL ALTER ROLE [writers] DROP MEMBER [john]"),
+	}, mockDB.Queries())
+
+	withoutDatabases := newMSSQL(t, mockDB, nil)
+	if err := withoutDatabases.GrantRoles(ctx, "john", roles); !errors.Is(err, mssql.ErrNoUsersDatabases) {
+		t.Errorf("MSSQL.GrantRoles() error = %v, want %v", err, mssql.ErrNoUsersDatabases)
+	}
+}
+
+func TestMSSQL_Introspection(t *testing.T) {
+	ctx := context.Background()
+	username := "john"
+
+	mockDB := connection.NewFakeConnection()
+	m := newMSSQL(t, mockDB, []string{"app"})
+
+	exists, err := m.UserExists(ctx, username)
+	if err != nil || exists {
+		t.Errorf("MSSQL.UserExists() = %v, %v, want false, nil", exists, err)
+	}
+
+	mockDB.SetResult([]string{username}, "SELECT name FROM sys.server_principals WHERE name = @p1 AND type = 'S'", username)
+	mockDB.SetResult([]string{"dbcreator"}, "SELECT r.name FROM sys.server_role_members rm"+
+		" JOIN sys.server_principals r ON r.principal_id = rm.role_principal_id"+
+		" JOIN sys.server_principals u ON u.principal_id = rm.member_principal_id WHERE u.name = @p1", username)
+	mockDB.SetResult([]map[string]interface{}{
+		{"permission_name": "VIEW SERVER STATE", "class_desc": "SERVER", "state": "G"},
+	}, "SELECT p.permission_name, p.class_desc, p.state FROM sys.server_permissions p"+
+		" JOIN sys.server_principals u ON u.principal_id = p.grantee_principal_id"+
+		" WHERE u.name = @p1 AND p.state IN ('G', 'W') AND p.permission_name <> 'CONNECT SQL'", username)
+	mockDB.SetResult([]map[string]interface{}{
+		{"permission_name": "CREATE TABLE", "class_desc": "DATABASE", "schema_name": "", "object_name": "", "state": "G"},
+		{"permission_name": "SELECT", "class_desc": "SCHEMA", "schema_name": "sales", "object_name": "", "state": "W"},
+		{"permission_name": "INSERT", "class_desc": "OBJECT_OR_COLUMN", "schema_name": "dbo", "object_name": "orders", "state": "G"},
+		{"permission_name": "ALTER", "class_desc": "DATABASE_PRINCIPAL", "schema_name": "", "object_name": "", "state": "G"},
+	}, "SELECT p.permission_name, p.class_desc, ISNULL(s.name, '') AS schema_name, ISNULL(o.name, '') AS object_name, p.state"+
+		" FROM [app].sys.database_permissions p"+
+		" JOIN [app].sys.database_principals u ON u.principal_id = p.grantee_principal_id"+
+		" LEFT JOIN [app].sys.objects o ON p.class = 1 AND o.object_id = p.major_id"+
+		" LEFT JOIN [app].sys.schemas s ON s.schema_id = CASE p.class WHEN 1 THEN o.schema_id WHEN 3 THEN p.major_id END"+
+		" WHERE u.name = @p1 AND p.state IN ('G', 'W') AND p.permission_name <> 'CONNECT'", username)
+	mockDB.SetResult("16.0.4105.2", "SELECT CAST(SERVERPROPERTY('ProductVersion') AS nvarchar(128))")
+	mockDB.SetResult("TRUE", "SELECT encrypt_option FROM sys.dm_exec_connections WHERE session_id = @@SPID")
+
+	exists, err = m.UserExists(ctx, username)
+	if err != nil || !exists {
+		t.Errorf("MSSQL.UserExists() = %v, %v, want true, nil", exists, err)
+	}
+
+	privileges, err := m.ListPrivileges(ctx, username)
+	if err != nil {
+		t.Fatalf("MSSQL.ListPrivileges() error = %v", err)
+	}
+	want := []v1alpha1.PrivilegeSpec{
+		{Privilege: "dbcreator"},
+		{ObjectType: v1alpha1.ObjectGlobal, Privileges: []v1alpha1.PrivilegeType{"VIEW SERVER STATE"}},
+		{ObjectType: v1alpha1.ObjectDatabase, Database: "app", Privileges: []v1alpha1.PrivilegeType{"CREATE TABLE"}},
+		{ObjectType: v1alpha1.ObjectSchema, Database: "app", Schema: "sales", Privileges: []v1alpha1.PrivilegeType{v1alpha1.SELECT}, WithGrantOption: true},
+		{ObjectType: v1alpha1.ObjectTable, Database: "app", Schema: "dbo", Objects: []string{"orders"}, Privileges: []v1alpha1.PrivilegeType{v1alpha1.INSERT}},
+	}
+	if !reflect.DeepEqual(privileges, want) {
+		t.Errorf("MSSQL.ListPrivileges() = %v, want %v", privileges, want)
+	}
+
+	version, err := m.ServerVersion(ctx)
+	if err != nil || version != "16.0.4105.2" {
+		t.Errorf("MSSQL.ServerVersion() = %v, %v, want 16.0.4105.2, nil", version, err)
+	}
+
+	tls, err := m.TLSEnabled(ctx)
+	if err != nil || !tls {
+		t.Errorf("MSSQL.TLSEnabled() = %v, %v, want true, nil", tls, err)
+	}
+}
@@ -0,0 +1,29 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mssql
+
+import "strings"
+
+// quoteIdentifier quotes identifier with brackets, "]" inside of it is escaped by doubling.
+func quoteIdentifier(str string) string {
+	return "[" + strings.ReplaceAll(str, "]", "]]") + "]"
+}
+
+// quoteString quotes unicode string literal, "'" inside of it is escaped by doubling.
+func quoteString(str string) string {
+	return "N'" + strings.ReplaceAll(str, "'", "''") + "'"
+}
@@ -21,6 +21,8 @@ import (
 	"net/url"
 
 	"github.com/xo/dburl"
+
+	"github.com/alex123012/database-users-operator/api/v1alpha1"
 )
 
 type Config struct {
@@ -30,17 +32,17 @@ type Config struct {
 	Password     string
 	DatabaseName string
 
-	usersHostname string
+	usersHostnames []string
 }
 
-func NewConfig(host string, port int, user, pass, dbname, usersHostname string) *Config {
+func NewConfig(host string, port int, user, pass, dbname string, usersHostnames []string) *Config {
 	return &Config{
-		Host:          host,
-		User:          user,
-		Password:      pass,
-		Port:          port,
-		DatabaseName:  dbname,
-		usersHostname: usersHostname,
+		Host:           host,
+		User:           user,
+		Password:       pass,
+		Port:           port,
+		DatabaseName:   dbname,
+		usersHostnames: usersHostnames,
 	}
 }
 
@@ -62,9 +64,10 @@ func (c *Config) ConnString() (string, error) {
 	})
 }
 
-func (c *Config) UsersHostname() string {
-	if c.usersHostname == "" {
-		return "*"
+// UsersHostnames returns host patterns of created users, defaults to "%" (any host).
+func (c *Config) UsersHostnames() []string {
+	if len(c.usersHostnames) < 1 {
+		return []string{v1alpha1.MySQLDefaultUsersHostname}
 	}
-	return c.usersHostname
+	return c.usersHostnames
 }
@@ -30,7 +30,7 @@ func TestConfig_ConnString(t *testing.T) {
 		Password     string
 		DatabaseName string
 
-		usersHostname string
+		usersHostnames []string
 	}
 	tests := []struct {
 		name    string
@@ -60,7 +60,7 @@ func TestConfig_ConnString(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			c := mysql.NewConfig(tt.fields.Host, tt.fields.Port, tt.fields.User, tt.fields.Password, tt.fields.DatabaseName, tt.fields.usersHostname)
+			c := mysql.NewConfig(tt.fields.Host, tt.fields.Port, tt.fields.User, tt.fields.Password, tt.fields.DatabaseName, tt.fields.usersHostnames)
 			got, err := c.ConnString()
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Config.ConnString() error = %v, wantErr %v", err, tt.wantErr)
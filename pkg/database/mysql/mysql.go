@@ -18,6 +18,9 @@ package mysql
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/go-logr/logr"
@@ -26,6 +29,25 @@ import (
 	"github.com/alex123012/database-users-operator/pkg/database/connection"
 )
 
+const (
+	authSocketPlugin     = "auth_socket"
+	cachingSHA2Plugin    = "caching_sha2_password"
+	nativePasswordPlugin = "mysql_native_password"
+)
+
+var ErrUnsupportedAuthPlugin = errors.New("unsupported authentication plugin")
+
+// Account is the user or the role in the statement: placeholder ("?@?" or "?") with its arguments.
+type Account struct {
+	Placeholder string
+	Args        []interface{}
+}
+
+// UserAccount returns account of the user with the host pattern ("<user>@<host>").
+func UserAccount(username, hostname string) Account {
+	return Account{Placeholder: "?@?", Args: []interface{}{username, hostname}}
+}
+
 type Mysql struct {
 	db     connection.Connection
 	config *Config
@@ -40,6 +62,14 @@ func NewMysql(conn connection.Connection, config *Config, logger logr.Logger) *M
 	}
 }
 
+// WithUsersHostnames returns copy of Mysql, that manages users for provided host patterns
+// instead of the ones from the Config. The copy shares connection with m.
+func (m *Mysql) WithUsersHostnames(hostnames []string) *Mysql {
+	config := *m.config
+	config.usersHostnames = hostnames
+	return NewMysql(m.db, &config, m.logger)
+}
+
 func (m *Mysql) Connect(ctx context.Context) error {
 	connString, err := m.config.ConnString()
 	if err != nil {
@@ -52,52 +82,426 @@ func (m *Mysql) Close(ctx context.Context) error {
 	return m.db.Close(ctx)
 }
 
+func (m *Mysql) Ping(ctx context.Context) error {
+	return m.db.Ping(ctx)
+}
+
+// CreateUser creates the user for every host pattern.
+// Password is set for the hosts, for which the user already exists, so all of them share the same password.
 func (m *Mysql) CreateUser(ctx context.Context, username, password string) (map[string]string, error) {
-	query := "CREATE USER ?@? IDENTIFIED BY ?"
-	return nil, m.db.Exec(ctx, connection.DisableLogger, query, username, m.config.UsersHostname(), password)
+	existing, err := m.ExistingHostnames(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, hostname := range m.UsersHostnames() {
+		query := "CREATE USER ?@? IDENTIFIED BY ?"
+		if slices.Contains(existing, hostname) {
+			query = "ALTER USER ?@? IDENTIFIED BY ?"
+		}
+		if err := m.db.Exec(ctx, connection.DisableLogger, query, username, hostname, password); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+func (m *Mysql) SetPassword(ctx context.Context, username, password string) error {
+	for _, hostname := range m.UsersHostnames() {
+		query := "ALTER USER ?@? IDENTIFIED BY ?"
+		if err := m.db.Exec(ctx, connection.DisableLogger, query, username, hostname, password); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AlterUser applies account options to the user. Options, that are not set, are reset to MySQL defaults.
+// Authentication plugin, that requires password, is changed only if password is provided.
+func (m *Mysql) AlterUser(ctx context.Context, username, password string, options, _ v1alpha1.UserOptions) error {
+	mysqlOptions := options.MySQL
+	if mysqlOptions == nil {
+		mysqlOptions = &v1alpha1.MySQLUserOptions{}
+	}
+
+	auth, authArgs, err := identifiedWith(mysqlOptions.AuthPlugin, password)
+	if err != nil {
+		return err
+	}
+
+	for _, hostname := range m.UsersHostnames() {
+		query, args := AlterUserQuery(UserAccount(username, hostname), auth, authArgs, mysqlOptions.MySQLAccountOptions)
+		if err := m.db.Exec(ctx, connection.DisableLogger, query, args...); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
+// DeleteUser drops the user for every host pattern, hosts without the user are skipped.
 func (m *Mysql) DeleteUser(ctx context.Context, username string) error {
-	query := "DROP USER ?@?"
-	return m.db.Exec(ctx, connection.EnableLogger, query, username, m.config.UsersHostname())
+	for _, hostname := range m.UsersHostnames() {
+		query := "DROP USER IF EXISTS ?@?"
+		if err := m.db.Exec(ctx, connection.EnableLogger, query, username, hostname); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateRole creates MySQL 8 role, that can be granted to users.
+func (m *Mysql) CreateRole(ctx context.Context, name string) error {
+	query := "CREATE ROLE IF NOT EXISTS ?"
+	return m.db.Exec(ctx, connection.EnableLogger, query, name)
+}
+
+func (m *Mysql) DeleteRole(ctx context.Context, name string) error {
+	query := "DROP ROLE IF EXISTS ?"
+	return m.db.Exec(ctx, connection.EnableLogger, query, name)
 }
 
 func (m *Mysql) ApplyPrivileges(ctx context.Context, username string, privileges []v1alpha1.PrivilegeSpec) error {
-	return m.privilegesProcessor(ctx, username, privileges, "GRANT", "TO")
+	return m.ApplyAccountsPrivileges(ctx, m.UserAccounts(username), privileges)
 }
 
 func (m *Mysql) RevokePrivileges(ctx context.Context, username string, privileges []v1alpha1.PrivilegeSpec) error {
-	return m.privilegesProcessor(ctx, username, privileges, "REVOKE", "FROM")
+	return m.RevokeAccountsPrivileges(ctx, m.UserAccounts(username), privileges)
+}
+
+// ApplyRolePrivileges grants privileges to the role created by CreateRole.
+// Roles are created without host, that is the same as "<role>@%".
+func (m *Mysql) ApplyRolePrivileges(ctx context.Context, name string, privileges []v1alpha1.PrivilegeSpec) error {
+	return m.ApplyAccountsPrivileges(ctx, []Account{UserAccount(name, v1alpha1.MySQLDefaultUsersHostname)}, privileges)
+}
+
+func (m *Mysql) RevokeRolePrivileges(ctx context.Context, name string, privileges []v1alpha1.PrivilegeSpec) error {
+	return m.RevokeAccountsPrivileges(ctx, []Account{UserAccount(name, v1alpha1.MySQLDefaultUsersHostname)}, privileges)
+}
+
+// ApplyAccountsPrivileges grants privileges to every account.
+func (m *Mysql) ApplyAccountsPrivileges(ctx context.Context, accounts []Account, privileges []v1alpha1.PrivilegeSpec) error {
+	return m.privilegesProcessor(ctx, accounts, privileges, "GRANT", "TO")
+}
+
+func (m *Mysql) RevokeAccountsPrivileges(ctx context.Context, accounts []Account, privileges []v1alpha1.PrivilegeSpec) error {
+	return m.privilegesProcessor(ctx, accounts, privileges, "REVOKE", "FROM")
+}
+
+// GrantRoles grants roles to the user and sets roles marked as default as user's default roles.
+func (m *Mysql) GrantRoles(ctx context.Context, username string, roles []v1alpha1.RoleGrant) error {
+	for _, hostname := range m.UsersHostnames() {
+		if err := m.grantRoles(ctx, username, hostname, roles); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func (m *Mysql) privilegesProcessor(ctx context.Context, username string, privileges []v1alpha1.PrivilegeSpec, statement, arg string) error {
-	for _, privilege := range privileges {
-		query, args := prepareStatementForPrivilege(statement, arg, username, privilege.Database, privilege.On, privilege.Privilege)
-		if err := m.db.Exec(ctx, connection.EnableLogger, query, args...); err != nil {
+func (m *Mysql) grantRoles(ctx context.Context, username, hostname string, roles []v1alpha1.RoleGrant) error {
+	var defaultRoles []interface{}
+	for _, role := range roles {
+		query := "GRANT ? TO ?@?"
+		if role.AdminOption {
+			query += " WITH ADMIN OPTION"
+		}
+		if err := m.db.Exec(ctx, connection.EnableLogger, query, role.Name, username, hostname); err != nil {
 			return err
 		}
+
+		if role.Default {
+			defaultRoles = append(defaultRoles, role.Name)
+		}
+	}
+
+	if len(defaultRoles) < 1 {
+		return nil
+	}
+	query := "SET DEFAULT ROLE " + strings.TrimSuffix(strings.Repeat("?, ", len(defaultRoles)), ", ") + " TO ?@?"
+	return m.db.Exec(ctx, connection.EnableLogger, query, append(defaultRoles, username, hostname)...)
+}
+
+// RevokeRoles revokes roles from the user, revoked roles are removed from user's default roles by the server.
+func (m *Mysql) RevokeRoles(ctx context.Context, username string, roles []v1alpha1.RoleGrant) error {
+	for _, hostname := range m.UsersHostnames() {
+		for _, role := range roles {
+			query := "REVOKE ? FROM ?@?"
+			if err := m.db.Exec(ctx, connection.EnableLogger, query, role.Name, username, hostname); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (m *Mysql) privilegesProcessor(ctx context.Context, accounts []Account, privileges []v1alpha1.PrivilegeSpec, statement, arg string) error {
+	for _, account := range accounts {
+		for _, privilege := range privileges {
+			if privilege.ObjectType == "" && privilege.Database == "" {
+				// Privilege without database is the name of the role.
+				query, args := prepareStatementForRole(statement, arg, account, privilege.Privilege)
+				if err := m.db.Exec(ctx, connection.EnableLogger, query, args...); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := m.objectPrivilege(ctx, account, objectSpec(privilege), statement, arg); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
 
-func prepareStatementForPrivilege(statement, arg, username, dbname, on string, privilege v1alpha1.PrivilegeType) (string, []interface{}) {
+func (m *Mysql) objectPrivilege(ctx context.Context, account Account, privilege v1alpha1.PrivilegeSpec, statement, arg string) error {
+	if privilege.DefaultPrivileges != nil {
+		return fmt.Errorf("%w: default privileges are not supported by MySQL", v1alpha1.ErrInvalidPrivilege)
+	}
+
+	privileges, err := privilege.PrivilegesList()
+	if err != nil {
+		return err
+	}
+
+	targets, err := objectTargets(privilege)
+	if err != nil {
+		return err
+	}
+
+	for _, target := range targets {
+		query := prepareStatementForObjectPrivilege(statement, arg, privileges, target, account.Placeholder, privilege.WithGrantOption)
+		if err := m.db.Exec(ctx, connection.EnableLogger, query, account.Args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UserExists returns true, if the user exists for every host pattern.
+func (m *Mysql) UserExists(ctx context.Context, username string) (bool, error) {
+	existing, err := m.ExistingHostnames(ctx, username)
+	if err != nil {
+		return false, err
+	}
+
+	for _, hostname := range m.UsersHostnames() {
+		if !slices.Contains(existing, hostname) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// ExistingHostnames returns host patterns, for which the user exists.
+func (m *Mysql) ExistingHostnames(ctx context.Context, username string) ([]string, error) {
+	var hostnames []string
+	query := "SELECT host FROM mysql.user WHERE user = ?"
+	if err := m.db.Select(ctx, connection.EnableLogger, &hostnames, query, username); err != nil {
+		return nil, err
+	}
+	return hostnames, nil
+}
+
+// UsersHostnames returns host patterns of users from the Config.
+func (m *Mysql) UsersHostnames() []string {
+	return m.config.UsersHostnames()
+}
+
+// UserAccounts returns the user for every host pattern.
+func (m *Mysql) UserAccounts(username string) []Account {
+	hostnames := m.UsersHostnames()
+	accounts := make([]Account, 0, len(hostnames))
+	for _, hostname := range hostnames {
+		accounts = append(accounts, UserAccount(username, hostname))
+	}
+	return accounts
+}
+
+func (m *Mysql) ServerVersion(ctx context.Context) (string, error) {
+	var version string
+	query := "SELECT VERSION()"
+	if err := m.db.Get(ctx, connection.EnableLogger, &version, query); err != nil {
+		return "", err
+	}
+	return version, nil
+}
+
+func (m *Mysql) TLSEnabled(ctx context.Context) (bool, error) {
+	var cipher string
+	query := "SELECT VARIABLE_VALUE FROM performance_schema.session_status WHERE VARIABLE_NAME = 'Ssl_cipher'"
+	if err := m.db.Get(ctx, connection.EnableLogger, &cipher, query); err != nil {
+		return false, err
+	}
+	return cipher != "", nil
+}
+
+// ListPrivileges returns privileges of the user for the first host pattern,
+// privileges are the same for all hosts, when they are applied by the operator.
+func (m *Mysql) ListPrivileges(ctx context.Context, username string) ([]v1alpha1.PrivilegeSpec, error) {
+	var grants []string
+	query := "SHOW GRANTS FOR ?@?"
+	if err := m.db.Select(ctx, connection.EnableLogger, &grants, query, username, m.UsersHostnames()[0]); err != nil {
+		return nil, err
+	}
+
+	var privileges []v1alpha1.PrivilegeSpec
+	for _, grant := range grants {
+		privileges = append(privileges, parseGrant(grant)...)
+	}
+	return privileges, nil
+}
+
+// objectSpec converts privilege in legacy format (privilege, database and on) to the structured one,
+// so privileges are rendered as keywords and targets as identifiers instead of quoted strings.
+func objectSpec(privilege v1alpha1.PrivilegeSpec) v1alpha1.PrivilegeSpec {
+	if privilege.ObjectType != "" {
+		return privilege
+	}
+
+	spec := v1alpha1.PrivilegeSpec{Database: privilege.Database}
+	switch {
+	case privilege.Database == v1alpha1.MySQLGlobalDatabase:
+		spec.ObjectType = v1alpha1.ObjectGlobal
+	case privilege.On == "" || privilege.On == "*":
+		spec.ObjectType = v1alpha1.ObjectDatabase
+	default:
+		spec.ObjectType = v1alpha1.ObjectTable
+		spec.Objects = []string{privilege.On}
+	}
+	for _, item := range strings.Split(string(privilege.Privilege), ",") {
+		spec.Privileges = append(spec.Privileges, v1alpha1.PrivilegeType(strings.TrimSpace(item)))
+	}
+	return spec
+}
+
+func prepareStatementForRole(statement, arg string, account Account, role v1alpha1.PrivilegeType) (string, []interface{}) {
 	stmtBuilder := &strings.Builder{}
 	stmtBuilder.WriteString(statement)
+	stmtBuilder.WriteString(" ?")
+	args := []interface{}{role}
 	stmtBuilder.WriteString(" ")
-	stmtBuilder.WriteString("?")
-	args := []interface{}{privilege}
-	if on != "" && dbname != "" {
-		stmtBuilder.WriteString(" ON ")
-		stmtBuilder.WriteString("?.?")
-		args = append(args, dbname, on)
-	} else if dbname != "" {
-		stmtBuilder.WriteString(" ON ")
-		stmtBuilder.WriteString("?.*")
-		args = append(args, dbname)
-	}
+	stmtBuilder.WriteString(arg)
+	stmtBuilder.WriteString(" ")
+	stmtBuilder.WriteString(account.Placeholder)
+	args = append(args, account.Args...)
+	return stmtBuilder.String(), args
+}
+
+func prepareStatementForObjectPrivilege(statement, arg, privileges, target, account string, withGrantOption bool) string {
+	stmtBuilder := &strings.Builder{}
+	stmtBuilder.WriteString(statement)
+	stmtBuilder.WriteString(" ")
+	stmtBuilder.WriteString(privileges)
+	stmtBuilder.WriteString(" ON ")
+	stmtBuilder.WriteString(target)
 	stmtBuilder.WriteString(" ")
 	stmtBuilder.WriteString(arg)
-	stmtBuilder.WriteString(" ?")
-	args = append(args, username)
+	stmtBuilder.WriteString(" ")
+	stmtBuilder.WriteString(account)
+	if withGrantOption && statement == "GRANT" {
+		stmtBuilder.WriteString(" WITH GRANT OPTION")
+	}
+	return stmtBuilder.String()
+}
+
+// objectTargets returns quoted targets of the privilege for "ON" clause.
+// MySQL allows only one object in the statement, so every object gets its own target.
+func objectTargets(privilege v1alpha1.PrivilegeSpec) ([]string, error) {
+	dbname := quoteIdentifier(privilege.Database)
+	switch privilege.ObjectType {
+	case v1alpha1.ObjectGlobal:
+		return []string{"*.*"}, nil
+
+	case v1alpha1.ObjectDatabase:
+		return []string{dbname + ".*"}, nil
+
+	case v1alpha1.ObjectTable:
+		if privilege.AllObjects() {
+			return []string{dbname + ".*"}, nil
+		}
+		targets := make([]string, 0, len(privilege.Objects))
+		for _, object := range privilege.Objects {
+			targets = append(targets, dbname+"."+quoteIdentifier(object))
+		}
+		return targets, nil
+
+	case v1alpha1.ObjectFunction:
+		if privilege.AllObjects() {
+			return nil, fmt.Errorf("%w: function names are required", v1alpha1.ErrInvalidPrivilege)
+		}
+		targets := make([]string, 0, len(privilege.Objects))
+		for _, object := range privilege.Objects {
+			targets = append(targets, "FUNCTION "+dbname+"."+quoteIdentifier(object))
+		}
+		return targets, nil
+	}
+	return nil, fmt.Errorf("%w: objectType %s is not supported by MySQL", v1alpha1.ErrInvalidPrivilege, privilege.ObjectType)
+}
+
+// identifiedWith returns "IDENTIFIED WITH" clause of ALTER USER statement for the authentication plugin.
+func identifiedWith(plugin, password string) (string, []interface{}, error) {
+	switch plugin {
+	case "":
+	case authSocketPlugin:
+		return " IDENTIFIED WITH " + authSocketPlugin, nil, nil
+	case cachingSHA2Plugin, nativePasswordPlugin:
+		if password != "" {
+			return " IDENTIFIED WITH " + plugin + " BY ?", []interface{}{password}, nil
+		}
+	default:
+		return "", nil, fmt.Errorf("%w: %s", ErrUnsupportedAuthPlugin, plugin)
+	}
+	return "", nil, nil
+}
+
+// AlterUserQuery returns ALTER USER statement for the account with authentication clause and account options,
+// that are the same for MySQL and MariaDB.
+func AlterUserQuery(account Account, auth string, authArgs []interface{}, options v1alpha1.MySQLAccountOptions) (string, []interface{}) {
+	stmtBuilder := &strings.Builder{}
+	stmtBuilder.WriteString("ALTER USER ")
+	stmtBuilder.WriteString(account.Placeholder)
+	stmtBuilder.WriteString(auth)
+	args := append(slices.Clone(account.Args), authArgs...)
+
+	stmtBuilder.WriteString(" REQUIRE ")
+	switch {
+	case options.RequireSubject != "" || options.RequireIssuer != "":
+		var requirements []string
+		if options.RequireSubject != "" {
+			requirements = append(requirements, "SUBJECT ?")
+			args = append(args, options.RequireSubject)
+		}
+		if options.RequireIssuer != "" {
+			requirements = append(requirements, "ISSUER ?")
+			args = append(args, options.RequireIssuer)
+		}
+		stmtBuilder.WriteString(strings.Join(requirements, " AND "))
+	case options.RequireTLS == "SSL" || options.RequireTLS == "X509":
+		stmtBuilder.WriteString(options.RequireTLS)
+	default:
+		stmtBuilder.WriteString("NONE")
+	}
+
+	stmtBuilder.WriteString(" WITH MAX_QUERIES_PER_HOUR ?")
+	stmtBuilder.WriteString(" MAX_UPDATES_PER_HOUR ?")
+	stmtBuilder.WriteString(" MAX_CONNECTIONS_PER_HOUR ?")
+	stmtBuilder.WriteString(" MAX_USER_CONNECTIONS ?")
+	args = append(args, options.MaxQueriesPerHour, options.MaxUpdatesPerHour, options.MaxConnectionsPerHour, options.MaxUserConnections)
+
+	switch {
+	case options.PasswordExpireDays == nil:
+		stmtBuilder.WriteString(" PASSWORD EXPIRE DEFAULT")
+	case *options.PasswordExpireDays == 0:
+		stmtBuilder.WriteString(" PASSWORD EXPIRE NEVER")
+	default:
+		stmtBuilder.WriteString(" PASSWORD EXPIRE INTERVAL ? DAY")
+		args = append(args, *options.PasswordExpireDays)
+	}
+
+	if options.AccountLocked {
+		stmtBuilder.WriteString(" ACCOUNT LOCK")
+	} else {
+		stmtBuilder.WriteString(" ACCOUNT UNLOCK")
+	}
 	return stmtBuilder.String(), args
 }
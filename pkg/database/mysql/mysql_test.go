@@ -18,7 +18,10 @@ package mysql_test
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"reflect"
 	"testing"
 
 	"github.com/go-logr/logr"
@@ -50,7 +53,7 @@ func TestPostgresql(t *testing.T) {
 		{
 			name: "Create user with password, apply privileges, revoke privileges, delete user",
 			fields: fields{
-				config: mysql.NewConfig("mysql", 3306, "user", "password", "dbname", ""),
+				config: mysql.NewConfig("mysql", 3306, "user", "password", "dbname", nil),
 				logger: logr.Discard(),
 			},
 			args: args{
@@ -64,18 +67,89 @@ func TestPostgresql(t *testing.T) {
 				},
 			},
 			queryList: func(a args, f fields) []string {
+				hostname := f.config.UsersHostnames()[0]
 				return []string{
-					fmt.Sprint(`CREATE USER ?@? IDENTIFIED BY ?`, a.username, f.config.UsersHostname(), a.password),
+					fmt.Sprint(`CREATE USER ?@? IDENTIFIED BY ?`, a.username, hostname, a.password),
 
-					fmt.Sprint(`GRANT ? ON ?.? TO ?`, a.privileges[0].Privilege, a.privileges[0].Database, a.privileges[0].On, a.username),
-					fmt.Sprint(`GRANT ? ON ?.* TO ?`, a.privileges[1].Privilege, a.privileges[1].Database, a.username),
-					fmt.Sprint(`GRANT ? TO ?`, a.privileges[2].Privilege, a.username),
+					fmt.Sprint("GRANT ALL PRIVILEGES ON `dat`.`table` TO ?@?", a.username, hostname),
+					fmt.Sprint("GRANT CONNECT ON `conn_dat`.* TO ?@?", a.username, hostname),
+					fmt.Sprint(`GRANT ? TO ?@?`, a.privileges[2].Privilege, a.username, hostname),
 
-					fmt.Sprint(`REVOKE ? ON ?.? FROM ?`, a.privileges[0].Privilege, a.privileges[0].Database, a.privileges[0].On, a.username),
-					fmt.Sprint(`REVOKE ? ON ?.* FROM ?`, a.privileges[1].Privilege, a.privileges[1].Database, a.username),
-					fmt.Sprint(`REVOKE ? FROM ?`, a.privileges[2].Privilege, a.username),
+					fmt.Sprint("REVOKE ALL PRIVILEGES ON `dat`.`table` FROM ?@?", a.username, hostname),
+					fmt.Sprint("REVOKE CONNECT ON `conn_dat`.* FROM ?@?", a.username, hostname),
+					fmt.Sprint(`REVOKE ? FROM ?@?`, a.privileges[2].Privilege, a.username, hostname),
 
-					fmt.Sprint(`DROP USER ?@?`, a.username, f.config.UsersHostname()),
+					fmt.Sprint(`DROP USER IF EXISTS ?@?`, a.username, hostname),
+				}
+			},
+		},
+
+		{
+			name: "Create user for multiple hosts",
+			fields: fields{
+				config: mysql.NewConfig("mysql", 3306, "user", "password", "dbname", []string{"10.0.%", "localhost"}),
+				logger: logr.Discard(),
+			},
+			args: args{
+				ctx:      context.Background(),
+				username: "john",
+				password: "mysupersecretpass",
+				privileges: []v1alpha1.PrivilegeSpec{
+					{Privilege: "SELECT", Database: "dat"},
+				},
+			},
+			queryList: func(a args, f fields) []string {
+				return []string{
+					fmt.Sprint(`CREATE USER ?@? IDENTIFIED BY ?`, a.username, "10.0.%", a.password),
+					fmt.Sprint(`CREATE USER ?@? IDENTIFIED BY ?`, a.username, "localhost", a.password),
+
+					fmt.Sprint("GRANT SELECT ON `dat`.* TO ?@?", a.username, "10.0.%"),
+					fmt.Sprint("GRANT SELECT ON `dat`.* TO ?@?", a.username, "localhost"),
+
+					fmt.Sprint("REVOKE SELECT ON `dat`.* FROM ?@?", a.username, "10.0.%"),
+					fmt.Sprint("REVOKE SELECT ON `dat`.* FROM ?@?", a.username, "localhost"),
+
+					fmt.Sprint(`DROP USER IF EXISTS ?@?`, a.username, "10.0.%"),
+					fmt.Sprint(`DROP USER IF EXISTS ?@?`, a.username, "localhost"),
+				}
+			},
+		},
+
+		{
+			name: "Apply structured object privileges",
+			fields: fields{
+				config: mysql.NewConfig("mysql", 3306, "user", "password", "dbname", nil),
+				logger: logr.Discard(),
+			},
+			args: args{
+				ctx:      context.Background(),
+				username: "john",
+				password: "mysupersecretpass",
+				privileges: []v1alpha1.PrivilegeSpec{
+					{ObjectType: v1alpha1.ObjectGlobal, Privileges: []v1alpha1.PrivilegeType{"process"}},
+					{ObjectType: v1alpha1.ObjectDatabase, Database: "dat", Privileges: []v1alpha1.PrivilegeType{v1alpha1.EXECUTE}},
+					{ObjectType: v1alpha1.ObjectTable, Database: "dat", Objects: []string{"users", "my`table"}, Privileges: []v1alpha1.PrivilegeType{v1alpha1.SELECT, v1alpha1.INSERT}, WithGrantOption: true},
+					{ObjectType: v1alpha1.ObjectFunction, Database: "dat", Objects: []string{"calc"}, Privileges: []v1alpha1.PrivilegeType{v1alpha1.EXECUTE}},
+				},
+			},
+			queryList: func(a args, f fields) []string {
+				hostname := f.config.UsersHostnames()[0]
+				return []string{
+					fmt.Sprint(`CREATE USER ?@? IDENTIFIED BY ?`, a.username, hostname, a.password),
+
+					fmt.Sprint("GRANT PROCESS ON *.* TO ?@?", a.username, hostname),
+					fmt.Sprint("GRANT EXECUTE ON `dat`.* TO ?@?", a.username, hostname),
+					fmt.Sprint("GRANT SELECT, INSERT ON `dat`.`users` TO ?@? WITH GRANT OPTION", a.username, hostname),
+					fmt.Sprint("GRANT SELECT, INSERT ON `dat`.`my``table` TO ?@? WITH GRANT OPTION", a.username, hostname),
+					fmt.Sprint("GRANT EXECUTE ON FUNCTION `dat`.`calc` TO ?@?", a.username, hostname),
+
+					fmt.Sprint("REVOKE PROCESS ON *.* FROM ?@?", a.username, hostname),
+					fmt.Sprint("REVOKE EXECUTE ON `dat`.* FROM ?@?", a.username, hostname),
+					fmt.Sprint("REVOKE SELECT, INSERT ON `dat`.`users` FROM ?@?", a.username, hostname),
+					fmt.Sprint("REVOKE SELECT, INSERT ON `dat`.`my``table` FROM ?@?", a.username, hostname),
+					fmt.Sprint("REVOKE EXECUTE ON FUNCTION `dat`.`calc` FROM ?@?", a.username, hostname),
+
+					fmt.Sprint(`DROP USER IF EXISTS ?@?`, a.username, hostname),
 				}
 			},
 		},
@@ -123,3 +197,387 @@ func TestPostgresql(t *testing.T) {
 		})
 	}
 }
+
+func TestMysql_InvalidObjectPrivileges(t *testing.T) {
+	privileges := []v1alpha1.PrivilegeSpec{
+		{ObjectType: v1alpha1.ObjectTable, Database: "dat", Privileges: []v1alpha1.PrivilegeType{"SELECT ON *.* TO"}},
+		{ObjectType: v1alpha1.ObjectFunction, Database: "dat", Privileges: []v1alpha1.PrivilegeType{v1alpha1.EXECUTE}},
+		{ObjectType: v1alpha1.ObjectSequence, Database: "dat", Privileges: []v1alpha1.PrivilegeType{v1alpha1.USAGE}},
+		{ObjectType: v1alpha1.ObjectTable, Database: "dat", Privileges: []v1alpha1.PrivilegeType{v1alpha1.SELECT}, DefaultPrivileges: &v1alpha1.DefaultPrivileges{}},
+	}
+
+	mockDB := connection.NewFakeConnection()
+	m := mysql.NewMysql(mockDB, mysql.NewConfig("mysql", 3306, "user", "password", "dbname", nil), logr.Discard())
+	for _, privilege := range privileges {
+		if err := m.ApplyPrivileges(context.Background(), "john", []v1alpha1.PrivilegeSpec{privilege}); !errors.Is(err, v1alpha1.ErrInvalidPrivilege) {
+			t.Errorf("Mysql.ApplyPrivileges() error = %v, want %v", err, v1alpha1.ErrInvalidPrivilege)
+		}
+	}
+
+	if queries := mockDB.Queries(); len(queries) != 0 {
+		t.Errorf("Queries executed for invalid privileges: %v", queries)
+	}
+}
+
+func TestMysql_Roles(t *testing.T) {
+	ctx := context.Background()
+	mockDB := connection.NewFakeConnection()
+	m := mysql.NewMysql(mockDB, mysql.NewConfig("mysql", 3306, "user", "password", "dbname", nil), logr.Discard())
+	if err := m.Connect(ctx); err != nil {
+		t.Fatalf("Mysql.Connect() error = %v", err)
+	}
+	defer m.Close(ctx)
+
+	roles := []v1alpha1.RoleGrant{
+		{Name: "readers", Default: true},
+		{Name: "writers", AdminOption: true},
+		{Name: "auditors", Default: true},
+	}
+	if err := m.GrantRoles(ctx, "john", roles); err != nil {
+		t.Errorf("Mysql.GrantRoles() error = %v", err)
+	}
+	if err := m.RevokeRoles(ctx, "john", roles[:1]); err != nil {
+		t.Errorf("Mysql.RevokeRoles() error = %v", err)
+	}
+	if err := m.CreateRole(ctx, "app_readonly"); err != nil {
+		t.Errorf("Mysql.CreateRole() error = %v", err)
+	}
+	privileges := []v1alpha1.PrivilegeSpec{{ObjectType: v1alpha1.ObjectDatabase, Database: "dat", Privileges: []v1alpha1.PrivilegeType{v1alpha1.SELECT}}}
+	if err := m.WithUsersHostnames([]string{"localhost"}).ApplyRolePrivileges(ctx, "app_readonly", privileges); err != nil {
+		t.Errorf("Mysql.ApplyRolePrivileges() error = %v", err)
+	}
+	if err := m.DeleteRole(ctx, "app_readonly"); err != nil {
+		t.Errorf("Mysql.DeleteRole() error = %v", err)
+	}
+
+	expectedQueries := []string{
+		fmt.Sprint("GRANT ? TO ?@?", "readers", "john", "%"),
+		fmt.Sprint("GRANT ? TO ?@? WITH ADMIN OPTION", "writers", "john", "%"),
+		fmt.Sprint("GRANT ? TO ?@?", "auditors", "john", "%"),
+		fmt.Sprint("SET DEFAULT ROLE ?, ? TO ?@?", "readers", "auditors", "john", "%"),
+		fmt.Sprint("REVOKE ? FROM ?@?", "readers", "john", "%"),
+		fmt.Sprint("CREATE ROLE IF NOT EXISTS ?", "app_readonly"),
+		fmt.Sprint("GRANT SELECT ON `dat`.* TO ?@?", "app_readonly", "%"),
+		fmt.Sprint("DROP ROLE IF EXISTS ?", "app_readonly"),
+	}
+	actualQueries := mockDB.Queries()
+	for i, query := range expectedQueries {
+		if actualQueries[query] != i+1 {
+			t.Errorf("Query not executed or executed out of order: '%s', %d", query, i+1)
+		}
+	}
+	if len(expectedQueries) != len(actualQueries) {
+		t.Errorf("Count of executed queries doesn't match: expected=%d, acrual=%d", len(expectedQueries), len(actualQueries))
+	}
+}
+
+func TestMysql_AlterUser(t *testing.T) {
+	expireDays := int32(90)
+	neverExpire := int32(0)
+
+	tests := []struct {
+		name     string
+		password string
+		options  v1alpha1.UserOptions
+		want     string
+		wantErr  bool
+	}{
+		{
+			name: "Reset to defaults",
+			want: fmt.Sprint("ALTER USER ?@? REQUIRE NONE WITH MAX_QUERIES_PER_HOUR ? MAX_UPDATES_PER_HOUR ? MAX_CONNECTIONS_PER_HOUR ? MAX_USER_CONNECTIONS ? PASSWORD EXPIRE DEFAULT ACCOUNT UNLOCK",
+				"john", "%", int32(0), int32(0), int32(0), int32(0)),
+		},
+		{
+			name:     "Set plugin, TLS and limits",
+			password: "secret",
+			options: v1alpha1.UserOptions{MySQL: &v1alpha1.MySQLUserOptions{
+				AuthPlugin: "caching_sha2_password",
+				MySQLAccountOptions: v1alpha1.MySQLAccountOptions{
+					RequireTLS:         "X509",
+					MaxQueriesPerHour:  100,
+					MaxUserConnections: 5,
+					PasswordExpireDays: &expireDays,
+					AccountLocked:      true,
+				},
+			}},
+			want: fmt.Sprint("ALTER USER ?@? IDENTIFIED WITH caching_sha2_password BY ? REQUIRE X509 WITH MAX_QUERIES_PER_HOUR ? MAX_UPDATES_PER_HOUR ? MAX_CONNECTIONS_PER_HOUR ? MAX_USER_CONNECTIONS ? PASSWORD EXPIRE INTERVAL ? DAY ACCOUNT LOCK",
+				"john", "%", "secret", int32(100), int32(0), int32(0), int32(5), int32(90)),
+		},
+		{
+			name: "Keep plugin without password",
+			options: v1alpha1.UserOptions{MySQL: &v1alpha1.MySQLUserOptions{
+				AuthPlugin: "mysql_native_password",
+				MySQLAccountOptions: v1alpha1.MySQLAccountOptions{
+					RequireSubject:     "/CN=john",
+					RequireIssuer:      "/CN=ca",
+					PasswordExpireDays: &neverExpire,
+				},
+			}},
+			want: fmt.Sprint("ALTER USER ?@? REQUIRE SUBJECT ? AND ISSUER ? WITH MAX_QUERIES_PER_HOUR ? MAX_UPDATES_PER_HOUR ? MAX_CONNECTIONS_PER_HOUR ? MAX_USER_CONNECTIONS ? PASSWORD EXPIRE NEVER ACCOUNT UNLOCK",
+				"john", "%", "/CN=john", "/CN=ca", int32(0), int32(0), int32(0), int32(0)),
+		},
+		{
+			name:     "Socket authentication",
+			password: "secret",
+			options:  v1alpha1.UserOptions{MySQL: &v1alpha1.MySQLUserOptions{AuthPlugin: "auth_socket"}},
+			want: fmt.Sprint("ALTER USER ?@? IDENTIFIED WITH auth_socket REQUIRE NONE WITH MAX_QUERIES_PER_HOUR ? MAX_UPDATES_PER_HOUR ? MAX_CONNECTIONS_PER_HOUR ? MAX_USER_CONNECTIONS ? PASSWORD EXPIRE DEFAULT ACCOUNT UNLOCK",
+				"john", "%", int32(0), int32(0), int32(0), int32(0)),
+		},
+		{
+			name:    "Unsupported plugin",
+			options: v1alpha1.UserOptions{MySQL: &v1alpha1.MySQLUserOptions{AuthPlugin: "sha256_password; DROP"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			mockDB := connection.NewFakeConnection()
+			m := mysql.NewMysql(mockDB, mysql.NewConfig("mysql", 3306, "user", "password", "dbname", nil), logr.Discard())
+			if err := m.Connect(ctx); err != nil {
+				t.Fatalf("Mysql.Connect() error = %v", err)
+			}
+			defer m.Close(ctx)
+
+			if err := m.AlterUser(ctx, "john", tt.password, tt.options, v1alpha1.UserOptions{}); (err != nil) != tt.wantErr {
+				t.Fatalf("Mysql.AlterUser() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			queries := mockDB.Queries()
+			if tt.wantErr {
+				if len(queries) != 0 {
+					t.Errorf("Mysql.AlterUser() queries = %v, want none", queries)
+				}
+				return
+			}
+			if len(queries) != 1 || queries[tt.want] != 1 {
+				t.Errorf("Mysql.AlterUser() queries = %v, want %s", queries, tt.want)
+			}
+		})
+	}
+}
+
+func TestMysql_SetPassword(t *testing.T) {
+	ctx := context.Background()
+	mockDB := connection.NewFakeConnection()
+	m := mysql.NewMysql(mockDB, mysql.NewConfig("mysql", 3306, "user", "password", "dbname", nil), logr.Discard())
+	if err := m.Connect(ctx); err != nil {
+		t.Fatalf("Mysql.Connect() error = %v", err)
+	}
+	defer m.Close(ctx)
+
+	if err := m.SetPassword(ctx, "john", "newpass"); err != nil {
+		t.Errorf("Mysql.SetPassword() error = %v", err)
+	}
+
+	want := fmt.Sprint("ALTER USER ?@? IDENTIFIED BY ?", "john", "%", "newpass")
+	if queries := mockDB.Queries(); len(queries) != 1 || queries[want] != 1 {
+		t.Errorf("Mysql.SetPassword() queries = %v, want %s", queries, want)
+	}
+}
+
+func TestMysql_UsersHostnamesOverride(t *testing.T) {
+	ctx := context.Background()
+	mockDB := connection.NewFakeConnection()
+	base := mysql.NewMysql(mockDB, mysql.NewConfig("mysql", 3306, "user", "password", "dbname", []string{"localhost"}), logr.Discard())
+	if err := base.Connect(ctx); err != nil {
+		t.Fatalf("Mysql.Connect() error = %v", err)
+	}
+	defer base.Close(ctx)
+
+	m := base.WithUsersHostnames([]string{"%", "10.0.%"})
+
+	// User already exists for "%", so only password is set for it.
+	mockDB.SetResult([]string{"%", "localhost"}, "SELECT host FROM mysql.user WHERE user = ?", "john")
+
+	exists, err := m.UserExists(ctx, "john")
+	if err != nil || exists {
+		t.Errorf("Mysql.UserExists() = %v, %v, want false, nil", exists, err)
+	}
+	if _, err := m.CreateUser(ctx, "john", "pass"); err != nil {
+		t.Errorf("Mysql.CreateUser() error = %v", err)
+	}
+	if err := m.GrantRoles(ctx, "john", []v1alpha1.RoleGrant{{Name: "readers"}}); err != nil {
+		t.Errorf("Mysql.GrantRoles() error = %v", err)
+	}
+	if err := base.DeleteUser(ctx, "john"); err != nil {
+		t.Errorf("Mysql.DeleteUser() error = %v", err)
+	}
+
+	expectedQueries := []string{
+		fmt.Sprint("ALTER USER ?@? IDENTIFIED BY ?", "john", "%", "pass"),
+		fmt.Sprint("CREATE USER ?@? IDENTIFIED BY ?", "john", "10.0.%", "pass"),
+		fmt.Sprint("GRANT ? TO ?@?", "readers", "john", "%"),
+		fmt.Sprint("GRANT ? TO ?@?", "readers", "john", "10.0.%"),
+		fmt.Sprint("DROP USER IF EXISTS ?@?", "john", "localhost"),
+	}
+	actualQueries := mockDB.Queries()
+	for i, query := range expectedQueries {
+		if actualQueries[query] != i+1 {
+			t.Errorf("Query not executed or executed out of order: '%s', %d", query, i+1)
+		}
+	}
+	if len(expectedQueries) != len(actualQueries) {
+		t.Errorf("Count of executed queries doesn't match: expected=%d, acrual=%d", len(expectedQueries), len(actualQueries))
+	}
+}
+
+func TestMysql_Introspection(t *testing.T) {
+	ctx := context.Background()
+	username := "john"
+
+	mockDB := connection.NewFakeConnection()
+	m := mysql.NewMysql(mockDB, mysql.NewConfig("mysql", 3306, "user", "password", "dbname", nil), logr.Discard())
+	if err := m.Connect(ctx); err != nil {
+		t.Fatalf("Mysql.Connect() error = %v", err)
+	}
+	defer m.Close(ctx)
+
+	exists, err := m.UserExists(ctx, username)
+	if err != nil || exists {
+		t.Errorf("Mysql.UserExists() = %v, %v, want false, nil", exists, err)
+	}
+
+	mockDB.SetResult([]string{"localhost", "%"}, "SELECT host FROM mysql.user WHERE user = ?", username)
+	mockDB.SetResult([]string{
+		"GRANT USAGE ON *.* TO `john`@`%`",
+		"GRANT RELOAD ON *.* TO `john`@`%`",
+		"GRANT SELECT, INSERT ON `dat`.* TO `john`@`%`",
+		"GRANT SELECT (`id`, `name`), UPDATE ON `dat`.`my``table` TO `john`@`%` WITH GRANT OPTION",
+		"GRANT `rolename`@`%`,`another`@`%` TO `john`@`%`",
+		"GRANT PROXY ON ''@'' TO `john`@`%`",
+	}, "SHOW GRANTS FOR ?@?", username, "%")
+
+	exists, err = m.UserExists(ctx, username)
+	if err != nil || !exists {
+		t.Errorf("Mysql.UserExists() = %v, %v, want true, nil", exists, err)
+	}
+
+	privileges, err := m.ListPrivileges(ctx, username)
+	if err != nil {
+		t.Fatalf("Mysql.ListPrivileges() error = %v", err)
+	}
+
+	want := []v1alpha1.PrivilegeSpec{
+		{Privilege: "RELOAD", Database: "*"},
+		{Privilege: "SELECT", Database: "dat"},
+		{Privilege: "INSERT", Database: "dat"},
+		{Privilege: "SELECT (`id`, `name`)", Database: "dat", On: "my`table"},
+		{Privilege: "UPDATE", Database: "dat", On: "my`table"},
+		{Privilege: "rolename"},
+		{Privilege: "another"},
+	}
+	if !reflect.DeepEqual(privileges, want) {
+		t.Errorf("Mysql.ListPrivileges() = %v, want %v", privileges, want)
+	}
+}
+
+func TestMysql_ListPrivileges(t *testing.T) {
+	tests := []struct {
+		name   string
+		grants []string
+		want   []v1alpha1.PrivilegeSpec
+	}{
+		{
+			name:   "Only usage",
+			grants: []string{"GRANT USAGE ON *.* TO `john`@`%`"},
+		},
+		{
+			name: "Routines and all privileges",
+			grants: []string{
+				"GRANT ALL PRIVILEGES ON `dat`.* TO `john`@`%`",
+				"GRANT EXECUTE ON FUNCTION `dat`.`calc` TO `john`@`%`",
+				"GRANT EXECUTE, ALTER ROUTINE ON PROCEDURE `dat`.`load` TO `john`@`%`",
+			},
+			want: []v1alpha1.PrivilegeSpec{
+				{Privilege: "ALL PRIVILEGES", Database: "dat"},
+				{Privilege: "EXECUTE", Database: "dat", On: "calc"},
+				{Privilege: "EXECUTE", Database: "dat", On: "load"},
+				{Privilege: "ALTER ROUTINE", Database: "dat", On: "load"},
+			},
+		},
+		{
+			name: "Quoted identifiers with separators",
+			grants: []string{
+				"GRANT SELECT ON `my.db`.`a,b` TO `john`@`%`",
+				"GRANT `team, readers`@`%` TO `john`@`%`",
+			},
+			want: []v1alpha1.PrivilegeSpec{
+				{Privilege: "SELECT", Database: "my.db", On: "a,b"},
+				{Privilege: "team, readers"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			mockDB := connection.NewFakeConnection()
+			m := mysql.NewMysql(mockDB, mysql.NewConfig("mysql", 3306, "user", "password", "dbname", []string{"10.0.0.%", "%"}), logr.Discard())
+			if err := m.Connect(ctx); err != nil {
+				t.Fatalf("Mysql.Connect() error = %v", err)
+			}
+			defer m.Close(ctx)
+
+			// Grants are read for the first host pattern, as privileges are applied to all of them.
+			mockDB.SetResult(tt.grants, "SHOW GRANTS FOR ?@?", "john", "10.0.0.%")
+			privileges, err := m.ListPrivileges(ctx, "john")
+			if err != nil {
+				t.Fatalf("Mysql.ListPrivileges() error = %v", err)
+			}
+			if !reflect.DeepEqual(privileges, tt.want) {
+				t.Errorf("Mysql.ListPrivileges() = %v, want %v", privileges, tt.want)
+			}
+		})
+	}
+}
+
+func TestMysql_UserExistsForAllHosts(t *testing.T) {
+	ctx := context.Background()
+	mockDB := connection.NewFakeConnection()
+	m := mysql.NewMysql(mockDB, mysql.NewConfig("mysql", 3306, "user", "password", "dbname", []string{"10.0.0.%", "%"}), logr.Discard())
+	if err := m.Connect(ctx); err != nil {
+		t.Fatalf("Mysql.Connect() error = %v", err)
+	}
+	defer m.Close(ctx)
+
+	mockDB.SetResult([]string{"%"}, "SELECT host FROM mysql.user WHERE user = ?", "john")
+	if exists, err := m.UserExists(ctx, "john"); err != nil || exists {
+		t.Errorf("Mysql.UserExists() = %v, %v, want false, nil for user missing one of host patterns", exists, err)
+	}
+
+	mockDB.SetResult([]string{"localhost", "%", "10.0.0.%"}, "SELECT host FROM mysql.user WHERE user = ?", "john")
+	if exists, err := m.UserExists(ctx, "john"); err != nil || !exists {
+		t.Errorf("Mysql.UserExists() = %v, %v, want true, nil", exists, err)
+	}
+}
+
+func TestMysql_ServerInfo(t *testing.T) {
+	ctx := context.Background()
+
+	mockDB := connection.NewFakeConnection()
+	m := mysql.NewMysql(mockDB, mysql.NewConfig("mysql", 3306, "user", "password", "dbname", nil), logr.Discard())
+	if err := m.Connect(ctx); err != nil {
+		t.Fatalf("Mysql.Connect() error = %v", err)
+	}
+	defer m.Close(ctx)
+
+	tls, err := m.TLSEnabled(ctx)
+	if !errors.Is(err, sql.ErrNoRows) || tls {
+		t.Errorf("Mysql.TLSEnabled() = %v, %v, want false, %v", tls, err, sql.ErrNoRows)
+	}
+
+	mockDB.SetResult("8.0.34", "SELECT VERSION()")
+	mockDB.SetResult("TLS_AES_256_GCM_SHA384", "SELECT VARIABLE_VALUE FROM performance_schema.session_status WHERE VARIABLE_NAME = 'Ssl_cipher'")
+
+	version, err := m.ServerVersion(ctx)
+	if err != nil || version != "8.0.34" {
+		t.Errorf("Mysql.ServerVersion() = %v, %v, want 8.0.34, nil", version, err)
+	}
+
+	tls, err = m.TLSEnabled(ctx)
+	if err != nil || !tls {
+		t.Errorf("Mysql.TLSEnabled() = %v, %v, want true, nil", tls, err)
+	}
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysql
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/alex123012/database-users-operator/api/v1alpha1"
+)
+
+const (
+	identifierPattern = "(?:`(?:[^`]|``)*`|\\*)"
+	usagePrivilege    = "USAGE"
+	proxyGrantPrefix  = "GRANT PROXY ON "
+)
+
+var (
+	objectGrantRegexp = regexp.MustCompile(`^GRANT (.+) ON (?:TABLE |FUNCTION |PROCEDURE )?(` + identifierPattern + `)\.(` + identifierPattern + `) TO `)
+	roleGrantRegexp   = regexp.MustCompile(`^GRANT (.+) TO `)
+)
+
+// parseGrant converts one row of "SHOW GRANTS" output to the list of privileges.
+// Global privileges are returned with "*" database.
+func parseGrant(grant string) []v1alpha1.PrivilegeSpec {
+	if strings.HasPrefix(grant, proxyGrantPrefix) {
+		return nil
+	}
+
+	if match := objectGrantRegexp.FindStringSubmatch(grant); match != nil {
+		dbname, on := unquoteIdentifier(match[2]), unquoteIdentifier(match[3])
+		if on == "*" {
+			on = ""
+		}
+
+		var privileges []v1alpha1.PrivilegeSpec
+		for _, privilege := range splitList(match[1]) {
+			if privilege == usagePrivilege {
+				continue
+			}
+			privileges = append(privileges, v1alpha1.PrivilegeSpec{Privilege: v1alpha1.PrivilegeType(privilege), Database: dbname, On: on})
+		}
+		return privileges
+	}
+
+	if match := roleGrantRegexp.FindStringSubmatch(grant); match != nil {
+		var privileges []v1alpha1.PrivilegeSpec
+		for _, role := range splitList(match[1]) {
+			name, _, _ := strings.Cut(role, "@")
+			privileges = append(privileges, v1alpha1.PrivilegeSpec{Privilege: v1alpha1.PrivilegeType(unquoteIdentifier(name))})
+		}
+		return privileges
+	}
+	return nil
+}
+
+// splitList splits comma separated list, ignoring commas inside parentheses (column lists) and quotes.
+func splitList(list string) []string {
+	var (
+		items  []string
+		depth  int
+		quoted bool
+		start  int
+	)
+	for i, r := range list {
+		switch {
+		case r == '`':
+			quoted = !quoted
+		case quoted:
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case r == ',' && depth == 0:
+			items = append(items, strings.TrimSpace(list[start:i]))
+			start = i + 1
+		}
+	}
+	return append(items, strings.TrimSpace(list[start:]))
+}
+
+func quoteIdentifier(str string) string {
+	return "`" + strings.ReplaceAll(str, "`", "``") + "`"
+}
+
+func unquoteIdentifier(str string) string {
+	if len(str) > 1 && strings.HasPrefix(str, "`") && strings.HasSuffix(str, "`") {
+		str = strings.ReplaceAll(str[1:len(str)-1], "``", "`")
+	}
+	return str
+}
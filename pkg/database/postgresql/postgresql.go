@@ -24,8 +24,13 @@ import (
 	"crypto/x509/pkix"
 	"encoding/pem"
 	"errors"
+	"fmt"
+	"maps"
 	"math/big"
 	"math/rand"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -35,6 +40,16 @@ import (
 	"github.com/alex123012/database-users-operator/pkg/database/connection"
 )
 
+// defaultSchema is used for object privileges without schema.
+const defaultSchema = "public"
+
+var (
+	ErrInvalidParameter = errors.New("invalid configuration parameter name")
+	ErrRoleCanLogin     = errors.New("role with the same name exists and can login")
+
+	parameterNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+)
+
 type Postgresql struct {
 	db     connection.Connection
 	config *Config
@@ -63,6 +78,10 @@ func (p *Postgresql) Close(ctx context.Context) error {
 	return p.db.Close(ctx)
 }
 
+func (p *Postgresql) Ping(ctx context.Context) error {
+	return p.db.Ping(ctx)
+}
+
 func (p *Postgresql) CreateUser(ctx context.Context, username, password string) (map[string]string, error) {
 	// TODO (alex123012): use gorm.Statement, refer to https://gorm.io/docs/sql_builder.html#Clauses
 	query, logInfo := createUserQuery(username, password)
@@ -84,7 +103,7 @@ func createUserQuery(username, password string) (string, connection.LogInfo) {
 	logInfo := connection.EnableLogger
 	stmtBuilder := &strings.Builder{}
 	stmtBuilder.WriteString("CREATE USER ")
-	stmtBuilder.WriteString(escapeLiteral(username))
+	stmtBuilder.WriteString(quoteIdentifier(username))
 	if password != "" {
 		stmtBuilder.WriteString(" WITH PASSWORD ")
 		stmtBuilder.WriteString(escapeString(password))
@@ -93,6 +112,115 @@ func createUserQuery(username, password string) (string, connection.LogInfo) {
 	return stmtBuilder.String(), logInfo
 }
 
+func (p *Postgresql) SetPassword(ctx context.Context, username, password string) error {
+	query := setPasswordQuery(username, password)
+	return p.db.Exec(ctx, connection.DisableLogger, query)
+}
+
+func setPasswordQuery(username, password string) string {
+	stmtBuilder := &strings.Builder{}
+	stmtBuilder.WriteString("ALTER USER ")
+	stmtBuilder.WriteString(quoteIdentifier(username))
+	stmtBuilder.WriteString(" WITH PASSWORD ")
+	if password == "" {
+		stmtBuilder.WriteString("NULL")
+	} else {
+		stmtBuilder.WriteString(escapeString(password))
+	}
+	return stmtBuilder.String()
+}
+
+// AlterUser sets role attributes and replaces configuration parameters of the user.
+// Only attributes, that differ from the applied options, are set, because changing some of them
+// (e.g. REPLICATION and BYPASSRLS) requires privileges, that operator may not have.
+func (p *Postgresql) AlterUser(ctx context.Context, username, _ string, options, applied v1alpha1.UserOptions) error {
+	queries, err := alterUserQueries(username, postgresOptions(options), postgresOptions(applied))
+	if err != nil {
+		return err
+	}
+
+	for _, query := range queries {
+		if err := p.db.Exec(ctx, connection.EnableLogger, query); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func postgresOptions(options v1alpha1.UserOptions) *v1alpha1.PostgreSQLUserOptions {
+	if options.PostgreSQL == nil {
+		return &v1alpha1.PostgreSQLUserOptions{}
+	}
+	return options.PostgreSQL
+}
+
+func alterUserQueries(username string, options, applied *v1alpha1.PostgreSQLUserOptions) ([]string, error) {
+	attributes := []string{"LOGIN"}
+	attribute := func(enabled, wasEnabled bool, name string) {
+		switch {
+		case enabled == wasEnabled:
+		case enabled:
+			attributes = append(attributes, name)
+		default:
+			attributes = append(attributes, "NO"+name)
+		}
+	}
+	inherit := func(o *v1alpha1.PostgreSQLUserOptions) bool {
+		return o.Inherit == nil || *o.Inherit
+	}
+	connectionLimit := func(o *v1alpha1.PostgreSQLUserOptions) int32 {
+		if o.ConnectionLimit != nil {
+			return *o.ConnectionLimit
+		}
+		return -1
+	}
+	validUntil := func(o *v1alpha1.PostgreSQLUserOptions) string {
+		if o.ValidUntil != nil {
+			return o.ValidUntil.UTC().Format(time.RFC3339)
+		}
+		return "infinity"
+	}
+
+	attribute(options.CreateDB, applied.CreateDB, "CREATEDB")
+	attribute(options.CreateRole, applied.CreateRole, "CREATEROLE")
+	attribute(options.Replication, applied.Replication, "REPLICATION")
+	attribute(options.BypassRLS, applied.BypassRLS, "BYPASSRLS")
+	attribute(inherit(options), inherit(applied), "INHERIT")
+	if limit := connectionLimit(options); limit != connectionLimit(applied) {
+		attributes = append(attributes, "CONNECTION LIMIT "+strconv.Itoa(int(limit)))
+	}
+	if until := validUntil(options); until != validUntil(applied) {
+		attributes = append(attributes, "VALID UNTIL "+escapeString(until))
+	}
+
+	queries := []string{"ALTER ROLE " + quoteIdentifier(username) + " WITH " + strings.Join(attributes, " ")}
+	if maps.Equal(options.Parameters, applied.Parameters) {
+		return queries, nil
+	}
+
+	// Parameters are reset, so parameters removed from options don't stay set.
+	queries = append(queries, "ALTER ROLE "+quoteIdentifier(username)+" RESET ALL")
+
+	names := make([]string, 0, len(options.Parameters))
+	for name := range options.Parameters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if !parameterNameRegexp.MatchString(name) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidParameter, name)
+		}
+
+		values := strings.Split(options.Parameters[name], ",")
+		for i := range values {
+			values[i] = escapeString(strings.TrimSpace(values[i]))
+		}
+		queries = append(queries, "ALTER ROLE "+quoteIdentifier(username)+" SET "+name+" TO "+strings.Join(values, ", "))
+	}
+	return queries, nil
+}
+
 func (p *Postgresql) DeleteUser(ctx context.Context, username string) error {
 	// TODO (alex123012): use gorm.Statement, refer to https://gorm.io/docs/sql_builder.html#Clauses
 	query := deleteUserQuery(username)
@@ -102,10 +230,49 @@ func (p *Postgresql) DeleteUser(ctx context.Context, username string) error {
 func deleteUserQuery(username string) string {
 	stmtBuilder := &strings.Builder{}
 	stmtBuilder.WriteString("DROP USER ")
-	stmtBuilder.WriteString(escapeLiteral(username))
+	stmtBuilder.WriteString(quoteIdentifier(username))
 	return stmtBuilder.String()
 }
 
+// CreateRole creates role without login, that can be granted to users.
+// Existing role without login is reused, but role, that can login, is the user and is never taken over.
+func (p *Postgresql) CreateRole(ctx context.Context, name string) error {
+	query := "CREATE ROLE " + quoteIdentifier(name) + " NOLOGIN"
+	err := p.db.Exec(ctx, connection.EnableLogger, query)
+	if !isAlreadyExists(err) {
+		return err
+	}
+
+	canLogin, err := p.roleCanLogin(ctx, name)
+	if err != nil {
+		return err
+	}
+	if canLogin {
+		return fmt.Errorf("%w: %s", ErrRoleCanLogin, name)
+	}
+	return nil
+}
+
+// DeleteRole drops the role, role, that can login, is the user and is left as is.
+func (p *Postgresql) DeleteRole(ctx context.Context, name string) error {
+	canLogin, err := p.roleCanLogin(ctx, name)
+	if err != nil || canLogin {
+		return err
+	}
+
+	query := "DROP ROLE " + quoteIdentifier(name)
+	return ignoreNotExists(p.db.Exec(ctx, connection.EnableLogger, query))
+}
+
+func (p *Postgresql) roleCanLogin(ctx context.Context, name string) (bool, error) {
+	var canLogin []bool
+	query := "SELECT rolcanlogin FROM pg_roles WHERE rolname = $1"
+	if err := p.db.Select(ctx, connection.EnableLogger, &canLogin, query, name); err != nil {
+		return false, err
+	}
+	return len(canLogin) > 0 && canLogin[0], nil
+}
+
 func (p *Postgresql) ApplyPrivileges(ctx context.Context, username string, privileges []v1alpha1.PrivilegeSpec) error {
 	return p.privilegesProcessor(ctx, username, privileges, "GRANT", "TO")
 }
@@ -114,10 +281,67 @@ func (p *Postgresql) RevokePrivileges(ctx context.Context, username string, priv
 	return ignoreNotExists(p.privilegesProcessor(ctx, username, privileges, "REVOKE", "FROM"))
 }
 
+// ApplyRolePrivileges grants privileges to the role, roles and users are the same in PostgreSQL.
+func (p *Postgresql) ApplyRolePrivileges(ctx context.Context, name string, privileges []v1alpha1.PrivilegeSpec) error {
+	return p.ApplyPrivileges(ctx, name, privileges)
+}
+
+func (p *Postgresql) RevokeRolePrivileges(ctx context.Context, name string, privileges []v1alpha1.PrivilegeSpec) error {
+	return p.RevokePrivileges(ctx, name, privileges)
+}
+
+func (p *Postgresql) GrantRoles(ctx context.Context, username string, roles []v1alpha1.RoleGrant) error {
+	for _, role := range roles {
+		if err := p.db.Exec(ctx, connection.EnableLogger, prepareStatementForRole("GRANT", "TO", username, role)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Postgresql) RevokeRoles(ctx context.Context, username string, roles []v1alpha1.RoleGrant) error {
+	for _, role := range roles {
+		if err := ignoreNotExists(p.db.Exec(ctx, connection.EnableLogger, prepareStatementForRole("REVOKE", "FROM", username, role))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func prepareStatementForRole(statement, arg, username string, role v1alpha1.RoleGrant) string {
+	stmtBuilder := &strings.Builder{}
+	stmtBuilder.WriteString(statement)
+	stmtBuilder.WriteString(" ")
+	stmtBuilder.WriteString(quoteIdentifier(role.Name))
+	stmtBuilder.WriteString(" ")
+	stmtBuilder.WriteString(arg)
+	stmtBuilder.WriteString(" ")
+	stmtBuilder.WriteString(quoteIdentifier(username))
+	if statement != "GRANT" {
+		return stmtBuilder.String()
+	}
+
+	var options []string
+	if role.AdminOption {
+		options = append(options, "ADMIN OPTION")
+	}
+	if role.Inherit != nil {
+		options = append(options, "INHERIT "+strings.ToUpper(strconv.FormatBool(*role.Inherit)))
+	}
+	if len(options) > 0 {
+		stmtBuilder.WriteString(" WITH ")
+		stmtBuilder.WriteString(strings.Join(options, ", "))
+	}
+	return stmtBuilder.String()
+}
+
 func (p *Postgresql) privilegesProcessor(ctx context.Context, username string, privileges []v1alpha1.PrivilegeSpec, statement, arg string) error {
 	for _, privilege := range privileges {
 		var err error
 		switch {
+		case privilege.ObjectType != "":
+			err = p.objectPrivilege(ctx, username, privilege, statement, arg)
+
 		case privilege.Database != "" && privilege.On != "" && privilege.Privilege != "":
 			err = p.inDatabasePrivilege(ctx, username, privilege.Database, privilege.On, privilege.Privilege, statement, arg)
 
@@ -139,6 +363,24 @@ func (p *Postgresql) privilegesProcessor(ctx context.Context, username string, p
 }
 
 func (p *Postgresql) inDatabasePrivilege(ctx context.Context, username, dbname, on string, privilege v1alpha1.PrivilegeType, statement, arg string) error {
+	query := prepareStatementForPrivilege(statement, arg, username, dbname, on, privilege)
+	return p.execInDatabase(ctx, dbname, query)
+}
+
+func (p *Postgresql) objectPrivilege(ctx context.Context, username string, privilege v1alpha1.PrivilegeSpec, statement, arg string) error {
+	query, err := prepareStatementForObjectPrivilege(statement, arg, username, privilege)
+	if err != nil {
+		return err
+	}
+
+	if privilege.ObjectType == v1alpha1.ObjectDatabase {
+		return p.db.Exec(ctx, connection.EnableLogger, query)
+	}
+	return p.execInDatabase(ctx, privilege.Database, query)
+}
+
+// execInDatabase executes query in the new connection to the specified database.
+func (p *Postgresql) execInDatabase(ctx context.Context, dbname, query string) error {
 	newconf := p.config.Copy()
 	newconf.DatabaseName = dbname
 	conn := p.db.Copy()
@@ -147,7 +389,6 @@ func (p *Postgresql) inDatabasePrivilege(ctx context.Context, username, dbname,
 		return err
 	}
 	defer newP.Close(ctx)
-	query := prepareStatementForPrivilege(statement, arg, username, dbname, on, privilege)
 	return newP.db.Exec(ctx, connection.EnableLogger, query)
 }
 
@@ -167,7 +408,12 @@ func prepareStatementForPrivilege(statement, arg, username, dbname, on string, p
 	stmtBuilder.WriteString(" ")
 	stmtBuilder.WriteString(escapeLiteralWithoutQuotes(string(privilege)))
 
-	if on != "" {
+	if match := v1alpha1.PostgresAllInSchemaRegexp.FindStringSubmatch(on); match != nil {
+		stmtBuilder.WriteString(" ON ALL ")
+		stmtBuilder.WriteString(strings.ToUpper(match[1]))
+		stmtBuilder.WriteString(" IN SCHEMA ")
+		stmtBuilder.WriteString(escapeLiteral(match[2]))
+	} else if on != "" {
 		stmtBuilder.WriteString(" ON ")
 		stmtBuilder.WriteString(escapeLiteral(on))
 	} else if dbname != "" {
@@ -178,10 +424,241 @@ func prepareStatementForPrivilege(statement, arg, username, dbname, on string, p
 	stmtBuilder.WriteString(" ")
 	stmtBuilder.WriteString(arg)
 	stmtBuilder.WriteString(" ")
-	stmtBuilder.WriteString(escapeLiteral(username))
+	stmtBuilder.WriteString(quoteIdentifier(username))
 	return stmtBuilder.String()
 }
 
+func prepareStatementForObjectPrivilege(statement, arg, username string, privilege v1alpha1.PrivilegeSpec) (string, error) {
+	privileges, err := privilege.PrivilegesList()
+	if err != nil {
+		return "", err
+	}
+
+	schema := privilege.Schema
+	if schema == "" {
+		schema = defaultSchema
+	}
+
+	if privilege.DefaultPrivileges != nil {
+		return prepareStatementForDefaultPrivilege(statement, arg, username, schema, privileges, privilege)
+	}
+
+	stmtBuilder := &strings.Builder{}
+	stmtBuilder.WriteString(statement)
+	stmtBuilder.WriteString(" ")
+	stmtBuilder.WriteString(privileges)
+	stmtBuilder.WriteString(" ON ")
+
+	switch privilege.ObjectType {
+	case v1alpha1.ObjectDatabase:
+		stmtBuilder.WriteString("DATABASE ")
+		stmtBuilder.WriteString(quoteIdentifier(privilege.Database))
+
+	case v1alpha1.ObjectSchema:
+		stmtBuilder.WriteString("SCHEMA ")
+		stmtBuilder.WriteString(quoteIdentifier(schema))
+
+	case v1alpha1.ObjectTable, v1alpha1.ObjectSequence, v1alpha1.ObjectFunction:
+		objectType := strings.ToUpper(string(privilege.ObjectType))
+		if privilege.AllObjects() {
+			stmtBuilder.WriteString("ALL ")
+			stmtBuilder.WriteString(objectType)
+			stmtBuilder.WriteString("S IN SCHEMA ")
+			stmtBuilder.WriteString(quoteIdentifier(schema))
+			break
+		}
+
+		objects := make([]string, 0, len(privilege.Objects))
+		for _, object := range privilege.Objects {
+			objects = append(objects, quoteIdentifier(schema)+"."+quoteIdentifier(object))
+		}
+		stmtBuilder.WriteString(objectType)
+		stmtBuilder.WriteString(" ")
+		stmtBuilder.WriteString(strings.Join(objects, ", "))
+
+	default:
+		return "", fmt.Errorf("%w: objectType %s is not supported by PostgreSQL", v1alpha1.ErrInvalidPrivilege, privilege.ObjectType)
+	}
+
+	stmtBuilder.WriteString(" ")
+	stmtBuilder.WriteString(arg)
+	stmtBuilder.WriteString(" ")
+	stmtBuilder.WriteString(quoteIdentifier(username))
+	if privilege.WithGrantOption && statement == "GRANT" {
+		stmtBuilder.WriteString(" WITH GRANT OPTION")
+	}
+	return stmtBuilder.String(), nil
+}
+
+// prepareStatementForDefaultPrivilege renders "ALTER DEFAULT PRIVILEGES" statement,
+// so privileges are given on objects created in the schema after the grant.
+func prepareStatementForDefaultPrivilege(statement, arg, username, schema, privileges string, privilege v1alpha1.PrivilegeSpec) (string, error) {
+	switch privilege.ObjectType {
+	case v1alpha1.ObjectTable, v1alpha1.ObjectSequence, v1alpha1.ObjectFunction:
+	default:
+		return "", fmt.Errorf("%w: default privileges can't be given on objectType %s", v1alpha1.ErrInvalidPrivilege, privilege.ObjectType)
+	}
+	if !privilege.AllObjects() {
+		return "", fmt.Errorf("%w: objects can't be set for default privileges", v1alpha1.ErrInvalidPrivilege)
+	}
+
+	stmtBuilder := &strings.Builder{}
+	stmtBuilder.WriteString("ALTER DEFAULT PRIVILEGES")
+	if privilege.DefaultPrivileges.ForRole != "" {
+		stmtBuilder.WriteString(" FOR ROLE ")
+		stmtBuilder.WriteString(quoteIdentifier(privilege.DefaultPrivileges.ForRole))
+	}
+	stmtBuilder.WriteString(" IN SCHEMA ")
+	stmtBuilder.WriteString(quoteIdentifier(schema))
+	stmtBuilder.WriteString(" ")
+	stmtBuilder.WriteString(statement)
+	stmtBuilder.WriteString(" ")
+	stmtBuilder.WriteString(privileges)
+	stmtBuilder.WriteString(" ON ")
+	stmtBuilder.WriteString(strings.ToUpper(string(privilege.ObjectType)))
+	stmtBuilder.WriteString("S ")
+	stmtBuilder.WriteString(arg)
+	stmtBuilder.WriteString(" ")
+	stmtBuilder.WriteString(quoteIdentifier(username))
+	if privilege.WithGrantOption && statement == "GRANT" {
+		stmtBuilder.WriteString(" WITH GRANT OPTION")
+	}
+	return stmtBuilder.String(), nil
+}
+
+func (p *Postgresql) UserExists(ctx context.Context, username string) (bool, error) {
+	var exists []bool
+	query := "SELECT true FROM pg_roles WHERE rolname = $1"
+	if err := p.db.Select(ctx, connection.EnableLogger, &exists, query, username); err != nil {
+		return false, err
+	}
+	return len(exists) > 0, nil
+}
+
+func (p *Postgresql) ServerVersion(ctx context.Context) (string, error) {
+	var version string
+	query := "SELECT current_setting('server_version')"
+	if err := p.db.Get(ctx, connection.EnableLogger, &version, query); err != nil {
+		return "", err
+	}
+	return version, nil
+}
+
+func (p *Postgresql) TLSEnabled(ctx context.Context) (bool, error) {
+	var ssl bool
+	query := "SELECT ssl FROM pg_stat_ssl WHERE pid = pg_backend_pid()"
+	if err := p.db.Get(ctx, connection.EnableLogger, &ssl, query); err != nil {
+		return false, err
+	}
+	return ssl, nil
+}
+
+// ListPrivileges returns roles granted to the user, privileges on databases
+// and privileges on tables in all databases that allow connections.
+func (p *Postgresql) ListPrivileges(ctx context.Context, username string) ([]v1alpha1.PrivilegeSpec, error) {
+	privileges, err := p.listRoles(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	databasePrivileges, err := p.listDatabasePrivileges(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	privileges = append(privileges, databasePrivileges...)
+
+	var databases []string
+	query := "SELECT datname FROM pg_database WHERE datallowconn AND NOT datistemplate"
+	if err := p.db.Select(ctx, connection.EnableLogger, &databases, query); err != nil {
+		return nil, err
+	}
+
+	for _, dbname := range databases {
+		tablePrivileges, err := p.listTablePrivileges(ctx, username, dbname)
+		if err != nil {
+			return nil, err
+		}
+		privileges = append(privileges, tablePrivileges...)
+	}
+	return privileges, nil
+}
+
+func (p *Postgresql) listRoles(ctx context.Context, username string) ([]v1alpha1.PrivilegeSpec, error) {
+	var roles []string
+	query := "SELECT r.rolname FROM pg_auth_members m JOIN pg_roles r ON r.oid = m.roleid JOIN pg_roles u ON u.oid = m.member WHERE u.rolname = $1"
+	if err := p.db.Select(ctx, connection.EnableLogger, &roles, query, username); err != nil {
+		return nil, err
+	}
+
+	privileges := make([]v1alpha1.PrivilegeSpec, 0, len(roles))
+	for _, role := range roles {
+		privileges = append(privileges, v1alpha1.PrivilegeSpec{Privilege: v1alpha1.PrivilegeType(role)})
+	}
+	return privileges, nil
+}
+
+type databaseGrant struct {
+	Database  string `db:"datname"`
+	Privilege string `db:"privilege_type"`
+}
+
+func (p *Postgresql) listDatabasePrivileges(ctx context.Context, username string) ([]v1alpha1.PrivilegeSpec, error) {
+	// has_database_privilege also reports privileges granted to PUBLIC,
+	// so only privileges granted to the user directly are taken from ACL.
+	var grants []databaseGrant
+	query := "SELECT d.datname, a.privilege_type FROM pg_database d CROSS JOIN LATERAL aclexplode(d.datacl) a JOIN pg_roles r ON r.oid = a.grantee WHERE r.rolname = $1"
+	if err := p.db.Select(ctx, connection.EnableLogger, &grants, query, username); err != nil {
+		return nil, err
+	}
+
+	privileges := make([]v1alpha1.PrivilegeSpec, 0, len(grants))
+	for _, grant := range grants {
+		privileges = append(privileges, v1alpha1.PrivilegeSpec{
+			Privilege: v1alpha1.PrivilegeType(grant.Privilege),
+			Database:  grant.Database,
+		})
+	}
+	return privileges, nil
+}
+
+type tableGrant struct {
+	Privilege string `db:"privilege_type"`
+	Database  string `db:"table_catalog"`
+	Schema    string `db:"table_schema"`
+	Table     string `db:"table_name"`
+}
+
+func (p *Postgresql) listTablePrivileges(ctx context.Context, username, dbname string) ([]v1alpha1.PrivilegeSpec, error) {
+	// information_schema contains only objects from the database of current connection.
+	conn := p.db
+	if dbname != p.config.DatabaseName {
+		newconf := p.config.Copy()
+		newconf.DatabaseName = dbname
+		newP := NewPostgresql(p.db.Copy(), newconf, p.logger)
+		if err := newP.Connect(ctx); err != nil {
+			return nil, err
+		}
+		defer newP.Close(ctx)
+		conn = newP.db
+	}
+
+	var grants []tableGrant
+	query := "SELECT privilege_type, table_catalog, table_schema, table_name FROM information_schema.role_table_grants WHERE grantee = $1"
+	if err := conn.Select(ctx, connection.EnableLogger, &grants, query, username); err != nil {
+		return nil, err
+	}
+
+	privileges := make([]v1alpha1.PrivilegeSpec, 0, len(grants))
+	for _, grant := range grants {
+		privileges = append(privileges, v1alpha1.PrivilegeSpec{
+			Privilege: v1alpha1.PrivilegeType(grant.Privilege),
+			On:        grant.Schema + "." + grant.Table,
+			Database:  grant.Database,
+		})
+	}
+	return privileges, nil
+}
+
 func (p *Postgresql) genPostgresCertFromCA(userName string) (map[string]string, error) {
 	caKeyBlock, _ := pem.Decode([]byte(p.config.SSLCAKey))
 	caPrivKey, err := x509.ParsePKCS1PrivateKey(caKeyBlock.Bytes)
@@ -22,9 +22,13 @@ import (
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/go-logr/logr"
+	"github.com/jackc/pgx/v5/pgconn"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/alex123012/database-users-operator/api/v1alpha1"
 	"github.com/alex123012/database-users-operator/pkg/database/connection"
@@ -120,6 +124,90 @@ func TestPostgresql(t *testing.T) {
 				}
 			},
 		},
+
+		{
+			name: "Apply privileges on all tables in schema",
+			fields: fields{
+				config: postgresql.NewConfig("postgres", 5432, "user", "password", "dbname", "disable", "", "", "", ""),
+				logger: logr.Discard(),
+			},
+			args: args{
+				ctx:      context.Background(),
+				username: "john",
+				privileges: []v1alpha1.PrivilegeSpec{
+					{Privilege: "SELECT, INSERT", On: "all tables in schema public", Database: "dat"},
+				},
+			},
+			queryList: func(a args) []string {
+				return []string{
+					fmt.Sprintf(`CREATE USER "%s"`, a.username),
+					fmt.Sprintf(`GRANT SELECT, INSERT ON ALL TABLES IN SCHEMA "public" TO "%s"`, a.username),
+					fmt.Sprintf(`REVOKE SELECT, INSERT ON ALL TABLES IN SCHEMA "public" FROM "%s"`, a.username),
+					fmt.Sprintf(`DROP USER "%s"`, a.username),
+				}
+			},
+		},
+
+		{
+			name: "Apply structured object privileges",
+			fields: fields{
+				config: postgresql.NewConfig("postgres", 5432, "user", "password", "dbname", "disable", "", "", "", ""),
+				logger: logr.Discard(),
+			},
+			args: args{
+				ctx:      context.Background(),
+				username: "john",
+				privileges: []v1alpha1.PrivilegeSpec{
+					{ObjectType: v1alpha1.ObjectTable, Database: "dat", Schema: "app", Objects: []string{"users", `my"table`}, Privileges: []v1alpha1.PrivilegeType{"select", "INSERT"}, WithGrantOption: true},
+					{ObjectType: v1alpha1.ObjectSequence, Database: "dat", Privileges: []v1alpha1.PrivilegeType{v1alpha1.USAGE}},
+					{ObjectType: v1alpha1.ObjectFunction, Database: "dat", Objects: []string{"calc.total"}, Privileges: []v1alpha1.PrivilegeType{v1alpha1.EXECUTE}},
+					{ObjectType: v1alpha1.ObjectSchema, Database: "dat", Schema: "app", Privileges: []v1alpha1.PrivilegeType{v1alpha1.USAGE}},
+					{ObjectType: v1alpha1.ObjectDatabase, Database: "dat", Privileges: []v1alpha1.PrivilegeType{v1alpha1.CONNECT}},
+				},
+			},
+			queryList: func(a args) []string {
+				return []string{
+					fmt.Sprintf(`CREATE USER "%s"`, a.username),
+					fmt.Sprintf(`GRANT SELECT, INSERT ON TABLE "app"."users", "app"."my""table" TO "%s" WITH GRANT OPTION`, a.username),
+					fmt.Sprintf(`GRANT USAGE ON ALL SEQUENCES IN SCHEMA "public" TO "%s"`, a.username),
+					fmt.Sprintf(`GRANT EXECUTE ON FUNCTION "public"."calc.total" TO "%s"`, a.username),
+					fmt.Sprintf(`GRANT USAGE ON SCHEMA "app" TO "%s"`, a.username),
+					fmt.Sprintf(`GRANT CONNECT ON DATABASE "dat" TO "%s"`, a.username),
+					fmt.Sprintf(`REVOKE SELECT, INSERT ON TABLE "app"."users", "app"."my""table" FROM "%s"`, a.username),
+					fmt.Sprintf(`REVOKE USAGE ON ALL SEQUENCES IN SCHEMA "public" FROM "%s"`, a.username),
+					fmt.Sprintf(`REVOKE EXECUTE ON FUNCTION "public"."calc.total" FROM "%s"`, a.username),
+					fmt.Sprintf(`REVOKE USAGE ON SCHEMA "app" FROM "%s"`, a.username),
+					fmt.Sprintf(`REVOKE CONNECT ON DATABASE "dat" FROM "%s"`, a.username),
+					fmt.Sprintf(`DROP USER "%s"`, a.username),
+				}
+			},
+		},
+
+		{
+			name: "Apply default privileges",
+			fields: fields{
+				config: postgresql.NewConfig("postgres", 5432, "user", "password", "dbname", "disable", "", "", "", ""),
+				logger: logr.Discard(),
+			},
+			args: args{
+				ctx:      context.Background(),
+				username: "john",
+				privileges: []v1alpha1.PrivilegeSpec{
+					{ObjectType: v1alpha1.ObjectTable, Database: "dat", Privileges: []v1alpha1.PrivilegeType{v1alpha1.SELECT}, DefaultPrivileges: &v1alpha1.DefaultPrivileges{}},
+					{ObjectType: v1alpha1.ObjectSequence, Database: "dat", Schema: "app", Privileges: []v1alpha1.PrivilegeType{v1alpha1.USAGE}, DefaultPrivileges: &v1alpha1.DefaultPrivileges{ForRole: "migrator"}, WithGrantOption: true},
+				},
+			},
+			queryList: func(a args) []string {
+				return []string{
+					fmt.Sprintf(`CREATE USER "%s"`, a.username),
+					fmt.Sprintf(`ALTER DEFAULT PRIVILEGES IN SCHEMA "public" GRANT SELECT ON TABLES TO "%s"`, a.username),
+					fmt.Sprintf(`ALTER DEFAULT PRIVILEGES FOR ROLE "migrator" IN SCHEMA "app" GRANT USAGE ON SEQUENCES TO "%s" WITH GRANT OPTION`, a.username),
+					fmt.Sprintf(`ALTER DEFAULT PRIVILEGES IN SCHEMA "public" REVOKE SELECT ON TABLES FROM "%s"`, a.username),
+					fmt.Sprintf(`ALTER DEFAULT PRIVILEGES FOR ROLE "migrator" IN SCHEMA "app" REVOKE USAGE ON SEQUENCES FROM "%s"`, a.username),
+					fmt.Sprintf(`DROP USER "%s"`, a.username),
+				}
+			},
+		},
 	}
 
 	if err := checkCertsValidity(map[string]string{"ca.crt": testsutils.SSLCACert, "tls.crt": invalidSSLCert}); err == nil {
@@ -176,6 +264,392 @@ func TestPostgresql(t *testing.T) {
 	}
 }
 
+func TestPostgresql_InvalidObjectPrivileges(t *testing.T) {
+	privileges := []v1alpha1.PrivilegeSpec{
+		{ObjectType: v1alpha1.ObjectTable, Database: "dat", Privileges: []v1alpha1.PrivilegeType{"SELECT; DROP TABLE users"}},
+		{ObjectType: v1alpha1.ObjectTable, Database: "dat"},
+		{ObjectType: v1alpha1.ObjectGlobal, Privileges: []v1alpha1.PrivilegeType{v1alpha1.SUPER}},
+		{ObjectType: v1alpha1.ObjectSchema, Database: "dat", Privileges: []v1alpha1.PrivilegeType{v1alpha1.USAGE}, DefaultPrivileges: &v1alpha1.DefaultPrivileges{}},
+		{ObjectType: v1alpha1.ObjectTable, Database: "dat", Objects: []string{"users"}, Privileges: []v1alpha1.PrivilegeType{v1alpha1.SELECT}, DefaultPrivileges: &v1alpha1.DefaultPrivileges{}},
+	}
+
+	mockDB := connection.NewFakeConnection()
+	p := postgresql.NewPostgresql(mockDB, postgresql.NewConfig("postgres", 5432, "user", "password", "dbname", "disable", "", "", "", ""), logr.Discard())
+	for _, privilege := range privileges {
+		if err := p.ApplyPrivileges(context.Background(), "john", []v1alpha1.PrivilegeSpec{privilege}); !errors.Is(err, v1alpha1.ErrInvalidPrivilege) {
+			t.Errorf("Postgresql.ApplyPrivileges() error = %v, want %v", err, v1alpha1.ErrInvalidPrivilege)
+		}
+	}
+
+	if queries := mockDB.Queries(); len(queries) != 0 {
+		t.Errorf("Queries executed for invalid privileges: %v", queries)
+	}
+}
+
+func TestPostgresql_Roles(t *testing.T) {
+	ctx := context.Background()
+	mockDB := connection.NewFakeConnection()
+	p := postgresql.NewPostgresql(mockDB, postgresql.NewConfig("postgres", 5432, "user", "password", "dbname", "disable", "", "", "", ""), logr.Discard())
+	if err := p.Connect(ctx); err != nil {
+		t.Fatalf("Postgresql.Connect() error = %v", err)
+	}
+	defer p.Close(ctx)
+
+	inherit := false
+	roles := []v1alpha1.RoleGrant{
+		{Name: "readers"},
+		{Name: "writers", AdminOption: true},
+		{Name: `my"role`, AdminOption: true, Inherit: &inherit},
+	}
+	if err := p.GrantRoles(ctx, "john", roles); err != nil {
+		t.Errorf("Postgresql.GrantRoles() error = %v", err)
+	}
+	if err := p.RevokeRoles(ctx, "john", roles[:2]); err != nil {
+		t.Errorf("Postgresql.RevokeRoles() error = %v", err)
+	}
+	if err := p.CreateRole(ctx, "app_readonly"); err != nil {
+		t.Errorf("Postgresql.CreateRole() error = %v", err)
+	}
+	if err := p.DeleteRole(ctx, "app_readonly"); err != nil {
+		t.Errorf("Postgresql.DeleteRole() error = %v", err)
+	}
+
+	expectedQueries := []string{
+		`GRANT "readers" TO "john"`,
+		`GRANT "writers" TO "john" WITH ADMIN OPTION`,
+		`GRANT "my""role" TO "john" WITH ADMIN OPTION, INHERIT FALSE`,
+		`REVOKE "readers" FROM "john"`,
+		`REVOKE "writers" FROM "john"`,
+		`CREATE ROLE "app_readonly" NOLOGIN`,
+		`DROP ROLE "app_readonly"`,
+	}
+	actualQueries := mockDB.Queries()
+	for i, query := range expectedQueries {
+		if actualQueries[query] != i+1 {
+			t.Errorf("Query not executed or executed out of order: %s", query)
+		}
+	}
+	if len(expectedQueries) != len(actualQueries) {
+		t.Errorf("Count of executed queries doesn't match: expected=%d, acrual=%d", len(expectedQueries), len(actualQueries))
+	}
+}
+
+func TestPostgresql_RoleTakeover(t *testing.T) {
+	ctx := context.Background()
+	mockDB := connection.NewFakeConnection()
+	p := postgresql.NewPostgresql(mockDB, postgresql.NewConfig("postgres", 5432, "user", "password", "dbname", "disable", "", "", "", ""), logr.Discard())
+	if err := p.Connect(ctx); err != nil {
+		t.Fatalf("Postgresql.Connect() error = %v", err)
+	}
+	defer p.Close(ctx)
+
+	alreadyExists := &pgconn.PgError{Code: "42710"}
+	mockDB.SetError(alreadyExists, `CREATE ROLE "john" NOLOGIN`)
+	mockDB.SetResult([]bool{true}, "SELECT rolcanlogin FROM pg_roles WHERE rolname = $1", "john")
+	mockDB.SetError(alreadyExists, `CREATE ROLE "app.readers" NOLOGIN`)
+	mockDB.SetResult([]bool{false}, "SELECT rolcanlogin FROM pg_roles WHERE rolname = $1", "app.readers")
+
+	if err := p.CreateRole(ctx, "john"); !errors.Is(err, postgresql.ErrRoleCanLogin) {
+		t.Errorf("Postgresql.CreateRole() error = %v, want %v", err, postgresql.ErrRoleCanLogin)
+	}
+	if err := p.DeleteRole(ctx, "john"); err != nil {
+		t.Errorf("Postgresql.DeleteRole() error = %v", err)
+	}
+
+	if err := p.CreateRole(ctx, "app.readers"); err != nil {
+		t.Errorf("Postgresql.CreateRole() error = %v", err)
+	}
+	privileges := []v1alpha1.PrivilegeSpec{{Privilege: "CONNECT", Database: "dat"}}
+	if err := p.ApplyRolePrivileges(ctx, "app.readers", privileges); err != nil {
+		t.Errorf("Postgresql.ApplyRolePrivileges() error = %v", err)
+	}
+	if err := p.DeleteRole(ctx, "app.readers"); err != nil {
+		t.Errorf("Postgresql.DeleteRole() error = %v", err)
+	}
+
+	expectedQueries := []string{
+		`CREATE ROLE "john" NOLOGIN`,
+		`CREATE ROLE "app.readers" NOLOGIN`,
+		`GRANT CONNECT ON DATABASE "dat" TO "app.readers"`,
+		`DROP ROLE "app.readers"`,
+	}
+	actualQueries := mockDB.Queries()
+	for i, query := range expectedQueries {
+		if actualQueries[query] != i+1 {
+			t.Errorf("Query not executed or executed out of order: %s", query)
+		}
+	}
+	if len(expectedQueries) != len(actualQueries) {
+		t.Errorf("Count of executed queries doesn't match: expected=%d, acrual=%d", len(expectedQueries), len(actualQueries))
+	}
+
+	// Login attribute is checked for both roles, before taking over the existing one and before dropping it.
+	for _, name := range []string{"john", "app.readers"} {
+		if _, ok := mockDB.Reads()["SELECT rolcanlogin FROM pg_roles WHERE rolname = $1"+name]; !ok {
+			t.Errorf("Login attribute of role %s wasn't checked: %v", name, mockDB.Reads())
+		}
+	}
+}
+
+func TestPostgresql_AlterUser(t *testing.T) {
+	inherit := false
+	connectionLimit := int32(10)
+	validUntil := metav1.NewTime(time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	tests := []struct {
+		name    string
+		options v1alpha1.UserOptions
+		applied v1alpha1.UserOptions
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "Defaults",
+			want: []string{
+				`ALTER ROLE "john" WITH LOGIN`,
+			},
+		},
+		{
+			name: "Set attributes and parameters",
+			options: v1alpha1.UserOptions{PostgreSQL: &v1alpha1.PostgreSQLUserOptions{
+				CreateDB:        true,
+				CreateRole:      true,
+				Replication:     true,
+				BypassRLS:       true,
+				Inherit:         &inherit,
+				ConnectionLimit: &connectionLimit,
+				ValidUntil:      &validUntil,
+				Parameters: map[string]string{
+					"statement_timeout": "30s",
+					"search_path":       "app, public",
+				},
+			}},
+			want: []string{
+				`ALTER ROLE "john" WITH LOGIN CREATEDB CREATEROLE REPLICATION BYPASSRLS NOINHERIT CONNECTION LIMIT 10 VALID UNTIL '2030-01-02T03:04:05Z'`,
+				`ALTER ROLE "john" RESET ALL`,
+				`ALTER ROLE "john" SET search_path TO 'app', 'public'`,
+				`ALTER ROLE "john" SET statement_timeout TO '30s'`,
+			},
+		},
+		{
+			name: "Change only connection limit",
+			options: v1alpha1.UserOptions{PostgreSQL: &v1alpha1.PostgreSQLUserOptions{
+				CreateDB:        true,
+				ConnectionLimit: &connectionLimit,
+				Parameters:      map[string]string{"statement_timeout": "30s"},
+			}},
+			applied: v1alpha1.UserOptions{PostgreSQL: &v1alpha1.PostgreSQLUserOptions{
+				CreateDB:   true,
+				Parameters: map[string]string{"statement_timeout": "30s"},
+			}},
+			want: []string{
+				`ALTER ROLE "john" WITH LOGIN CONNECTION LIMIT 10`,
+			},
+		},
+		{
+			name: "Reset removed attributes",
+			applied: v1alpha1.UserOptions{PostgreSQL: &v1alpha1.PostgreSQLUserOptions{
+				Replication:     true,
+				Inherit:         &inherit,
+				ConnectionLimit: &connectionLimit,
+				ValidUntil:      &validUntil,
+				Parameters:      map[string]string{"statement_timeout": "30s"},
+			}},
+			want: []string{
+				`ALTER ROLE "john" WITH LOGIN NOREPLICATION INHERIT CONNECTION LIMIT -1 VALID UNTIL 'infinity'`,
+				`ALTER ROLE "john" RESET ALL`,
+			},
+		},
+		{
+			name: "Invalid parameter name",
+			options: v1alpha1.UserOptions{PostgreSQL: &v1alpha1.PostgreSQLUserOptions{
+				Parameters: map[string]string{"work_mem TO '1GB'; --": "1"},
+			}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			mockDB := connection.NewFakeConnection()
+			p := postgresql.NewPostgresql(mockDB, postgresql.NewConfig("postgres", 5432, "user", "password", "dbname", "disable", "", "", "", ""), logr.Discard())
+			if err := p.Connect(ctx); err != nil {
+				t.Fatalf("Postgresql.Connect() error = %v", err)
+			}
+			defer p.Close(ctx)
+
+			if err := p.AlterUser(ctx, "john", "", tt.options, tt.applied); (err != nil) != tt.wantErr {
+				t.Errorf("Postgresql.AlterUser() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			actualQueries := mockDB.Queries()
+			for i, query := range tt.want {
+				if actualQueries[query] != i+1 {
+					t.Errorf("Query not executed or executed out of order: %s", query)
+				}
+			}
+			if len(tt.want) != len(actualQueries) {
+				t.Errorf("Count of executed queries doesn't match: expected=%d, acrual=%d", len(tt.want), len(actualQueries))
+			}
+		})
+	}
+}
+
+func TestPostgresql_SetPassword(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		password string
+		want     string
+	}{
+		{
+			name:     "Set password",
+			username: "john",
+			password: "it's-secret",
+			want:     `ALTER USER "john" WITH PASSWORD 'it''s-secret'`,
+		},
+		{
+			name:     "Remove password",
+			username: "john",
+			want:     `ALTER USER "john" WITH PASSWORD NULL`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			mockDB := connection.NewFakeConnection()
+			p := postgresql.NewPostgresql(mockDB, postgresql.NewConfig("postgres", 5432, "user", "password", "dbname", "disable", "", "", "", ""), logr.Discard())
+			if err := p.Connect(ctx); err != nil {
+				t.Fatalf("Postgresql.Connect() error = %v", err)
+			}
+			defer p.Close(ctx)
+
+			if err := p.SetPassword(ctx, tt.username, tt.password); err != nil {
+				t.Errorf("Postgresql.SetPassword() error = %v", err)
+			}
+
+			if queries := mockDB.Queries(); len(queries) != 1 || queries[tt.want] != 1 {
+				t.Errorf("Postgresql.SetPassword() queries = %v, want %s", queries, tt.want)
+			}
+		})
+	}
+}
+
+func TestPostgresql_Introspection(t *testing.T) {
+	ctx := context.Background()
+	username := "john"
+
+	mockDB := connection.NewFakeConnection()
+	p := postgresql.NewPostgresql(mockDB, postgresql.NewConfig("postgres", 5432, "user", "password", "", "disable", "", "", "", ""), logr.Discard())
+	if err := p.Connect(ctx); err != nil {
+		t.Fatalf("Postgresql.Connect() error = %v", err)
+	}
+	defer p.Close(ctx)
+
+	exists, err := p.UserExists(ctx, username)
+	if err != nil || exists {
+		t.Errorf("Postgresql.UserExists() = %v, %v, want false, nil", exists, err)
+	}
+
+	mockDB.SetResult([]bool{true}, "SELECT true FROM pg_roles WHERE rolname = $1", username)
+	mockDB.SetResult([]map[string]interface{}{{"rolname": "some_role"}},
+		"SELECT r.rolname FROM pg_auth_members m JOIN pg_roles r ON r.oid = m.roleid JOIN pg_roles u ON u.oid = m.member WHERE u.rolname = $1", username)
+	mockDB.SetResult([]map[string]interface{}{{"datname": "conn_dat", "privilege_type": "CONNECT"}},
+		"SELECT d.datname, a.privilege_type FROM pg_database d CROSS JOIN LATERAL aclexplode(d.datacl) a JOIN pg_roles r ON r.oid = a.grantee WHERE r.rolname = $1", username)
+	mockDB.SetResult([]string{"dat"}, "SELECT datname FROM pg_database WHERE datallowconn AND NOT datistemplate")
+	mockDB.SetResult([]map[string]interface{}{
+		{"privilege_type": "SELECT", "table_catalog": "dat", "table_schema": "public", "table_name": "table"},
+		{"privilege_type": "INSERT", "table_catalog": "dat", "table_schema": "public", "table_name": "table"},
+	}, "SELECT privilege_type, table_catalog, table_schema, table_name FROM information_schema.role_table_grants WHERE grantee = $1", username)
+
+	exists, err = p.UserExists(ctx, username)
+	if err != nil || !exists {
+		t.Errorf("Postgresql.UserExists() = %v, %v, want true, nil", exists, err)
+	}
+
+	privileges, err := p.ListPrivileges(ctx, username)
+	if err != nil {
+		t.Fatalf("Postgresql.ListPrivileges() error = %v", err)
+	}
+
+	want := []v1alpha1.PrivilegeSpec{
+		{Privilege: "some_role"},
+		{Privilege: "CONNECT", Database: "conn_dat"},
+		{Privilege: "SELECT", On: "public.table", Database: "dat"},
+		{Privilege: "INSERT", On: "public.table", Database: "dat"},
+	}
+	if !reflect.DeepEqual(privileges, want) {
+		t.Errorf("Postgresql.ListPrivileges() = %v, want %v", privileges, want)
+	}
+
+	if !mockDB.Connections()["pgx:host=postgres user=user port=5432 dbname=dat password=password sslmode=disable"] {
+		t.Errorf("Postgresql.ListPrivileges() didn't connect to database with tables: %v", mockDB.Connections())
+	}
+
+	reads := mockDB.Reads()
+	if reads["SELECT datname FROM pg_database WHERE datallowconn AND NOT datistemplate"] >=
+		reads["SELECT privilege_type, table_catalog, table_schema, table_name FROM information_schema.role_table_grants WHERE grantee = $1"+username] {
+		t.Errorf("Postgresql.ListPrivileges() didn't read table privileges after listing databases: %v", reads)
+	}
+	if len(mockDB.Queries()) != 0 {
+		t.Errorf("Introspection executed statements: %v", mockDB.Queries())
+	}
+}
+
+func TestPostgresql_ListPrivilegesWithoutGrants(t *testing.T) {
+	ctx := context.Background()
+
+	mockDB := connection.NewFakeConnection()
+	p := postgresql.NewPostgresql(mockDB, postgresql.NewConfig("postgres", 5432, "user", "password", "", "disable", "", "", "", ""), logr.Discard())
+	if err := p.Connect(ctx); err != nil {
+		t.Fatalf("Postgresql.Connect() error = %v", err)
+	}
+	defer p.Close(ctx)
+
+	mockDB.SetResult([]string{"app", "dat"}, "SELECT datname FROM pg_database WHERE datallowconn AND NOT datistemplate")
+
+	privileges, err := p.ListPrivileges(ctx, "john")
+	if err != nil {
+		t.Fatalf("Postgresql.ListPrivileges() error = %v", err)
+	}
+	if len(privileges) != 0 {
+		t.Errorf("Postgresql.ListPrivileges() = %v, want empty", privileges)
+	}
+
+	// Table privileges are stored per database, so every database that allows connections is checked.
+	for _, dbname := range []string{"app", "dat"} {
+		if !mockDB.Connections()["pgx:host=postgres user=user port=5432 dbname="+dbname+" password=password sslmode=disable"] {
+			t.Errorf("Postgresql.ListPrivileges() didn't connect to database %s: %v", dbname, mockDB.Connections())
+		}
+	}
+}
+
+func TestPostgresql_ServerInfo(t *testing.T) {
+	ctx := context.Background()
+
+	mockDB := connection.NewFakeConnection()
+	p := postgresql.NewPostgresql(mockDB, postgresql.NewConfig("postgres", 5432, "user", "password", "", "disable", "", "", "", ""), logr.Discard())
+	if err := p.Connect(ctx); err != nil {
+		t.Fatalf("Postgresql.Connect() error = %v", err)
+	}
+	defer p.Close(ctx)
+
+	mockDB.SetResult("15.4", "SELECT current_setting('server_version')")
+	mockDB.SetResult(true, "SELECT ssl FROM pg_stat_ssl WHERE pid = pg_backend_pid()")
+
+	version, err := p.ServerVersion(ctx)
+	if err != nil || version != "15.4" {
+		t.Errorf("Postgresql.ServerVersion() = %v, %v, want 15.4, nil", version, err)
+	}
+
+	tls, err := p.TLSEnabled(ctx)
+	if err != nil || !tls {
+		t.Errorf("Postgresql.TLSEnabled() = %v, %v, want true, nil", tls, err)
+	}
+}
+
 func checkCertsValidity(data map[string]string) error {
 	if data["ca.crt"] != testsutils.SSLCACert {
 		return errors.New("CA cert doen't match expencted CA cert")
@@ -36,6 +36,11 @@ func escapeLiteral(str string) string {
 	return strings.Join(parts, ".")
 }
 
+// quoteIdentifier quotes single identifier, dots are treated as part of the name.
+func quoteIdentifier(str string) string {
+	return `"` + strings.ReplaceAll(strings.ReplaceAll(str, string([]byte{0}), ""), `"`, `""`) + `"`
+}
+
 func escapeString(str string) string {
 	return "'" + strings.ReplaceAll(str, "'", "''") + "'"
 }
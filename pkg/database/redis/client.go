@@ -0,0 +1,89 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/redis/go-redis/v9"
+)
+
+// Client runs Redis commands. It is used by Redis instead of SQL connection.Connection.
+type Client interface {
+	Connect(ctx context.Context, url string) error
+	Close(ctx context.Context) error
+	// Exec runs the command, that changes state of the database (e.g. "ACL SETUSER").
+	Exec(ctx context.Context, args ...interface{}) error
+	// Get runs the command and returns its reply, nil reply is returned without error.
+	Get(ctx context.Context, args ...interface{}) (interface{}, error)
+}
+
+type DefaultClient struct {
+	client *redis.Client
+	logger logr.Logger
+}
+
+func NewDefaultClient(logger logr.Logger) *DefaultClient {
+	return &DefaultClient{
+		logger: logger,
+	}
+}
+
+func (d *DefaultClient) Connect(ctx context.Context, url string) error {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return err
+	}
+	client := redis.NewClient(opts)
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return err
+	}
+	d.client = client
+	return nil
+}
+
+func (d *DefaultClient) Close(_ context.Context) error {
+	return d.client.Close()
+}
+
+func (d *DefaultClient) Exec(ctx context.Context, args ...interface{}) error {
+	d.infoLog(args)
+	return d.client.Do(ctx, args...).Err()
+}
+
+func (d *DefaultClient) Get(ctx context.Context, args ...interface{}) (interface{}, error) {
+	d.infoLog(args)
+	reply, err := d.client.Do(ctx, args...).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	return reply, err
+}
+
+// infoLog logs only the command and the subcommand, because arguments can contain passwords.
+func (d *DefaultClient) infoLog(args []interface{}) {
+	name := make([]string, 0, 2)
+	for i := 0; i < len(args) && i < 2; i++ {
+		name = append(name, fmt.Sprint(args[i]))
+	}
+	d.logger.Info(fmt.Sprintf("Running command '%s'", strings.Join(name, " ")))
+}
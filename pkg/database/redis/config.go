@@ -0,0 +1,59 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redis
+
+import (
+	"fmt"
+	"net/url"
+)
+
+type Config struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+	Secure   bool
+}
+
+func NewConfig(host string, port int, user, pass string, secure bool) *Config {
+	return &Config{
+		Host:     host,
+		User:     user,
+		Password: pass,
+		Port:     port,
+		Secure:   secure,
+	}
+}
+
+// ConnString returns connection URL for Redis, "rediss" scheme is used for TLS.
+// If user is not set - password is used to authenticate as "default" user.
+func (c *Config) ConnString() string {
+	u := url.URL{
+		Scheme: "redis",
+		Host:   fmt.Sprintf("%s:%d", c.Host, c.Port),
+	}
+	if c.Secure {
+		u.Scheme = "rediss"
+	}
+	switch {
+	case c.Password != "":
+		u.User = url.UserPassword(c.User, c.Password)
+	case c.User != "":
+		u.User = url.User(c.User)
+	}
+	return u.String()
+}
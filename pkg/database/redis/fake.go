@@ -0,0 +1,105 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// FakeClient records commands run with Exec and returns results scripted with SetResult from Get.
+type FakeClient struct {
+	commands    map[string]int
+	count       int
+	connections map[string]bool
+	results     map[string]interface{}
+	lock        *sync.RWMutex
+}
+
+func NewFakeClient() *FakeClient {
+	return &FakeClient{
+		commands:    make(map[string]int),
+		connections: make(map[string]bool),
+		results:     make(map[string]interface{}),
+		lock:        &sync.RWMutex{},
+	}
+}
+
+func (f *FakeClient) Connect(_ context.Context, url string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.connections[url] = true
+	return nil
+}
+
+func (f *FakeClient) Close(_ context.Context) error {
+	return nil
+}
+
+func (f *FakeClient) Exec(_ context.Context, args ...interface{}) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.count++
+	f.commands[CommandKey(args...)] = f.count
+	return nil
+}
+
+// Get returns result from SetResult for the command, or nil, if there is no result for it.
+func (f *FakeClient) Get(_ context.Context, args ...interface{}) (interface{}, error) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	return f.results[CommandKey(args...)], nil
+}
+
+// SetResult scripts reply, that will be returned for the command.
+func (f *FakeClient) SetResult(result interface{}, args ...interface{}) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.results[CommandKey(args...)] = result
+}
+
+func (f *FakeClient) Commands() map[string]int {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	return f.commands
+}
+
+func (f *FakeClient) Connections() map[string]bool {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	return f.connections
+}
+
+func (f *FakeClient) ResetDB() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.commands = make(map[string]int)
+	f.connections = make(map[string]bool)
+	f.results = make(map[string]interface{})
+	f.count = 0
+}
+
+// CommandKey returns arguments of the command separated with spaces, that are used as keys of Commands.
+func CommandKey(args ...interface{}) string {
+	items := make([]string, 0, len(args))
+	for _, arg := range args {
+		items = append(items, fmt.Sprint(arg))
+	}
+	return strings.Join(items, " ")
+}
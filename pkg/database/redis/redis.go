@@ -0,0 +1,253 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+
+	"github.com/alex123012/database-users-operator/api/v1alpha1"
+)
+
+var ErrRolesNotSupported = errors.New("roles are not supported by Redis")
+
+// Redis manages users with ACL SETUSER and ACL DELUSER commands,
+// PrivilegeSpec.Privilege is mapped to command rules ("+@read") and PrivilegeSpec.On to key and channel patterns ("~app:*").
+type Redis struct {
+	client Client
+	config *Config
+	logger logr.Logger
+}
+
+func NewRedis(client Client, config *Config, logger logr.Logger) *Redis {
+	return &Redis{
+		client: client,
+		logger: logger,
+		config: config,
+	}
+}
+
+func (r *Redis) Connect(ctx context.Context) error {
+	return r.client.Connect(ctx, r.config.ConnString())
+}
+
+func (r *Redis) Close(ctx context.Context) error {
+	return r.client.Close(ctx)
+}
+
+func (r *Redis) Ping(ctx context.Context) error {
+	_, err := r.client.Get(ctx, "PING")
+	return err
+}
+
+// CreateUser creates enabled user without permissions or sets the password, if the user already exists.
+func (r *Redis) CreateUser(ctx context.Context, username, password string) (map[string]string, error) {
+	return nil, r.client.Exec(ctx, "ACL", "SETUSER", username, "on", "resetpass", ">"+password)
+}
+
+func (r *Redis) SetPassword(ctx context.Context, username, password string) error {
+	return r.client.Exec(ctx, "ACL", "SETUSER", username, "resetpass", ">"+password)
+}
+
+// AlterUser does nothing, Redis has no user options yet.
+func (r *Redis) AlterUser(_ context.Context, _, _ string, _, _ v1alpha1.UserOptions) error {
+	return nil
+}
+
+// DeleteUser deletes the user, ACL DELUSER skips not existing users.
+func (r *Redis) DeleteUser(ctx context.Context, username string) error {
+	return r.client.Exec(ctx, "ACL", "DELUSER", username)
+}
+
+func (r *Redis) CreateRole(_ context.Context, _ string) error {
+	return ErrRolesNotSupported
+}
+
+// DeleteRole does nothing, roles can't be created in Redis.
+func (r *Redis) DeleteRole(_ context.Context, _ string) error {
+	return nil
+}
+
+func (r *Redis) ApplyRolePrivileges(_ context.Context, _ string, _ []v1alpha1.PrivilegeSpec) error {
+	return ErrRolesNotSupported
+}
+
+// RevokeRolePrivileges does nothing, roles can't be created in Redis.
+func (r *Redis) RevokeRolePrivileges(_ context.Context, _ string, _ []v1alpha1.PrivilegeSpec) error {
+	return nil
+}
+
+func (r *Redis) GrantRoles(_ context.Context, _ string, roles []v1alpha1.RoleGrant) error {
+	if len(roles) > 0 {
+		return ErrRolesNotSupported
+	}
+	return nil
+}
+
+// RevokeRoles does nothing, roles can't be granted in Redis.
+func (r *Redis) RevokeRoles(_ context.Context, _ string, _ []v1alpha1.RoleGrant) error {
+	return nil
+}
+
+// ApplyPrivileges replaces all permissions of the user with rules of privileges,
+// because single key pattern can't be removed from the user in Redis.
+// Rules of all privileges are applied to the user together, so commands are allowed on all its patterns.
+func (r *Redis) ApplyPrivileges(ctx context.Context, username string, privileges []v1alpha1.PrivilegeSpec) error {
+	rules, err := privilegesRules(privileges)
+	if err != nil {
+		return err
+	}
+
+	args := []interface{}{"ACL", "SETUSER", username, "-@all", "resetkeys", "resetchannels"}
+	for _, rule := range rules {
+		args = append(args, rule)
+	}
+	return r.client.Exec(ctx, args...)
+}
+
+// RevokePrivileges disallows commands allowed by privileges and resets key and channel patterns,
+// if privileges contain them. Rules that disallow commands are skipped.
+func (r *Redis) RevokePrivileges(ctx context.Context, username string, privileges []v1alpha1.PrivilegeSpec) error {
+	rules, err := privilegesRules(privileges)
+	if err != nil {
+		return err
+	}
+
+	var args []interface{}
+	var resetKeys, resetChannels bool
+	for _, rule := range rules {
+		switch {
+		case strings.HasPrefix(rule, "+"):
+			args = append(args, "-"+strings.TrimPrefix(rule, "+"))
+		case strings.HasPrefix(rule, "&"):
+			resetChannels = true
+		case !strings.HasPrefix(rule, "-"):
+			resetKeys = true
+		}
+	}
+	if resetKeys {
+		args = append(args, "resetkeys")
+	}
+	if resetChannels {
+		args = append(args, "resetchannels")
+	}
+	if len(args) < 1 {
+		return nil
+	}
+	return r.client.Exec(ctx, append([]interface{}{"ACL", "SETUSER", username}, args...)...)
+}
+
+// privilegesRules returns command rules and patterns of privileges in the order, they are set in privileges.
+func privilegesRules(privileges []v1alpha1.PrivilegeSpec) ([]string, error) {
+	var rules []string
+	for _, privilege := range privileges {
+		if privilege.ObjectType != "" || privilege.Database != "" {
+			return nil, fmt.Errorf("%w: Redis privilege must contain only ACL rules and patterns", v1alpha1.ErrInvalidPrivilege)
+		}
+
+		for _, rule := range strings.Fields(string(privilege.Privilege)) {
+			if !v1alpha1.RedisCommandRuleRegexp.MatchString(rule) {
+				return nil, fmt.Errorf("%w: %q is not a command rule", v1alpha1.ErrInvalidPrivilege, rule)
+			}
+			rules = append(rules, rule)
+		}
+		for _, pattern := range strings.Fields(privilege.On) {
+			if !v1alpha1.RedisPatternRegexp.MatchString(pattern) {
+				return nil, fmt.Errorf("%w: %q is not a key or channel pattern", v1alpha1.ErrInvalidPrivilege, pattern)
+			}
+			rules = append(rules, pattern)
+		}
+	}
+	return rules, nil
+}
+
+func (r *Redis) UserExists(ctx context.Context, username string) (bool, error) {
+	reply, err := r.client.Get(ctx, "ACL", "GETUSER", username)
+	return reply != nil, err
+}
+
+// ListPrivileges returns all permissions of the user as one privilege with command rules and patterns.
+func (r *Redis) ListPrivileges(ctx context.Context, username string) ([]v1alpha1.PrivilegeSpec, error) {
+	reply, err := r.client.Get(ctx, "ACL", "GETUSER", username)
+	if err != nil || reply == nil {
+		return nil, err
+	}
+
+	fields := replyFields(reply)
+	commands, _ := fields["commands"].(string)
+	if commands == "" {
+		return nil, nil
+	}
+	patterns := append(replyPatterns(fields["keys"], "~"), replyPatterns(fields["channels"], "&")...)
+	return []v1alpha1.PrivilegeSpec{{Privilege: v1alpha1.PrivilegeType(commands), On: strings.Join(patterns, " ")}}, nil
+}
+
+// replyFields converts ACL GETUSER reply to map, reply is a map for RESP3 and a flat list of names and values for RESP2.
+func replyFields(reply interface{}) map[string]interface{} {
+	fields := make(map[string]interface{})
+	switch reply := reply.(type) {
+	case map[interface{}]interface{}:
+		for key, value := range reply {
+			fields[fmt.Sprint(key)] = value
+		}
+	case []interface{}:
+		for i := 0; i+1 < len(reply); i += 2 {
+			fields[fmt.Sprint(reply[i])] = reply[i+1]
+		}
+	}
+	return fields
+}
+
+// replyPatterns returns patterns from ACL GETUSER reply, Redis 7 returns them as rules string
+// and Redis 6 returns list of patterns without prefix.
+func replyPatterns(value interface{}, prefix string) []string {
+	switch value := value.(type) {
+	case string:
+		return strings.Fields(value)
+	case []interface{}:
+		patterns := make([]string, 0, len(value))
+		for _, pattern := range value {
+			patterns = append(patterns, prefix+fmt.Sprint(pattern))
+		}
+		return patterns
+	}
+	return nil
+}
+
+func (r *Redis) ServerVersion(ctx context.Context) (string, error) {
+	reply, err := r.client.Get(ctx, "INFO", "server")
+	if err != nil {
+		return "", err
+	}
+
+	info, _ := reply.(string)
+	for _, line := range strings.Split(info, "\n") {
+		if version, ok := strings.CutPrefix(strings.TrimSpace(line), "redis_version:"); ok {
+			return version, nil
+		}
+	}
+	return "", nil
+}
+
+// TLSEnabled returns whether the connection is configured to use TLS.
+func (r *Redis) TLSEnabled(_ context.Context) (bool, error) {
+	return r.config.Secure, nil
+}
@@ -0,0 +1,33 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testsutils
+
+import "testing"
+
+// CheckQueries checks, that only expected queries were executed and in the same order.
+// Actual queries are the ones recorded by fake connections: query with its execution order, starting from 1.
+func CheckQueries(t testing.TB, expected []string, actual map[string]int) {
+	t.Helper()
+	for i, query := range expected {
+		if actual[query] != i+1 {
+			t.Errorf("Query not executed or executed out of order: '%s', %d", query, i+1)
+		}
+	}
+	if len(expected) != len(actual) {
+		t.Errorf("Count of executed queries doesn't match: expected=%d, actual=%d", len(expected), len(actual))
+	}
+}
@@ -18,9 +18,15 @@ package utils
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"math/big"
 	"os"
 	"path/filepath"
 
+	"golang.org/x/crypto/bcrypt"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -50,3 +56,44 @@ func PathFromHome(paths ...string) string {
 	paths = append([]string{os.Getenv("HOME")}, paths...)
 	return filepath.Join(paths...)
 }
+
+const (
+	DefaultPasswordLength  = 32
+	DefaultPasswordCharset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+)
+
+// GeneratePassword returns random password with provided length from characters in charset.
+func GeneratePassword(length int, charset string) (string, error) {
+	if length < 1 || charset == "" {
+		return "", errors.New("password length and charset must not be empty")
+	}
+
+	chars := []rune(charset)
+	max := big.NewInt(int64(len(chars)))
+	password := make([]rune, length)
+	for i := range password {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		password[i] = chars[n.Int64()]
+	}
+	return string(password), nil
+}
+
+// HashPassword returns hash of the password, that is safe to store in resource status.
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword(preHashPassword(password), bcrypt.DefaultCost)
+	return string(hash), err
+}
+
+// PasswordMatchesHash checks if the hash was created by HashPassword from the password.
+func PasswordMatchesHash(password, hash string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), preHashPassword(password)) == nil
+}
+
+// bcrypt uses only first 72 bytes of the password, so it is hashed with sha256 first.
+func preHashPassword(password string) []byte {
+	sum := sha256.Sum256([]byte(password))
+	return []byte(hex.EncodeToString(sum[:]))
+}